@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+
+	"github.com/asdine/lobby"
+)
+
+// A Permission allows an operation on a topic.
+type Permission string
+
+// Permissions.
+const (
+	// Create allows to create and delete a topic.
+	Create Permission = "create"
+	// Send allows to send messages to a topic.
+	Send Permission = "send"
+	// Read allows to read the messages and the informations of a topic, and to subscribe to it.
+	Read Permission = "read"
+)
+
+// A Rule grants permissions on a set of topics.
+type Rule struct {
+	// Name of a topic, or prefix of topic names if it ends with "*". "*" matches every topic.
+	Topic       string
+	Permissions []Permission
+}
+
+// Match returns true if the rule applies to the given topic.
+func (r *Rule) Match(topic string) bool {
+	if strings.HasSuffix(r.Topic, "*") {
+		return strings.HasPrefix(topic, strings.TrimSuffix(r.Topic, "*"))
+	}
+
+	return r.Topic == topic
+}
+
+// An Identity is an authenticated client and its permissions.
+type Identity struct {
+	Name  string
+	Rules []Rule
+}
+
+// Can returns true if one of the rules of the identity grants the permission on the topic.
+func (i *Identity) Can(p Permission, topic string) bool {
+	for j := range i.Rules {
+		if !i.Rules[j].Match(topic) {
+			continue
+		}
+
+		for _, granted := range i.Rules[j].Permissions {
+			if granted == p {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// An Authenticator returns the identity associated with a token, like an API key or a JWT.
+// It returns lobby.ErrUnauthenticated if the token is invalid.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Identity, error)
+}
+
+// A CertificateAuthenticator returns the identity associated with a verified client certificate.
+// It returns lobby.ErrUnauthenticated if no identity is associated with the certificate.
+type CertificateAuthenticator interface {
+	AuthenticateCertificate(ctx context.Context, cert *x509.Certificate) (*Identity, error)
+}
+
+type certificateKey struct{}
+
+// NewCertificateContext returns a context carrying the verified certificate of the client.
+func NewCertificateContext(ctx context.Context, cert *x509.Certificate) context.Context {
+	return context.WithValue(ctx, certificateKey{}, cert)
+}
+
+// CertificateFromContext returns the verified certificate of the client, if any.
+func CertificateFromContext(ctx context.Context) (*x509.Certificate, bool) {
+	cert, ok := ctx.Value(certificateKey{}).(*x509.Certificate)
+	return cert, ok
+}
+
+type identityKey struct{}
+
+// NewIdentityContext returns a context carrying the identity the client was authenticated with.
+func NewIdentityContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFromContext returns the identity the client was authenticated with, if any.
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(*Identity)
+	return id, ok
+}
+
+// Authenticate returns the identity of the client. The token is used if set, otherwise the client certificate
+// stored in the context is used if a supports it.
+func Authenticate(ctx context.Context, a Authenticator, token string) (*Identity, error) {
+	if token != "" {
+		return a.Authenticate(ctx, token)
+	}
+
+	cert, ok := CertificateFromContext(ctx)
+	if !ok {
+		return nil, lobby.ErrUnauthenticated
+	}
+
+	c, ok := a.(CertificateAuthenticator)
+	if !ok {
+		return nil, lobby.ErrUnauthenticated
+	}
+
+	return c.AuthenticateCertificate(ctx, cert)
+}
+
+// Authorize authenticates the client and checks that its identity is granted the permissions on the topic.
+// The identity stored in the context, if any, is used rather than authenticating the client again.
+// If a is nil, authentication is disabled and every request is authorized.
+func Authorize(ctx context.Context, a Authenticator, token, topic string, permissions ...Permission) error {
+	if a == nil {
+		return nil
+	}
+
+	id, ok := IdentityFromContext(ctx)
+	if !ok {
+		var err error
+
+		id, err = Authenticate(ctx, a, token)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, p := range permissions {
+		if !id.Can(p, topic) {
+			return lobby.ErrPermissionDenied
+		}
+	}
+
+	return nil
+}
+
+// listPageSize is the number of topics fetched at once by List.
+const listPageSize = 100
+
+// List returns the topics of the registry the identity can read, ordered by name. It skips the first
+// offset readable topics and returns at most limit topics, all the remaining ones if limit is lower
+// or equal to zero. Topics are fetched by pages until the limit is reached, so that the topics the
+// identity can't read don't shorten the list. If id is nil, every topic is returned.
+func List(r lobby.Registry, id *Identity, offset, limit int) ([]lobby.TopicInfo, error) {
+	if id == nil {
+		return r.List(offset, limit)
+	}
+
+	list := []lobby.TopicInfo{}
+	var skipped int
+
+	for start := 0; ; start += listPageSize {
+		page, err := r.List(start, listPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range page {
+			if !id.Can(Read, page[i].Name) {
+				continue
+			}
+
+			if skipped < offset {
+				skipped++
+				continue
+			}
+
+			list = append(list, page[i])
+			if limit > 0 && len(list) == limit {
+				return list, nil
+			}
+		}
+
+		if len(page) < listPageSize {
+			return list, nil
+		}
+	}
+}
+
+// BearerToken returns the token of an authorization header using the Bearer scheme, like "Bearer <token>".
+func BearerToken(header string) string {
+	const prefix = "bearer "
+
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return ""
+	}
+
+	return strings.TrimSpace(header[len(prefix):])
+}
@@ -0,0 +1,200 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"testing"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/auth"
+	"github.com/asdine/lobby/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type authenticatorFn func(ctx context.Context, token string) (*auth.Identity, error)
+
+func (fn authenticatorFn) Authenticate(ctx context.Context, token string) (*auth.Identity, error) {
+	return fn(ctx, token)
+}
+
+func TestIdentityCan(t *testing.T) {
+	id := auth.Identity{
+		Name: "producer",
+		Rules: []auth.Rule{
+			{Topic: "quotes", Permissions: []auth.Permission{auth.Send, auth.Read}},
+			{Topic: "logs-*", Permissions: []auth.Permission{auth.Create}},
+		},
+	}
+
+	require.True(t, id.Can(auth.Send, "quotes"))
+	require.True(t, id.Can(auth.Read, "quotes"))
+	require.False(t, id.Can(auth.Create, "quotes"))
+	require.False(t, id.Can(auth.Send, "quotes2"))
+	require.True(t, id.Can(auth.Create, "logs-app"))
+	require.True(t, id.Can(auth.Create, "logs-"))
+	require.False(t, id.Can(auth.Send, "logs-app"))
+	require.False(t, id.Can(auth.Create, "logs"))
+
+	admin := auth.Identity{
+		Rules: []auth.Rule{
+			{Topic: "*", Permissions: []auth.Permission{auth.Create, auth.Send, auth.Read}},
+		},
+	}
+	require.True(t, admin.Can(auth.Create, "anything"))
+}
+
+func TestAuthorize(t *testing.T) {
+	a := authenticatorFn(func(ctx context.Context, token string) (*auth.Identity, error) {
+		if token != "key" {
+			return nil, lobby.ErrUnauthenticated
+		}
+
+		return &auth.Identity{
+			Rules: []auth.Rule{
+				{Topic: "quotes", Permissions: []auth.Permission{auth.Read}},
+			},
+		}, nil
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		err := auth.Authorize(context.Background(), nil, "", "quotes", auth.Create)
+		require.NoError(t, err)
+	})
+
+	t.Run("NoToken", func(t *testing.T) {
+		err := auth.Authorize(context.Background(), a, "", "quotes", auth.Read)
+		require.Equal(t, lobby.ErrUnauthenticated, err)
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		err := auth.Authorize(context.Background(), a, "other", "quotes", auth.Read)
+		require.Equal(t, lobby.ErrUnauthenticated, err)
+	})
+
+	t.Run("PermissionDenied", func(t *testing.T) {
+		err := auth.Authorize(context.Background(), a, "key", "quotes", auth.Read, auth.Send)
+		require.Equal(t, lobby.ErrPermissionDenied, err)
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		err := auth.Authorize(context.Background(), a, "key", "quotes", auth.Read)
+		require.NoError(t, err)
+	})
+}
+
+type certificateAuthenticator struct {
+	authenticatorFn
+}
+
+func (certificateAuthenticator) AuthenticateCertificate(ctx context.Context, cert *x509.Certificate) (*auth.Identity, error) {
+	return &auth.Identity{
+		Name: cert.Subject.CommonName,
+		Rules: []auth.Rule{
+			{Topic: "quotes", Permissions: []auth.Permission{auth.Send}},
+		},
+	}, nil
+}
+
+func TestAuthorizeCertificate(t *testing.T) {
+	tokens := authenticatorFn(func(ctx context.Context, token string) (*auth.Identity, error) {
+		return nil, lobby.ErrUnauthenticated
+	})
+
+	cert := x509.Certificate{Subject: pkix.Name{CommonName: "producer"}}
+	ctx := auth.NewCertificateContext(context.Background(), &cert)
+
+	c, ok := auth.CertificateFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, &cert, c)
+
+	t.Run("NotSupported", func(t *testing.T) {
+		err := auth.Authorize(ctx, tokens, "", "quotes", auth.Send)
+		require.Equal(t, lobby.ErrUnauthenticated, err)
+	})
+
+	t.Run("NoCertificate", func(t *testing.T) {
+		err := auth.Authorize(context.Background(), certificateAuthenticator{tokens}, "", "quotes", auth.Send)
+		require.Equal(t, lobby.ErrUnauthenticated, err)
+	})
+
+	t.Run("TokenFirst", func(t *testing.T) {
+		err := auth.Authorize(ctx, certificateAuthenticator{tokens}, "token", "quotes", auth.Send)
+		require.Equal(t, lobby.ErrUnauthenticated, err)
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		id, err := auth.Authenticate(ctx, certificateAuthenticator{tokens}, "")
+		require.NoError(t, err)
+		require.Equal(t, "producer", id.Name)
+
+		err = auth.Authorize(ctx, certificateAuthenticator{tokens}, "", "quotes", auth.Send)
+		require.NoError(t, err)
+
+		err = auth.Authorize(ctx, certificateAuthenticator{tokens}, "", "quotes", auth.Read)
+		require.Equal(t, lobby.ErrPermissionDenied, err)
+	})
+}
+
+func TestBearerToken(t *testing.T) {
+	require.Equal(t, "abc", auth.BearerToken("Bearer abc"))
+	require.Equal(t, "abc", auth.BearerToken("bearer  abc "))
+	require.Equal(t, "", auth.BearerToken("Basic abc"))
+	require.Equal(t, "", auth.BearerToken("Bearer"))
+	require.Equal(t, "", auth.BearerToken(""))
+}
+
+func TestList(t *testing.T) {
+	var r mock.Registry
+
+	// every third topic can't be read, 300 topics are spread over several pages.
+	var all []lobby.TopicInfo
+	for i := 0; i < 300; i++ {
+		name := fmt.Sprintf("logs-%03d", i)
+		if i%3 == 1 {
+			name = fmt.Sprintf("secret-%03d", i)
+		}
+		all = append(all, lobby.TopicInfo{Name: name})
+	}
+
+	r.ListFn = func(offset, limit int) ([]lobby.TopicInfo, error) {
+		if offset > len(all) {
+			offset = len(all)
+		}
+		end := len(all)
+		if limit > 0 && offset+limit < end {
+			end = offset + limit
+		}
+		return all[offset:end], nil
+	}
+
+	id := auth.Identity{Rules: []auth.Rule{{Topic: "logs-*", Permissions: []auth.Permission{auth.Read}}}}
+
+	t.Run("Page", func(t *testing.T) {
+		list, err := auth.List(&r, &id, 1, 2)
+		require.NoError(t, err)
+		require.Equal(t, []lobby.TopicInfo{{Name: "logs-002"}, {Name: "logs-003"}}, list)
+	})
+
+	t.Run("AcrossPages", func(t *testing.T) {
+		list, err := auth.List(&r, &id, 60, 100)
+		require.NoError(t, err)
+		require.Len(t, list, 100)
+		require.Equal(t, "logs-090", list[0].Name)
+		require.Equal(t, "logs-239", list[99].Name)
+	})
+
+	t.Run("All", func(t *testing.T) {
+		list, err := auth.List(&r, &id, 190, 0)
+		require.NoError(t, err)
+		require.Len(t, list, 10)
+		require.Equal(t, "logs-299", list[9].Name)
+	})
+
+	t.Run("NoIdentity", func(t *testing.T) {
+		list, err := auth.List(&r, nil, 1, 2)
+		require.NoError(t, err)
+		require.Equal(t, []lobby.TopicInfo{{Name: "secret-001"}, {Name: "logs-002"}}, list)
+	})
+}
@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/asdine/lobby"
+	"github.com/pkg/errors"
+)
+
+var _ Authenticator = new(Keys)
+var _ CertificateAuthenticator = new(Keys)
+
+// keyFile is the content of a key file.
+type keyFile struct {
+	JWT struct {
+		// Secret used to verify HS256 tokens.
+		Secret string
+		// PEM encoded RSA public key used to verify RS256 tokens.
+		PublicKey string `toml:"public-key"`
+	}
+	Identities []struct {
+		Name    string
+		APIKeys []string `toml:"api-keys"`
+		Rules   []Rule
+	}
+}
+
+// LoadKeyFile reads the API keys, the JWT keys and the identities from a TOML file.
+func LoadKeyFile(path string) (*Keys, error) {
+	var f keyFile
+
+	_, err := toml.DecodeFile(path, &f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read key file %s", path)
+	}
+
+	k := Keys{
+		apiKeys:    make(map[[sha256.Size]byte]*Identity),
+		identities: make(map[string]*Identity),
+		now:        time.Now,
+	}
+
+	if f.JWT.Secret != "" {
+		k.secret = []byte(f.JWT.Secret)
+	}
+
+	if f.JWT.PublicKey != "" {
+		k.publicKey, err = parsePublicKey(f.JWT.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, i := range f.Identities {
+		if i.Name == "" {
+			return nil, errors.New("identities must have a name")
+		}
+
+		if _, ok := k.identities[i.Name]; ok {
+			return nil, errors.Errorf("identity %s is defined more than once", i.Name)
+		}
+
+		for _, r := range i.Rules {
+			for _, p := range r.Permissions {
+				if p != Create && p != Send && p != Read {
+					return nil, errors.Errorf("unknown permission '%s' in the rules of %s", p, i.Name)
+				}
+			}
+		}
+
+		id := Identity{
+			Name:  i.Name,
+			Rules: i.Rules,
+		}
+
+		k.identities[i.Name] = &id
+		for _, key := range i.APIKeys {
+			k.apiKeys[sha256.Sum256([]byte(key))] = &id
+		}
+	}
+
+	return &k, nil
+}
+
+func parsePublicKey(data string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("invalid public key: no PEM data found")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid public key")
+	}
+
+	pub, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("invalid public key: only RSA keys are supported")
+	}
+
+	return pub, nil
+}
+
+// Keys authenticates clients using the API keys and JWT keys of a key file.
+type Keys struct {
+	// API keys are indexed by their hash, so that lookups don't leak them through timing.
+	apiKeys    map[[sha256.Size]byte]*Identity
+	identities map[string]*Identity
+	secret     []byte
+	publicKey  *rsa.PublicKey
+	now        func() time.Time
+}
+
+// Authenticate returns the identity owning the API key, or the identity named by the subject of the JWT.
+// JWTs must be signed using HS256 or RS256 and are rejected once expired.
+func (k *Keys) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	if id, ok := k.apiKeys[sha256.Sum256([]byte(token))]; ok {
+		return id, nil
+	}
+
+	if strings.Count(token, ".") != 2 {
+		return nil, lobby.ErrUnauthenticated
+	}
+
+	sub, err := k.verifyJWT(token)
+	if err != nil {
+		return nil, lobby.ErrUnauthenticated
+	}
+
+	id, ok := k.identities[sub]
+	if !ok {
+		return nil, lobby.ErrUnauthenticated
+	}
+
+	return id, nil
+}
+
+// AuthenticateCertificate returns the identity named by the common name of the certificate subject.
+func (k *Keys) AuthenticateCertificate(ctx context.Context, cert *x509.Certificate) (*Identity, error) {
+	id, ok := k.identities[cert.Subject.CommonName]
+	if !ok {
+		return nil, lobby.ErrUnauthenticated
+	}
+
+	return id, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Sub string `json:"sub"`
+	Exp *int64 `json:"exp"`
+	Nbf *int64 `json:"nbf"`
+}
+
+// verifyJWT checks the signature and the validity period of a JWT and returns its subject.
+func (k *Keys) verifyJWT(token string) (string, error) {
+	parts := strings.Split(token, ".")
+
+	var header jwtHeader
+	err := decodeSegment(parts[0], &header)
+	if err != nil {
+		return "", err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", err
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+
+	switch {
+	case header.Alg == "HS256" && k.secret != nil:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(signed)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return "", errors.New("invalid signature")
+		}
+	case header.Alg == "RS256" && k.publicKey != nil:
+		hash := sha256.Sum256(signed)
+		err = rsa.VerifyPKCS1v15(k.publicKey, crypto.SHA256, hash[:], sig)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", errors.Errorf("unsupported algorithm %s", header.Alg)
+	}
+
+	var claims jwtClaims
+	err = decodeSegment(parts[1], &claims)
+	if err != nil {
+		return "", err
+	}
+
+	now := k.now().Unix()
+	if claims.Exp != nil && now >= *claims.Exp {
+		return "", errors.New("token expired")
+	}
+
+	if claims.Nbf != nil && now < *claims.Nbf {
+		return "", errors.New("token not valid yet")
+	}
+
+	return claims.Sub, nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
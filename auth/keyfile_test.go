@@ -0,0 +1,229 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKeyFile(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "lobby")
+	require.NoError(t, err)
+
+	p := path.Join(dir, "keys.toml")
+	err = ioutil.WriteFile(p, []byte(content), 0600)
+	require.NoError(t, err)
+
+	return p, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+// signJWT returns a JWT with the given claims, signed with an HMAC secret or an RSA private key.
+func signJWT(t *testing.T, key interface{}, claims map[string]interface{}) string {
+	alg := "HS256"
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		alg = "RS256"
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		hash := sha256.Sum256([]byte(signed))
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, hash[:])
+		require.NoError(t, err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	require.NoError(t, err)
+	pub := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	p, cleanup := writeKeyFile(t, `
+[jwt]
+secret = "s3cr3t"
+public-key = """
+`+string(pub)+`"""
+
+[[identities]]
+name = "producer"
+api-keys = ["key1", "key2"]
+
+  [[identities.rules]]
+  topic = "quotes"
+  permissions = ["send"]
+
+[[identities]]
+name = "admin"
+
+  [[identities.rules]]
+  topic = "*"
+  permissions = ["create", "send", "read"]
+`)
+	defer cleanup()
+
+	keys, err := auth.LoadKeyFile(p)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("APIKey", func(t *testing.T) {
+		id, err := keys.Authenticate(ctx, "key2")
+		require.NoError(t, err)
+		require.Equal(t, "producer", id.Name)
+		require.True(t, id.Can(auth.Send, "quotes"))
+		require.False(t, id.Can(auth.Read, "quotes"))
+
+		_, err = keys.Authenticate(ctx, "key3")
+		require.Equal(t, lobby.ErrUnauthenticated, err)
+	})
+
+	t.Run("HS256", func(t *testing.T) {
+		token := signJWT(t, []byte("s3cr3t"), map[string]interface{}{
+			"sub": "admin",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+
+		id, err := keys.Authenticate(ctx, token)
+		require.NoError(t, err)
+		require.Equal(t, "admin", id.Name)
+
+		token = signJWT(t, []byte("other"), map[string]interface{}{"sub": "admin"})
+		_, err = keys.Authenticate(ctx, token)
+		require.Equal(t, lobby.ErrUnauthenticated, err)
+	})
+
+	t.Run("RS256", func(t *testing.T) {
+		token := signJWT(t, priv, map[string]interface{}{"sub": "producer"})
+
+		id, err := keys.Authenticate(ctx, token)
+		require.NoError(t, err)
+		require.Equal(t, "producer", id.Name)
+
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		token = signJWT(t, other, map[string]interface{}{"sub": "producer"})
+		_, err = keys.Authenticate(ctx, token)
+		require.Equal(t, lobby.ErrUnauthenticated, err)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		token := signJWT(t, []byte("s3cr3t"), map[string]interface{}{
+			"sub": "admin",
+			"exp": time.Now().Add(-time.Minute).Unix(),
+		})
+
+		_, err := keys.Authenticate(ctx, token)
+		require.Equal(t, lobby.ErrUnauthenticated, err)
+	})
+
+	t.Run("NotBefore", func(t *testing.T) {
+		token := signJWT(t, []byte("s3cr3t"), map[string]interface{}{
+			"sub": "admin",
+			"nbf": time.Now().Add(time.Hour).Unix(),
+		})
+
+		_, err := keys.Authenticate(ctx, token)
+		require.Equal(t, lobby.ErrUnauthenticated, err)
+	})
+
+	t.Run("Certificate", func(t *testing.T) {
+		id, err := keys.AuthenticateCertificate(ctx, &x509.Certificate{Subject: pkix.Name{CommonName: "admin"}})
+		require.NoError(t, err)
+		require.Equal(t, "admin", id.Name)
+
+		_, err = keys.AuthenticateCertificate(ctx, &x509.Certificate{Subject: pkix.Name{CommonName: "nobody"}})
+		require.Equal(t, lobby.ErrUnauthenticated, err)
+	})
+
+	t.Run("UnknownSubject", func(t *testing.T) {
+		token := signJWT(t, []byte("s3cr3t"), map[string]interface{}{"sub": "nobody"})
+
+		_, err := keys.Authenticate(ctx, token)
+		require.Equal(t, lobby.ErrUnauthenticated, err)
+	})
+}
+
+func TestLoadKeyFileErrors(t *testing.T) {
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := auth.LoadKeyFile("/path/to/nowhere.toml")
+		require.Error(t, err)
+	})
+
+	t.Run("UnknownPermission", func(t *testing.T) {
+		p, cleanup := writeKeyFile(t, `
+[[identities]]
+name = "producer"
+
+  [[identities.rules]]
+  topic = "quotes"
+  permissions = ["write"]
+`)
+		defer cleanup()
+
+		_, err := auth.LoadKeyFile(p)
+		require.EqualError(t, err, "unknown permission 'write' in the rules of producer")
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		p, cleanup := writeKeyFile(t, `
+[[identities]]
+name = "producer"
+
+[[identities]]
+name = "producer"
+`)
+		defer cleanup()
+
+		_, err := auth.LoadKeyFile(p)
+		require.Error(t, err)
+	})
+
+	t.Run("NoneAlgorithm", func(t *testing.T) {
+		p, cleanup := writeKeyFile(t, `
+[[identities]]
+name = "admin"
+`)
+		defer cleanup()
+
+		keys, err := auth.LoadKeyFile(p)
+		require.NoError(t, err)
+
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+		payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`))
+		_, err = keys.Authenticate(context.Background(), header+"."+payload+".")
+		require.Equal(t, lobby.ErrUnauthenticated, err)
+	})
+}
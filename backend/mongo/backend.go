@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
+	"time"
+
 	"github.com/asdine/lobby"
+	"github.com/pkg/errors"
 	"gopkg.in/mgo.v2"
 )
 
 var _ lobby.Backend = new(Backend)
+var _ lobby.HealthChecker = new(Backend)
 
 // NewBackend returns a MongoDB backend.
 func NewBackend(uri string) (*Backend, error) {
@@ -16,7 +21,7 @@ func NewBackend(uri string) (*Backend, error) {
 		return nil, err
 	}
 
-	err = ensureIndexes(session.DB(""))
+	err = ensureIndexes(session.DB("").C(colMessages))
 	if err != nil {
 		return nil, err
 	}
@@ -26,9 +31,7 @@ func NewBackend(uri string) (*Backend, error) {
 	}, nil
 }
 
-func ensureIndexes(db *mgo.Database) error {
-	col := db.C(colMessages)
-
+func ensureIndexes(col *mgo.Collection) error {
 	index := mgo.Index{
 		Key:    []string{"topic", "group"},
 		Sparse: true,
@@ -43,8 +46,34 @@ type Backend struct {
 }
 
 // Topic returns the topic associated with the given name.
-func (s *Backend) Topic(name string) (lobby.Topic, error) {
-	return NewTopic(s.session.Copy(), name), nil
+// The "collection" option selects the collection where the messages are saved,
+// it defaults to "messages".
+func (s *Backend) Topic(name string, options map[string]string) (lobby.Topic, error) {
+	collection := colMessages
+
+	if c := options["collection"]; c != "" {
+		collection = c
+
+		// indexes are cached by the session, they are only created once.
+		err := ensureIndexes(s.session.DB("").C(collection))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create indexes of collection %s", collection)
+		}
+	}
+
+	return NewTopic(s.session.Copy(), name, collection), nil
+}
+
+// Health pings the MongoDB server.
+func (s *Backend) Health(ctx context.Context) error {
+	session := s.session.Copy()
+	defer session.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		session.SetSocketTimeout(time.Until(deadline))
+	}
+
+	return errors.Wrap(session.Ping(), "failed to ping mongodb")
 }
 
 // Close MongoDB connection.
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"testing"
 
+	"github.com/asdine/lobby"
 	"github.com/stretchr/testify/require"
 )
 
@@ -22,7 +24,7 @@ func TestBackend(t *testing.T) {
 	backend, cleanup := getBackend(t)
 	defer cleanup()
 
-	topic, err := backend.Topic("a")
+	topic, err := backend.Topic("a", nil)
 	require.NoError(t, err)
 	require.NotNil(t, topic)
 	require.NotNil(t, topic.(*Topic).session)
@@ -30,10 +32,10 @@ func TestBackend(t *testing.T) {
 	err = topic.Close()
 	require.NoError(t, err)
 
-	b1, err := backend.Topic("a")
+	b1, err := backend.Topic("a", nil)
 	require.NoError(t, err)
 
-	b2, err := backend.Topic("b")
+	b2, err := backend.Topic("b", nil)
 	require.NoError(t, err)
 
 	err = b1.Close()
@@ -42,3 +44,25 @@ func TestBackend(t *testing.T) {
 	err = b2.Close()
 	require.NoError(t, err)
 }
+
+func TestBackendTopicOptions(t *testing.T) {
+	backend, cleanup := getBackend(t)
+	defer cleanup()
+
+	topic, err := backend.Topic("a", nil)
+	require.NoError(t, err)
+	require.Equal(t, colMessages, topic.(*Topic).collection)
+	topic.Close()
+
+	topic, err = backend.Topic("a", map[string]string{"collection": "custom"})
+	require.NoError(t, err)
+	defer topic.Close()
+	require.Equal(t, "custom", topic.(*Topic).collection)
+
+	err = topic.Send(context.Background(), &lobby.Message{Value: []byte("Value")})
+	require.NoError(t, err)
+
+	n, err := topic.(*Topic).session.DB("").C("custom").Count()
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/asdine/lobby"
 	"github.com/pkg/errors"
@@ -11,50 +13,146 @@ import (
 const colMessages = "messages"
 
 type message struct {
-	ID    string      `bson:"_id,omitempty"`
-	Topic string      `bson:"topic"`
-	Group string      `bson:"group"`
-	Value interface{} `bson:"value"`
+	ID        string            `bson:"_id,omitempty"`
+	Topic     string            `bson:"topic"`
+	Group     string            `bson:"group"`
+	Value     interface{}       `bson:"value"`
+	CreatedAt time.Time         `bson:"created_at,omitempty"`
+	Headers   map[string]string `bson:"headers,omitempty"`
 }
 
-var _ lobby.Topic = new(Topic)
+var _ lobby.BatchSender = new(Topic)
 
-// NewTopic returns a MongoDB Topic.
-func NewTopic(session *mgo.Session, name string) *Topic {
+// NewTopic returns a MongoDB Topic saving its messages in the given collection.
+func NewTopic(session *mgo.Session, name, collection string) *Topic {
 	return &Topic{
-		session: session,
-		name:    name,
+		session:    session,
+		name:       name,
+		collection: collection,
 	}
 }
 
 // Topic is a MongoDB implementation of a topic.
 type Topic struct {
-	session *mgo.Session
-	name    string
+	session    *mgo.Session
+	name       string
+	collection string
 }
 
 // Send a message to the topic.
-func (t *Topic) Send(m *lobby.Message) error {
-	col := t.session.DB("").C(colMessages)
+func (t *Topic) Send(ctx context.Context, m *lobby.Message) error {
+	err := t.applyDeadline(ctx)
+	if err != nil {
+		return err
+	}
+
+	col := t.session.DB("").C(t.collection)
+
+	doc, err := t.newDocument(m)
+	if err != nil {
+		return err
+	}
+
+	err = col.Insert(doc)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert of update")
+	}
+
+	return nil
+}
+
+// SendBatch inserts the messages using a single unordered bulk operation.
+// A message that fails to be inserted doesn't prevent the others from being inserted.
+func (t *Topic) SendBatch(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+	err := t.applyDeadline(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(messages))
+	docs := make([]interface{}, 0, len(messages))
+	// position in the batch of each inserted document.
+	indexes := make([]int, 0, len(messages))
+
+	for i, m := range messages {
+		doc, err := t.newDocument(m)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+
+		docs = append(docs, doc)
+		indexes = append(indexes, i)
+	}
+
+	if len(docs) == 0 {
+		return errs, nil
+	}
 
+	bulk := t.session.DB("").C(t.collection).Bulk()
+	bulk.Unordered()
+	bulk.Insert(docs...)
+
+	_, err = bulk.Run()
+	if err == nil {
+		return errs, nil
+	}
+
+	berr, ok := err.(*mgo.BulkError)
+	if !ok {
+		return nil, errors.Wrap(err, "failed to insert messages")
+	}
+
+	for _, c := range berr.Cases() {
+		if c.Index < 0 || c.Index >= len(indexes) {
+			return nil, errors.Wrap(c.Err, "failed to insert messages")
+		}
+
+		errs[indexes[c.Index]] = errors.Wrap(c.Err, "failed to insert message")
+	}
+
+	return errs, nil
+}
+
+// applyDeadline makes the operations of the session fail
+// once the deadline of the context is exceeded.
+func (t *Topic) applyDeadline(ctx context.Context) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		t.session.SetSocketTimeout(time.Until(deadline))
+	}
+
+	return nil
+}
+
+// newDocument converts a message to a document. Values that are valid JSON
+// are stored as documents.
+func (t *Topic) newDocument(m *lobby.Message) (*message, error) {
 	var raw interface{}
 
 	valid, err := ValidateBytes(m.Value)
 	if err == nil {
 		err := json.Unmarshal(valid, &raw)
 		if err != nil {
-			return errors.Wrap(err, "failed to unmarshal json")
+			return nil, errors.Wrap(err, "failed to unmarshal json")
 		}
 	} else {
 		raw = m.Value
 	}
 
-	err = col.Insert(&message{Group: m.Group, Topic: t.name, Value: raw})
-	if err != nil {
-		return errors.Wrap(err, "failed to insert of update")
-	}
-
-	return nil
+	// the message ID is used as the document ID, if empty MongoDB generates one.
+	return &message{
+		ID:        m.ID,
+		Group:     m.Group,
+		Topic:     t.name,
+		Value:     raw,
+		CreatedAt: m.CreatedAt,
+		Headers:   m.Headers,
+	}, nil
 }
 
 // Close the topic session.
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -13,11 +14,11 @@ func TestTopicSend(t *testing.T) {
 	backend, cleanup := getBackend(t)
 	defer cleanup()
 
-	tp, err := backend.Topic("topic")
+	tp, err := backend.Topic("topic", nil)
 	require.NoError(t, err)
 
 	for i := 0; i < 5; i++ {
-		err = tp.Send(&lobby.Message{
+		err = tp.Send(context.Background(), &lobby.Message{
 			Group: "group",
 			Value: []byte(fmt.Sprintf("Value%d", i)),
 		})
@@ -31,6 +32,48 @@ func TestTopicSend(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, list, 5)
 	require.Equal(t, []byte("Value0"), list[0].Value)
+
+	m := lobby.NewMessage("meta", []byte("value"), map[string]string{"source": "test"})
+	err = tp.Send(context.Background(), m)
+	require.NoError(t, err)
+
+	var stored message
+	err = col.FindId(m.ID).One(&stored)
+	require.NoError(t, err)
+	require.Equal(t, "meta", stored.Group)
+	require.Equal(t, "test", stored.Headers["source"])
+	require.False(t, stored.CreatedAt.IsZero())
+
 	err = tp.Close()
 	require.NoError(t, err)
 }
+
+func TestTopicSendBatch(t *testing.T) {
+	backend, cleanup := getBackend(t)
+	defer cleanup()
+
+	tp, err := backend.Topic("topic", nil)
+	require.NoError(t, err)
+	defer tp.Close()
+
+	m := lobby.NewMessage("batch", []byte("Value1"), nil)
+	err = tp.Send(context.Background(), m)
+	require.NoError(t, err)
+
+	errs, err := tp.(lobby.BatchSender).SendBatch(context.Background(), []*lobby.Message{
+		lobby.NewMessage("batch", []byte("Value2"), nil),
+		// duplicate ID.
+		m,
+		lobby.NewMessage("batch", []byte(`{"a": "b"}`), nil),
+	})
+	require.NoError(t, err)
+	require.Len(t, errs, 3)
+	require.NoError(t, errs[0])
+	require.Error(t, errs[1])
+	require.NoError(t, errs[2])
+
+	col := tp.(*Topic).session.DB("").C(colMessages)
+	n, err := col.Find(bson.M{"group": "batch"}).Count()
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+}
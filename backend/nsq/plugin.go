@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
@@ -31,6 +32,7 @@ func main() {
 }
 
 var _ lobby.Backend = new(Backend)
+var _ lobby.HealthChecker = new(Backend)
 
 // NewBackend returns a NSQ backend.
 func NewBackend(addr string) (*Backend, error) {
@@ -55,12 +57,33 @@ type Backend struct {
 }
 
 // Topic returns the topic associated with the given name.
-func (s *Backend) Topic(name string) (lobby.Topic, error) {
-	return lobby.TopicFunc(func(m *lobby.Message) error {
+// Messages are published to the NSQ topic of the same name,
+// unless the "topic" option is set.
+func (s *Backend) Topic(name string, options map[string]string) (lobby.Topic, error) {
+	if t, ok := options["topic"]; ok && t != "" {
+		name = t
+	}
+
+	return lobby.TopicFunc(func(ctx context.Context, m *lobby.Message) error {
+		err := ctx.Err()
+		if err != nil {
+			return err
+		}
+
 		return s.producer.Publish(name, m.Value)
 	}), nil
 }
 
+// Health pings the NSQ daemon.
+func (s *Backend) Health(ctx context.Context) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	return s.producer.Ping()
+}
+
 // Close NSQ connection.
 func (s *Backend) Close() error {
 	s.producer.Stop()
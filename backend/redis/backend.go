@@ -1,13 +1,16 @@
 package main
 
 import (
+	"context"
 	"time"
 
 	"github.com/asdine/lobby"
 	"github.com/garyburd/redigo/redis"
+	"github.com/pkg/errors"
 )
 
 var _ lobby.Backend = new(Backend)
+var _ lobby.HealthChecker = new(Backend)
 
 // NewBackend returns a Redis backend.
 func NewBackend(addr string) (*Backend, error) {
@@ -44,8 +47,29 @@ type Backend struct {
 }
 
 // Topic returns the topic associated with the given name.
-func (s *Backend) Topic(name string) (lobby.Topic, error) {
-	return NewTopic(s.pool.Get(), name), nil
+// The "key" option sets the key of the list where the messages are pushed,
+// it defaults to the name of the topic.
+func (s *Backend) Topic(name string, options map[string]string) (lobby.Topic, error) {
+	key := options["key"]
+	if key == "" {
+		key = name
+	}
+
+	return NewTopic(s.pool.Get(), name, key), nil
+}
+
+// Health pings the Redis server.
+func (s *Backend) Health(ctx context.Context) error {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Do("PING")
+	return errors.Wrap(err, "failed to ping redis")
 }
 
 // Close the Redis connection.
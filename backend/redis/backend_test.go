@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"testing"
 
+	"github.com/asdine/lobby"
+	"github.com/garyburd/redigo/redis"
 	"github.com/stretchr/testify/require"
 )
 
@@ -24,7 +27,7 @@ func TestBackend(t *testing.T) {
 	backend, cleanup := getBackend(t)
 	defer cleanup()
 
-	topic, err := backend.Topic("a")
+	topic, err := backend.Topic("a", nil)
 	require.NoError(t, err)
 	require.NotNil(t, topic)
 	require.NotNil(t, topic.(*Topic).conn)
@@ -32,10 +35,10 @@ func TestBackend(t *testing.T) {
 	err = topic.Close()
 	require.NoError(t, err)
 
-	b1, err := backend.Topic("a")
+	b1, err := backend.Topic("a", nil)
 	require.NoError(t, err)
 
-	b2, err := backend.Topic("b")
+	b2, err := backend.Topic("b", nil)
 	require.NoError(t, err)
 
 	err = b1.Close()
@@ -44,3 +47,19 @@ func TestBackend(t *testing.T) {
 	err = b2.Close()
 	require.NoError(t, err)
 }
+
+func TestBackendTopicOptions(t *testing.T) {
+	backend, cleanup := getBackend(t)
+	defer cleanup()
+
+	topic, err := backend.Topic("a", map[string]string{"key": "custom"})
+	require.NoError(t, err)
+	defer topic.Close()
+
+	err = topic.Send(context.Background(), &lobby.Message{Group: "group", Value: []byte("Value")})
+	require.NoError(t, err)
+
+	list, err := redis.ByteSlices(topic.(*Topic).conn.Do("LRANGE", "custom:group", "0", "-1"))
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+}
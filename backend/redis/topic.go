@@ -1,18 +1,23 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"time"
+
 	"github.com/asdine/lobby"
 	"github.com/garyburd/redigo/redis"
 	"github.com/pkg/errors"
 )
 
-var _ lobby.Topic = new(Topic)
+var _ lobby.BatchSender = new(Topic)
 
-// NewTopic returns a Redis Topic.
-func NewTopic(conn redis.Conn, name string) *Topic {
+// NewTopic returns a Redis Topic pushing its messages to the list stored at key.
+func NewTopic(conn redis.Conn, name, key string) *Topic {
 	return &Topic{
 		conn: conn,
 		name: name,
+		key:  key,
 	}
 }
 
@@ -20,17 +25,119 @@ func NewTopic(conn redis.Conn, name string) *Topic {
 type Topic struct {
 	conn redis.Conn
 	name string
+	key  string
+}
+
+// Send message to the topic. The value is pushed to the topic list and,
+// if the message has an ID, its metadata is stored in a hash at lobby:messages:<id>.
+func (t *Topic) Send(ctx context.Context, m *lobby.Message) error {
+	errs, err := t.SendBatch(ctx, []*lobby.Message{m})
+	if err != nil {
+		return err
+	}
+
+	return errs[0]
 }
 
-// Send message to the topic.
-func (t *Topic) Send(m *lobby.Message) error {
-	name := t.name
+// SendBatch pipelines the commands of all the messages and sends them in a single round trip.
+// The context is only checked before sending the commands, they can't be cancelled once sent.
+func (t *Topic) SendBatch(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	// headers are marshaled first to avoid leaving queued commands on the connection.
+	headers := make([][]byte, len(messages))
+	for i, m := range messages {
+		if m.ID == "" {
+			continue
+		}
+
+		headers[i], err = json.Marshal(m.Headers)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal headers")
+		}
+	}
+
+	for i, m := range messages {
+		name := t.listName(m)
+
+		if m.ID == "" {
+			t.conn.Send("RPUSH", name, m.Value)
+			continue
+		}
+
+		t.conn.Send("MULTI")
+		t.conn.Send("RPUSH", name, m.Value)
+		t.conn.Send(
+			"HMSET", metaKey(m.ID),
+			"topic", t.name,
+			"group", m.Group,
+			"created_at", m.CreatedAt.Format(time.RFC3339Nano),
+			"headers", headers[i],
+		)
+		t.conn.Send("EXEC")
+	}
+
+	err = t.conn.Flush()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to send messages")
+	}
+
+	errs := make([]error, len(messages))
+	for i, m := range messages {
+		// MULTI, RPUSH, HMSET and EXEC replies.
+		replies := 4
+		if m.ID == "" {
+			replies = 1
+		}
+
+		for j := 0; j < replies; j++ {
+			reply, err := t.conn.Receive()
+			if err == nil {
+				err = execError(reply)
+			}
+
+			if _, ok := err.(redis.Error); !ok && err != nil {
+				return nil, errors.Wrap(err, "failed to read replies")
+			}
+
+			if err != nil && errs[i] == nil {
+				errs[i] = errors.Wrapf(err, "failed to send message '%s'", t.listName(m))
+			}
+		}
+	}
+
+	return errs, nil
+}
+
+func (t *Topic) listName(m *lobby.Message) string {
 	if m.Group != "" {
-		name += ":" + m.Group
+		return t.key + ":" + m.Group
 	}
 
-	_, err := t.conn.Do("RPUSH", name, m.Value)
-	return errors.Wrapf(err, "failed to send message '%s'", name)
+	return t.key
+}
+
+// execError returns the first error of the reply of an EXEC command.
+func execError(reply interface{}) error {
+	results, ok := reply.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	for _, r := range results {
+		if err, ok := r.(redis.Error); ok {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func metaKey(id string) string {
+	return "lobby:messages:" + id
 }
 
 // Close the topic connection.
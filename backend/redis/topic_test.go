@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -13,11 +14,11 @@ func TestTopicSend(t *testing.T) {
 	backend, cleanup := getBackend(t)
 	defer cleanup()
 
-	tp, err := backend.Topic("topic")
+	tp, err := backend.Topic("topic", nil)
 	require.NoError(t, err)
 
 	for i := 0; i < 5; i++ {
-		err = tp.Send(&lobby.Message{
+		err = tp.Send(context.Background(), &lobby.Message{
 			Group: "group",
 			Value: []byte(fmt.Sprintf("Value%d", i)),
 		})
@@ -28,6 +29,51 @@ func TestTopicSend(t *testing.T) {
 	list, err := redis.ByteSlices(topic.conn.Do("LRANGE", "topic:group", "0", "-1"))
 	require.NoError(t, err)
 	require.Len(t, list, 5)
+
+	m := lobby.NewMessage("meta", []byte("value"), map[string]string{"source": "test"})
+	err = tp.Send(context.Background(), m)
+	require.NoError(t, err)
+
+	meta, err := redis.StringMap(topic.conn.Do("HGETALL", metaKey(m.ID)))
+	require.NoError(t, err)
+	require.Equal(t, "topic", meta["topic"])
+	require.Equal(t, "meta", meta["group"])
+	require.JSONEq(t, `{"source": "test"}`, meta["headers"])
+
 	err = tp.Close()
 	require.NoError(t, err)
 }
+
+func TestTopicSendBatch(t *testing.T) {
+	backend, cleanup := getBackend(t)
+	defer cleanup()
+
+	tp, err := backend.Topic("topic", nil)
+	require.NoError(t, err)
+	defer tp.Close()
+
+	topic := tp.(*Topic)
+	// pushing to a key holding a string fails.
+	_, err = topic.conn.Do("SET", "topic:string", "value")
+	require.NoError(t, err)
+
+	m := lobby.NewMessage("batch", []byte("Value2"), nil)
+	errs, err := topic.SendBatch(context.Background(), []*lobby.Message{
+		{Group: "batch", Value: []byte("Value1")},
+		m,
+		{Group: "string", Value: []byte("Value3")},
+	})
+	require.NoError(t, err)
+	require.Len(t, errs, 3)
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.Error(t, errs[2])
+
+	list, err := redis.ByteSlices(topic.conn.Do("LRANGE", "topic:batch", "0", "-1"))
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	meta, err := redis.StringMap(topic.conn.Do("HGETALL", metaKey(m.ID)))
+	require.NoError(t, err)
+	require.Equal(t, "batch", meta["group"])
+}
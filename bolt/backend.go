@@ -1,18 +1,45 @@
 package bolt
 
 import (
+	"sync"
 	"time"
 
 	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/bolt/boltpb"
+	"github.com/asdine/lobby/log"
 	"github.com/asdine/storm"
 	"github.com/asdine/storm/codec/protobuf"
 	"github.com/coreos/bbolt"
+	"github.com/pkg/errors"
 )
 
 var _ lobby.Backend = new(Backend)
 
+// DefaultRetention sets the retention policy of the topics.
+// Limits set in the options of a topic take precedence.
+func DefaultRetention(r Retention) func(*Backend) {
+	return func(s *Backend) {
+		s.retention = r
+	}
+}
+
+// CompactionInterval sets the period at which retention policies are enforced.
+// If zero, retention policies are only enforced when calling Compact.
+func CompactionInterval(d time.Duration) func(*Backend) {
+	return func(s *Backend) {
+		s.interval = d
+	}
+}
+
+// Logger used to report compaction failures.
+func Logger(logger *log.Logger) func(*Backend) {
+	return func(s *Backend) {
+		s.logger = logger
+	}
+}
+
 // NewBackend returns a BoltDB backend.
-func NewBackend(path string) (*Backend, error) {
+func NewBackend(path string, opts ...func(*Backend)) (*Backend, error) {
 	var err error
 
 	db, err := storm.Open(
@@ -27,22 +54,168 @@ func NewBackend(path string) (*Backend, error) {
 		return nil, err
 	}
 
-	return &Backend{
-		DB: db,
-	}, nil
+	s := Backend{
+		DB:         db,
+		interval:   DefaultCompactionInterval,
+		retentions: make(map[string]Retention),
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+
+	for _, o := range opts {
+		o(&s)
+	}
+
+	if s.logger == nil {
+		s.logger = log.New(log.Prefix("bolt backend:"))
+	}
+
+	go s.compactor()
+
+	return &s, nil
 }
 
 // Backend is a BoltDB backend.
 type Backend struct {
 	DB *storm.DB
+
+	retention Retention
+	interval  time.Duration
+	logger    *log.Logger
+
+	m sync.Mutex
+	// retention policies set in the options of the topics, indexed by topic name.
+	retentions map[string]Retention
+
+	closeOnce sync.Once
+	quit      chan struct{}
+	done      chan struct{}
 }
 
 // Topic returns the topic associated with the given name.
-func (s *Backend) Topic(name string) (lobby.Topic, error) {
+// The options "max-age", "max-messages" and "max-bytes" set the retention policy of the topic.
+func (s *Backend) Topic(name string, options map[string]string) (lobby.Topic, error) {
+	r, err := ParseRetention(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.IsZero() {
+		err = s.setRetention(name, r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return NewTopic(s.DB.From(name)), nil
 }
 
+// setRetention saves the retention policy of a topic so it is enforced
+// even if the topic isn't used after a restart.
+func (s *Backend) setRetention(name string, r Retention) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if cur, ok := s.retentions[name]; ok && cur == r {
+		return nil
+	}
+
+	err := s.DB.From(name).Set(retentionBucket, retentionKey, retentionToProto(r))
+	if err != nil {
+		return errors.Wrapf(err, "failed to save the retention policy of topic %s", name)
+	}
+
+	s.retentions[name] = r
+	return nil
+}
+
+// topicRetention returns the retention policy of a topic, merged with the default one.
+func (s *Backend) topicRetention(name string) (Retention, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	r, ok := s.retentions[name]
+	if !ok {
+		var pb boltpb.Retention
+
+		err := s.DB.From(name).Get(retentionBucket, retentionKey, &pb)
+		if err != nil && err != storm.ErrNotFound {
+			return r, errors.Wrapf(err, "failed to fetch the retention policy of topic %s", name)
+		}
+
+		r = retentionFromProto(&pb)
+		s.retentions[name] = r
+	}
+
+	return r.merge(s.retention), nil
+}
+
+// Compact removes the messages that don't respect the retention policy of their topic.
+func (s *Backend) Compact() error {
+	names, err := topicNames(s.DB)
+	if err != nil {
+		return errors.Wrap(err, "failed to list topics")
+	}
+
+	now := time.Now()
+	for _, name := range names {
+		r, err := s.topicRetention(name)
+		if err != nil {
+			return err
+		}
+
+		if r.IsZero() {
+			continue
+		}
+
+		ev, err := compactTopic(s.DB.From(name), r, now)
+		if err != nil {
+			return errors.Wrapf(err, "failed to compact topic %s", name)
+		}
+
+		if ev.Messages == 0 {
+			continue
+		}
+
+		s.logger.Debugf("Evicted %d messages (%d bytes) from topic %s\n", ev.Messages, ev.Bytes, name)
+		evictedMessages.Add(float64(ev.Messages), name)
+		evictedBytes.Add(float64(ev.Bytes), name)
+	}
+
+	return nil
+}
+
+// compactor calls Compact periodically until the backend is closed.
+func (s *Backend) compactor() {
+	defer close(s.done)
+
+	if s.interval <= 0 {
+		<-s.quit
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+			err := s.Compact()
+			if err != nil {
+				s.logger.Error(err)
+			}
+		}
+	}
+}
+
 // Close BoltDB connection.
 func (s *Backend) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+	})
+	<-s.done
+
 	return s.DB.Close()
 }
@@ -1,12 +1,20 @@
 package bolt_test
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/asdine/lobby"
 	"github.com/asdine/lobby/bolt"
+	"github.com/asdine/lobby/metrics"
 	"github.com/stretchr/testify/require"
 )
 
@@ -33,7 +41,7 @@ func TestBackend(t *testing.T) {
 	require.NoError(t, err)
 	defer s.Close()
 
-	topic, err := s.Topic("a")
+	topic, err := s.Topic("a", nil)
 	require.NoError(t, err)
 	require.NotNil(t, topic)
 	require.NotNil(t, s.DB)
@@ -41,10 +49,10 @@ func TestBackend(t *testing.T) {
 	err = topic.Close()
 	require.NoError(t, err)
 
-	b1, err := s.Topic("a")
+	b1, err := s.Topic("a", nil)
 	require.NoError(t, err)
 
-	b2, err := s.Topic("b")
+	b2, err := s.Topic("b", nil)
 	require.NoError(t, err)
 
 	err = b1.Close()
@@ -53,3 +61,161 @@ func TestBackend(t *testing.T) {
 	err = b2.Close()
 	require.NoError(t, err)
 }
+
+func TestBackendRetention(t *testing.T) {
+	send := func(t *testing.T, topic lobby.Topic, createdAt time.Time, n int) {
+		for i := 0; i < n; i++ {
+			err := topic.Send(context.Background(), &lobby.Message{
+				Value:     []byte(fmt.Sprintf("Value%d", i)),
+				CreatedAt: createdAt,
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	read := func(t *testing.T, topic lobby.Topic) []lobby.Message {
+		list, _, err := topic.(lobby.TopicReader).Read(context.Background(), "", "", 0)
+		require.NoError(t, err)
+		return list
+	}
+
+	// evicted returns the value of an eviction metric of the topic.
+	evicted := func(t *testing.T, metric, topic string) int {
+		var buf bytes.Buffer
+		_, err := metrics.DefaultRegistry.WriteTo(&buf)
+		require.NoError(t, err)
+
+		prefix := fmt.Sprintf("lobby_bolt_evicted_%s_total{topic=%q} ", metric, topic)
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if strings.HasPrefix(line, prefix) {
+				n, err := strconv.Atoi(strings.TrimPrefix(line, prefix))
+				require.NoError(t, err)
+				return n
+			}
+		}
+
+		return 0
+	}
+
+	t.Run("InvalidOptions", func(t *testing.T) {
+		path, cleanup := preparePath(t, "backend.db")
+		defer cleanup()
+
+		s, err := bolt.NewBackend(path, bolt.CompactionInterval(0))
+		require.NoError(t, err)
+		defer s.Close()
+
+		for _, options := range []map[string]string{
+			{"max-age": "1 day"},
+			{"max-messages": "-1"},
+			{"max-bytes": "a"},
+		} {
+			_, err = s.Topic("a", options)
+			require.Error(t, err)
+		}
+	})
+
+	t.Run("MaxMessages", func(t *testing.T) {
+		path, cleanup := preparePath(t, "backend.db")
+		defer cleanup()
+
+		s, err := bolt.NewBackend(path, bolt.CompactionInterval(0))
+		require.NoError(t, err)
+		defer s.Close()
+
+		messages, size := evicted(t, "messages", "a"), evicted(t, "bytes", "a")
+		others := evicted(t, "messages", "b")
+
+		topic, err := s.Topic("a", map[string]string{"max-messages": "2"})
+		require.NoError(t, err)
+		send(t, topic, time.Now(), 5)
+
+		other, err := s.Topic("b", nil)
+		require.NoError(t, err)
+		send(t, other, time.Now(), 5)
+
+		err = s.Compact()
+		require.NoError(t, err)
+
+		list := read(t, topic)
+		require.Len(t, list, 2)
+		require.Equal(t, "Value3", string(list[0].Value))
+		require.Equal(t, "Value4", string(list[1].Value))
+		require.Len(t, read(t, other), 5)
+
+		require.Equal(t, messages+3, evicted(t, "messages", "a"))
+		require.True(t, evicted(t, "bytes", "a") > size)
+		require.Equal(t, others, evicted(t, "messages", "b"))
+	})
+
+	t.Run("MaxAge", func(t *testing.T) {
+		path, cleanup := preparePath(t, "backend.db")
+		defer cleanup()
+
+		s, err := bolt.NewBackend(
+			path,
+			bolt.CompactionInterval(0),
+			bolt.DefaultRetention(bolt.Retention{MaxAge: time.Hour}),
+		)
+		require.NoError(t, err)
+		defer s.Close()
+
+		messages := evicted(t, "messages", "a")
+
+		topic, err := s.Topic("a", nil)
+		require.NoError(t, err)
+		send(t, topic, time.Now().Add(-2*time.Hour), 3)
+		send(t, topic, time.Now(), 2)
+
+		err = s.Compact()
+		require.NoError(t, err)
+		require.Len(t, read(t, topic), 2)
+		require.Equal(t, messages+3, evicted(t, "messages", "a"))
+	})
+
+	t.Run("MaxBytes", func(t *testing.T) {
+		path, cleanup := preparePath(t, "backend.db")
+		defer cleanup()
+
+		s, err := bolt.NewBackend(path, bolt.CompactionInterval(0))
+		require.NoError(t, err)
+
+		topic, err := s.Topic("a", map[string]string{"max-bytes": "1"})
+		require.NoError(t, err)
+		send(t, topic, time.Now(), 3)
+		s.Close()
+
+		// the retention policy of the topic is kept after a restart.
+		s, err = bolt.NewBackend(path, bolt.CompactionInterval(0))
+		require.NoError(t, err)
+		defer s.Close()
+
+		err = s.Compact()
+		require.NoError(t, err)
+
+		topic, err = s.Topic("a", nil)
+		require.NoError(t, err)
+		require.Empty(t, read(t, topic))
+	})
+
+	t.Run("Compactor", func(t *testing.T) {
+		path, cleanup := preparePath(t, "backend.db")
+		defer cleanup()
+
+		s, err := bolt.NewBackend(path, bolt.CompactionInterval(10*time.Millisecond))
+		require.NoError(t, err)
+		defer s.Close()
+
+		messages := evicted(t, "messages", "a")
+
+		topic, err := s.Topic("a", map[string]string{"max-messages": "1"})
+		require.NoError(t, err)
+		send(t, topic, time.Now(), 3)
+
+		deadline := time.Now().Add(time.Second)
+		for evicted(t, "messages", "a") != messages+2 {
+			require.True(t, time.Now().Before(deadline), "messages weren't evicted")
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
@@ -11,6 +11,7 @@ It is generated from these files:
 
 It has these top-level messages:
 	Message
+	Retention
 	Topic
 */
 package boltpb
@@ -34,8 +35,12 @@ type Message struct {
 	// @inject_tag: storm:"id,increment"
 	Id int64 `protobuf:"varint,1,opt,name=id" json:"id,omitempty" storm:"id,increment"`
 	// @inject_tag: storm:"index"
-	Group string `protobuf:"bytes,2,opt,name=group" json:"group,omitempty" storm:"index"`
-	Value []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Group     string `protobuf:"bytes,2,opt,name=group" json:"group,omitempty" storm:"index"`
+	Value     []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	MessageId string `protobuf:"bytes,4,opt,name=message_id,json=messageId" json:"message_id,omitempty"`
+	// Unix time in nanoseconds.
+	CreatedAt int64             `protobuf:"varint,5,opt,name=created_at,json=createdAt" json:"created_at,omitempty"`
+	Headers   map[string]string `protobuf:"bytes,6,rep,name=headers" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *Message) Reset()                    { *m = Message{} }
@@ -43,19 +48,52 @@ func (m *Message) String() string            { return proto.CompactTextString(m)
 func (*Message) ProtoMessage()               {}
 func (*Message) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
 
+func (m *Message) GetHeaders() map[string]string {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+// Retention policy of a topic.
+type Retention struct {
+	// Maximum age of the messages, in nanoseconds.
+	MaxAge int64 `protobuf:"varint,1,opt,name=max_age,json=maxAge" json:"max_age,omitempty"`
+	// Maximum number of messages.
+	MaxMessages int64 `protobuf:"varint,2,opt,name=max_messages,json=maxMessages" json:"max_messages,omitempty"`
+	// Maximum size of the messages, in bytes.
+	MaxBytes int64 `protobuf:"varint,3,opt,name=max_bytes,json=maxBytes" json:"max_bytes,omitempty"`
+}
+
+func (m *Retention) Reset()                    { *m = Retention{} }
+func (m *Retention) String() string            { return proto.CompactTextString(m) }
+func (*Retention) ProtoMessage()               {}
+func (*Retention) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
 func init() {
 	proto.RegisterType((*Message)(nil), "boltpb.Message")
+	proto.RegisterType((*Retention)(nil), "boltpb.Retention")
 }
 
 func init() { proto.RegisterFile("message.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 108 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0xcd, 0x4d, 0x2d, 0x2e,
-	0x4e, 0x4c, 0x4f, 0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x4b, 0xca, 0xcf, 0x29, 0x29,
-	0x48, 0x52, 0x72, 0xe5, 0x62, 0xf7, 0x85, 0x48, 0x08, 0xf1, 0x71, 0x31, 0x65, 0xa6, 0x48, 0x30,
-	0x2a, 0x30, 0x6a, 0x30, 0x07, 0x31, 0x65, 0xa6, 0x08, 0x89, 0x70, 0xb1, 0xa6, 0x17, 0xe5, 0x97,
-	0x16, 0x48, 0x30, 0x29, 0x30, 0x6a, 0x70, 0x06, 0x41, 0x38, 0x20, 0xd1, 0xb2, 0xc4, 0x9c, 0xd2,
-	0x54, 0x09, 0x66, 0x05, 0x46, 0x0d, 0x9e, 0x20, 0x08, 0x27, 0x89, 0x0d, 0x6c, 0xaa, 0x31, 0x20,
-	0x00, 0x00, 0xff, 0xff, 0x21, 0x0b, 0x95, 0x24, 0x66, 0x00, 0x00, 0x00,
+	// 271 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x90, 0x4f, 0x4b, 0xc4, 0x30,
+	0x10, 0xc5, 0x49, 0xe3, 0xb6, 0x66, 0xb6, 0x8a, 0x04, 0xc1, 0xe0, 0x1f, 0xa8, 0x7b, 0xea, 0xa9,
+	0x07, 0x05, 0x91, 0xbd, 0xad, 0x20, 0xe8, 0xc1, 0x4b, 0xbe, 0x40, 0x49, 0xcd, 0x50, 0x8b, 0xdb,
+	0x3f, 0x34, 0x59, 0x69, 0x3f, 0xb9, 0x57, 0x49, 0x1a, 0xd1, 0x5b, 0xdf, 0xfb, 0x4d, 0x33, 0xef,
+	0x0d, 0x9c, 0xb4, 0x68, 0x8c, 0xaa, 0xb1, 0x18, 0xc6, 0xde, 0xf6, 0x3c, 0xae, 0xfa, 0xbd, 0x1d,
+	0xaa, 0xcd, 0x37, 0x81, 0xe4, 0x6d, 0x21, 0xfc, 0x14, 0xa2, 0x46, 0x0b, 0x92, 0x91, 0x9c, 0xca,
+	0xa8, 0xd1, 0xfc, 0x1c, 0x56, 0xf5, 0xd8, 0x1f, 0x06, 0x11, 0x65, 0x24, 0x67, 0x72, 0x11, 0xce,
+	0xfd, 0x52, 0xfb, 0x03, 0x0a, 0x9a, 0x91, 0x3c, 0x95, 0x8b, 0xe0, 0x37, 0x00, 0x61, 0x41, 0xd9,
+	0x68, 0x71, 0xe4, 0x7f, 0x60, 0xc1, 0x79, 0xd5, 0x0e, 0xbf, 0x8f, 0xa8, 0x2c, 0xea, 0x52, 0x59,
+	0xb1, 0xf2, 0x2b, 0x58, 0x70, 0x76, 0x96, 0x3f, 0x40, 0xf2, 0x81, 0x4a, 0xe3, 0x68, 0x44, 0x9c,
+	0xd1, 0x7c, 0x7d, 0x77, 0x5d, 0x2c, 0xf9, 0x8a, 0x90, 0xad, 0x78, 0x59, 0xf0, 0x73, 0x67, 0xc7,
+	0x59, 0xfe, 0x0e, 0x5f, 0x6e, 0x21, 0xfd, 0x0f, 0xf8, 0x19, 0xd0, 0x4f, 0x9c, 0x7d, 0x05, 0x26,
+	0xdd, 0xe7, 0x5f, 0xda, 0xd0, 0xc1, 0x8b, 0x6d, 0xf4, 0x48, 0x36, 0x1a, 0x98, 0x44, 0x8b, 0x9d,
+	0x6d, 0xfa, 0x8e, 0x5f, 0x40, 0xd2, 0xaa, 0xa9, 0x54, 0x35, 0x86, 0xfe, 0x71, 0xab, 0xa6, 0x5d,
+	0x8d, 0xfc, 0x16, 0x52, 0x07, 0x42, 0x13, 0xe3, 0x9f, 0xa1, 0x72, 0xdd, 0xaa, 0x29, 0x24, 0x33,
+	0xfc, 0x0a, 0x98, 0x1b, 0xa9, 0x66, 0x8b, 0xc6, 0x1f, 0x85, 0xca, 0xe3, 0x56, 0x4d, 0x4f, 0x4e,
+	0x57, 0xb1, 0x3f, 0xf7, 0xfd, 0xcf, 0x00, 0x4c, 0xa1, 0xca, 0xe5, 0x7f, 0x01, 0x00, 0x00,
 }
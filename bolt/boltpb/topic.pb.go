@@ -15,8 +15,10 @@ var _ = math.Inf
 
 type Topic struct {
 	// @inject_tag: storm:"id"
-	Name    string `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty" storm:"id"`
-	Backend string `protobuf:"bytes,2,opt,name=Backend" json:"Backend,omitempty"`
+	Name      string            `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty" storm:"id"`
+	Backend   string            `protobuf:"bytes,2,opt,name=Backend" json:"Backend,omitempty"`
+	CreatedAt int64             `protobuf:"varint,3,opt,name=CreatedAt" json:"CreatedAt,omitempty"`
+	Options   map[string]string `protobuf:"bytes,4,rep,name=Options" json:"Options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *Topic) Reset()                    { *m = Topic{} }
@@ -24,6 +26,13 @@ func (m *Topic) String() string            { return proto.CompactTextString(m) }
 func (*Topic) ProtoMessage()               {}
 func (*Topic) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
 
+func (m *Topic) GetOptions() map[string]string {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Topic)(nil), "boltpb.Topic")
 }
@@ -31,11 +40,17 @@ func init() {
 func init() { proto.RegisterFile("topic.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 90 bytes of a gzipped FileDescriptorProto
+	// 181 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x2e, 0xc9, 0x2f, 0xc8,
 	0x4c, 0xd6, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x4b, 0xca, 0xcf, 0x29, 0x29, 0x48, 0x52,
-	0x32, 0xe5, 0x62, 0x0d, 0x01, 0x09, 0x0b, 0x09, 0x71, 0xb1, 0xf8, 0x25, 0xe6, 0xa6, 0x4a, 0x30,
-	0x2a, 0x30, 0x6a, 0x70, 0x06, 0x81, 0xd9, 0x42, 0x12, 0x5c, 0xec, 0x4e, 0x89, 0xc9, 0xd9, 0xa9,
-	0x79, 0x29, 0x12, 0x4c, 0x60, 0x61, 0x18, 0x37, 0x89, 0x0d, 0x6c, 0x8a, 0x31, 0x20, 0x00, 0x00,
-	0xff, 0xff, 0x4f, 0x0a, 0x10, 0x0d, 0x54, 0x00, 0x00, 0x00,
+	0x3a, 0xca, 0xc8, 0xc5, 0x1a, 0x02, 0x12, 0x17, 0x12, 0xe2, 0x62, 0xf1, 0x4b, 0xcc, 0x4d, 0x95,
+	0x60, 0x54, 0x60, 0xd4, 0xe0, 0x0c, 0x02, 0xb3, 0x85, 0x24, 0xb8, 0xd8, 0x9d, 0x12, 0x93, 0xb3,
+	0x53, 0xf3, 0x52, 0x24, 0x98, 0xc0, 0xc2, 0x30, 0xae, 0x90, 0x0c, 0x17, 0xa7, 0x73, 0x51, 0x6a,
+	0x62, 0x49, 0x6a, 0x8a, 0x63, 0x89, 0x04, 0xb3, 0x02, 0xa3, 0x06, 0x73, 0x10, 0x42, 0x40, 0xc8,
+	0x84, 0x8b, 0xdd, 0xbf, 0xa0, 0x24, 0x33, 0x3f, 0xaf, 0x58, 0x82, 0x45, 0x81, 0x59, 0x83, 0xdb,
+	0x48, 0x4a, 0x0f, 0x62, 0x9f, 0x1e, 0xd8, 0x2e, 0x3d, 0xa8, 0xa4, 0x6b, 0x5e, 0x49, 0x51, 0x65,
+	0x10, 0x4c, 0xa9, 0x94, 0x15, 0x17, 0x0f, 0xb2, 0x84, 0x90, 0x00, 0x17, 0x73, 0x76, 0x6a, 0x25,
+	0xd4, 0x41, 0x20, 0xa6, 0x90, 0x08, 0x17, 0x6b, 0x59, 0x62, 0x4e, 0x69, 0x2a, 0xd4, 0x35, 0x10,
+	0x8e, 0x15, 0x93, 0x05, 0x63, 0x12, 0x1b, 0xd8, 0x5b, 0xc6, 0x80, 0x01, 0x00, 0x58, 0xf0, 0x38,
+	0xe6, 0xe5, 0x00, 0x00, 0x00,
 }
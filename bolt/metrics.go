@@ -0,0 +1,18 @@
+package bolt
+
+import (
+	"github.com/asdine/lobby/metrics"
+)
+
+var (
+	evictedMessages = metrics.NewCounter(
+		"lobby_bolt_evicted_messages_total",
+		"Number of messages removed by retention policies, by topic.",
+		"topic",
+	)
+	evictedBytes = metrics.NewCounter(
+		"lobby_bolt_evicted_bytes_total",
+		"Size of the messages removed by retention policies, by topic.",
+		"topic",
+	)
+)
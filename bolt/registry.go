@@ -8,6 +8,7 @@ import (
 	"github.com/asdine/lobby/log"
 	"github.com/asdine/storm"
 	"github.com/asdine/storm/codec/protobuf"
+	"github.com/asdine/storm/index"
 	"github.com/coreos/bbolt"
 	"github.com/pkg/errors"
 )
@@ -51,7 +52,7 @@ func (r *Registry) RegisterBackend(name string, backend lobby.Backend) {
 }
 
 // Create a topic in the registry.
-func (r *Registry) Create(backendName, topicName string) error {
+func (r *Registry) Create(backendName, topicName string, options map[string]string) error {
 	if _, ok := r.backends[backendName]; !ok {
 		return lobby.ErrBackendNotFound
 	}
@@ -74,8 +75,10 @@ func (r *Registry) Create(backendName, topicName string) error {
 	}
 
 	err = tx.Save(&boltpb.Topic{
-		Name:    topicName,
-		Backend: backendName,
+		Name:      topicName,
+		Backend:   backendName,
+		CreatedAt: time.Now().UnixNano(),
+		Options:   options,
 	})
 
 	if err != nil {
@@ -86,6 +89,108 @@ func (r *Registry) Create(backendName, topicName string) error {
 	return errors.Wrap(err, "failed to commit")
 }
 
+// Delete a topic from the registry.
+func (r *Registry) Delete(topicName string) error {
+	tx, err := r.DB.Begin(true)
+	if err != nil {
+		return errors.Wrap(err, "failed to create a transaction")
+	}
+	defer tx.Rollback()
+
+	var topic boltpb.Topic
+
+	err = tx.One("Name", topicName, &topic)
+	if err == storm.ErrNotFound {
+		return lobby.ErrTopicNotFound
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch topic %s", topicName)
+	}
+
+	err = tx.DeleteStruct(&topic)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete topic %s", topicName)
+	}
+
+	err = tx.Commit()
+	return errors.Wrap(err, "failed to commit")
+}
+
+// Rebind a topic to another backend and replace its options.
+func (r *Registry) Rebind(topicName, backendName string, options map[string]string) error {
+	if _, ok := r.backends[backendName]; !ok {
+		return lobby.ErrBackendNotFound
+	}
+
+	tx, err := r.DB.Begin(true)
+	if err != nil {
+		return errors.Wrap(err, "failed to create a transaction")
+	}
+	defer tx.Rollback()
+
+	var topic boltpb.Topic
+
+	err = tx.One("Name", topicName, &topic)
+	if err == storm.ErrNotFound {
+		return lobby.ErrTopicNotFound
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch topic %s", topicName)
+	}
+
+	topic.Backend = backendName
+	topic.Options = options
+
+	err = tx.Save(&topic)
+	if err != nil {
+		return errors.Wrapf(err, "failed to rebind topic %s", topicName)
+	}
+
+	err = tx.Commit()
+	return errors.Wrap(err, "failed to commit")
+}
+
+// Info returns informations about the selected topic.
+func (r *Registry) Info(topicName string) (*lobby.TopicInfo, error) {
+	var topic boltpb.Topic
+
+	err := r.DB.One("Name", topicName, &topic)
+	if err == storm.ErrNotFound {
+		return nil, lobby.ErrTopicNotFound
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch topic %s", topicName)
+	}
+
+	info := topicInfo(&topic)
+	return &info, nil
+}
+
+// List topics ordered by name.
+func (r *Registry) List(offset, limit int) ([]lobby.TopicInfo, error) {
+	var topics []boltpb.Topic
+
+	opts := []func(*index.Options){storm.Skip(offset)}
+	if limit > 0 {
+		opts = append(opts, storm.Limit(limit))
+	}
+
+	err := r.DB.All(&topics, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list topics")
+	}
+
+	list := make([]lobby.TopicInfo, len(topics))
+	for i := range topics {
+		list[i] = topicInfo(&topics[i])
+	}
+
+	return list, nil
+}
+
 // Topic returns the selected topic from the Backend.
 func (r *Registry) Topic(name string) (lobby.Topic, error) {
 	var topic boltpb.Topic
@@ -104,7 +209,21 @@ func (r *Registry) Topic(name string) (lobby.Topic, error) {
 		return nil, lobby.ErrTopicNotFound
 	}
 
-	return backend.Topic(name)
+	return backend.Topic(name, topic.Options)
+}
+
+func topicInfo(t *boltpb.Topic) lobby.TopicInfo {
+	info := lobby.TopicInfo{
+		Name:    t.Name,
+		Backend: t.Backend,
+		Options: t.Options,
+	}
+
+	if t.CreatedAt != 0 {
+		info.CreatedAt = time.Unix(0, t.CreatedAt)
+	}
+
+	return info
 }
 
 // Close BoltDB connection and registered backends.
@@ -25,22 +25,22 @@ func TestRegistry(t *testing.T) {
 		require.NoError(t, err)
 		defer r.Close()
 
-		err = r.Create("bolt1", "a")
+		err = r.Create("bolt1", "a", nil)
 		require.Equal(t, lobby.ErrBackendNotFound, err)
 
 		r.RegisterBackend("bolt1", s)
 		r.RegisterBackend("bolt2", s)
 
-		err = r.Create("bolt1", "a")
+		err = r.Create("bolt1", "a", nil)
 		require.NoError(t, err)
 
-		err = r.Create("bolt1", "a")
+		err = r.Create("bolt1", "a", nil)
 		require.Equal(t, lobby.ErrTopicAlreadyExists, err)
 
-		err = r.Create("bolt1", "b")
+		err = r.Create("bolt1", "b", nil)
 		require.NoError(t, err)
 
-		err = r.Create("bolt2", "a")
+		err = r.Create("bolt2", "a", nil)
 		require.Equal(t, lobby.ErrTopicAlreadyExists, err)
 	})
 
@@ -60,21 +60,140 @@ func TestRegistry(t *testing.T) {
 		b, err = r.Topic("a")
 		require.Equal(t, lobby.ErrTopicNotFound, err)
 
-		err = r.Create("bolt1", "a")
+		err = r.Create("bolt1", "a", nil)
 		require.NoError(t, err)
 
 		b, err = r.Topic("a")
 		require.NoError(t, err)
 		require.NotNil(t, b)
 
-		err = r.Create("bolt2", "b")
+		err = r.Create("bolt2", "b", nil)
 		require.NoError(t, err)
 
 		b, err = r.Topic("b")
 		require.NoError(t, err)
 		require.NotNil(t, b)
 
-		err = r.Create("bolt2", "a")
+		err = r.Create("bolt2", "a", nil)
 		require.Equal(t, lobby.ErrTopicAlreadyExists, err)
 	})
+
+	t.Run("info", func(t *testing.T) {
+		pathReg, cleanupReg := preparePath(t, "reg.db")
+		defer cleanupReg()
+		r, err := bolt.NewRegistry(pathReg, log.New(log.Output(ioutil.Discard)))
+		require.NoError(t, err)
+		defer r.Close()
+
+		r.RegisterBackend("bolt1", s)
+
+		_, err = r.Info("a")
+		require.Equal(t, lobby.ErrTopicNotFound, err)
+
+		err = r.Create("bolt1", "a", map[string]string{"key": "value"})
+		require.NoError(t, err)
+
+		info, err := r.Info("a")
+		require.NoError(t, err)
+		require.Equal(t, "a", info.Name)
+		require.Equal(t, "bolt1", info.Backend)
+		require.Equal(t, map[string]string{"key": "value"}, info.Options)
+		require.False(t, info.CreatedAt.IsZero())
+	})
+
+	t.Run("list", func(t *testing.T) {
+		pathReg, cleanupReg := preparePath(t, "reg.db")
+		defer cleanupReg()
+		r, err := bolt.NewRegistry(pathReg, log.New(log.Output(ioutil.Discard)))
+		require.NoError(t, err)
+		defer r.Close()
+
+		r.RegisterBackend("bolt1", s)
+
+		list, err := r.List(0, 10)
+		require.NoError(t, err)
+		require.Len(t, list, 0)
+
+		for _, name := range []string{"e", "b", "d", "a", "c"} {
+			err = r.Create("bolt1", name, nil)
+			require.NoError(t, err)
+		}
+
+		list, err = r.List(0, 0)
+		require.NoError(t, err)
+		require.Len(t, list, 5)
+		require.Equal(t, "a", list[0].Name)
+		require.Equal(t, "e", list[4].Name)
+
+		list, err = r.List(1, 2)
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		require.Equal(t, "b", list[0].Name)
+		require.Equal(t, "c", list[1].Name)
+		require.Equal(t, "bolt1", list[1].Backend)
+
+		list, err = r.List(10, 2)
+		require.NoError(t, err)
+		require.Len(t, list, 0)
+	})
+
+	t.Run("rebind", func(t *testing.T) {
+		pathReg, cleanupReg := preparePath(t, "reg.db")
+		defer cleanupReg()
+		r, err := bolt.NewRegistry(pathReg, log.New(log.Output(ioutil.Discard)))
+		require.NoError(t, err)
+		defer r.Close()
+
+		r.RegisterBackend("bolt1", s)
+		r.RegisterBackend("bolt2", s)
+
+		err = r.Rebind("a", "bolt2", nil)
+		require.Equal(t, lobby.ErrTopicNotFound, err)
+
+		err = r.Create("bolt1", "a", map[string]string{"key": "value"})
+		require.NoError(t, err)
+
+		before, err := r.Info("a")
+		require.NoError(t, err)
+
+		err = r.Rebind("a", "bolt3", nil)
+		require.Equal(t, lobby.ErrBackendNotFound, err)
+
+		err = r.Rebind("a", "bolt2", map[string]string{"other": "value"})
+		require.NoError(t, err)
+
+		info, err := r.Info("a")
+		require.NoError(t, err)
+		require.Equal(t, "bolt2", info.Backend)
+		require.Equal(t, map[string]string{"other": "value"}, info.Options)
+		require.Equal(t, before.CreatedAt, info.CreatedAt)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		pathReg, cleanupReg := preparePath(t, "reg.db")
+		defer cleanupReg()
+		r, err := bolt.NewRegistry(pathReg, log.New(log.Output(ioutil.Discard)))
+		require.NoError(t, err)
+		defer r.Close()
+
+		r.RegisterBackend("bolt1", s)
+
+		err = r.Delete("a")
+		require.Equal(t, lobby.ErrTopicNotFound, err)
+
+		err = r.Create("bolt1", "a", nil)
+		require.NoError(t, err)
+
+		err = r.Delete("a")
+		require.NoError(t, err)
+
+		_, err = r.Topic("a")
+		require.Equal(t, lobby.ErrTopicNotFound, err)
+
+		err = r.Delete("a")
+		require.Equal(t, lobby.ErrTopicNotFound, err)
+
+		err = r.Create("bolt1", "a", nil)
+		require.NoError(t, err)
+	})
 }
@@ -0,0 +1,223 @@
+package bolt
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asdine/lobby/bolt/boltpb"
+	"github.com/asdine/storm"
+	"github.com/coreos/bbolt"
+	"github.com/pkg/errors"
+)
+
+// DefaultCompactionInterval is the default period at which retention policies are enforced.
+const DefaultCompactionInterval = time.Minute
+
+// Bucket and key under which the retention policy of a topic is stored, inside the topic bucket.
+const (
+	retentionBucket = "retention"
+	retentionKey    = "policy"
+)
+
+// Retention policy of a topic. Messages are evicted, oldest first, until all limits are respected.
+// A zero limit is disabled.
+type Retention struct {
+	// Maximum age of the messages.
+	MaxAge time.Duration
+	// Maximum number of messages.
+	MaxMessages int64
+	// Maximum size of the messages, in bytes.
+	MaxBytes int64
+}
+
+// IsZero returns true if no limit is set.
+func (r Retention) IsZero() bool {
+	return r == Retention{}
+}
+
+// merge returns a copy of r where unset limits are taken from defaults.
+func (r Retention) merge(defaults Retention) Retention {
+	if r.MaxAge == 0 {
+		r.MaxAge = defaults.MaxAge
+	}
+
+	if r.MaxMessages == 0 {
+		r.MaxMessages = defaults.MaxMessages
+	}
+
+	if r.MaxBytes == 0 {
+		r.MaxBytes = defaults.MaxBytes
+	}
+
+	return r
+}
+
+// ParseRetention reads the retention policy from topic options.
+// The supported options are "max-age" (a duration like "24h"), "max-messages" and "max-bytes".
+func ParseRetention(options map[string]string) (Retention, error) {
+	var r Retention
+	var err error
+
+	if v, ok := options["max-age"]; ok {
+		r.MaxAge, err = time.ParseDuration(strings.TrimSpace(v))
+		if err != nil || r.MaxAge < 0 {
+			return r, errors.Errorf("invalid max-age option '%s'", v)
+		}
+	}
+
+	if v, ok := options["max-messages"]; ok {
+		r.MaxMessages, err = strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil || r.MaxMessages < 0 {
+			return r, errors.Errorf("invalid max-messages option '%s'", v)
+		}
+	}
+
+	if v, ok := options["max-bytes"]; ok {
+		r.MaxBytes, err = strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil || r.MaxBytes < 0 {
+			return r, errors.Errorf("invalid max-bytes option '%s'", v)
+		}
+	}
+
+	return r, nil
+}
+
+// Eviction counts what was removed from a topic by its retention policy.
+type Eviction struct {
+	Messages int64
+	Bytes    int64
+}
+
+// messageBucket is the bucket in which storm saves the messages of a topic.
+const messageBucket = "Message"
+
+// compactTopic removes the messages of the topic that don't respect the retention policy.
+// The messages are scanned in a read-only transaction, a write transaction is only
+// opened if some of them must be removed.
+func compactTopic(node storm.Node, r Retention, now time.Time) (Eviction, error) {
+	ids, ev, err := evictable(node, r, now)
+	if err != nil || len(ids) == 0 {
+		return Eviction{}, err
+	}
+
+	tx, err := node.Begin(true)
+	if err != nil {
+		return Eviction{}, errors.Wrap(err, "failed to create bolt transaction")
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		err = tx.DeleteStruct(&boltpb.Message{Id: id})
+		if err != nil && err != storm.ErrNotFound {
+			return Eviction{}, errors.Wrapf(err, "failed to delete message %d", id)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return Eviction{}, errors.Wrap(err, "failed to commit bolt transaction")
+	}
+
+	return ev, nil
+}
+
+// evictable returns the IDs of the messages of the topic that don't respect the retention policy,
+// oldest first. The scan stops at the first message respecting every limit, the following ones are newer.
+func evictable(node storm.Node, r Retention, now time.Time) ([]int64, Eviction, error) {
+	var ids []int64
+	var ev Eviction
+
+	err := node.GetBolt().View(func(tx *bolt.Tx) error {
+		b := node.GetBucket(tx, messageBucket)
+		if b == nil {
+			return nil
+		}
+
+		var count, total int64
+		if r.MaxMessages > 0 || r.MaxBytes > 0 {
+			// counting the messages doesn't require decoding them.
+			err := b.ForEach(func(k, v []byte) error {
+				// nested buckets have no value.
+				if v != nil {
+					count++
+					total += int64(len(v))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		var cutoff int64
+		if r.MaxAge > 0 {
+			cutoff = now.Add(-r.MaxAge).UnixNano()
+		}
+
+		// messages are iterated in insertion order.
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if v == nil {
+				continue
+			}
+
+			var m boltpb.Message
+			err := node.Codec().Unmarshal(v, &m)
+			if err != nil {
+				return errors.Wrap(err, "failed to decode message")
+			}
+
+			expired := cutoff != 0 && m.CreatedAt != 0 && m.CreatedAt < cutoff
+			tooMany := r.MaxMessages > 0 && count-ev.Messages > r.MaxMessages
+			tooLarge := r.MaxBytes > 0 && total-ev.Bytes > r.MaxBytes
+			if !expired && !tooMany && !tooLarge {
+				break
+			}
+
+			ids = append(ids, m.Id)
+			ev.Messages++
+			ev.Bytes += int64(len(v))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, Eviction{}, errors.Wrap(err, "failed to read messages")
+	}
+
+	return ids, ev, nil
+}
+
+// topicNames returns the name of every topic stored in the database.
+func topicNames(db *storm.DB) ([]string, error) {
+	var names []string
+
+	err := db.Bolt.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			// skipping storm's internal buckets.
+			if !strings.HasPrefix(string(name), "__storm") {
+				names = append(names, string(name))
+			}
+			return nil
+		})
+	})
+
+	return names, err
+}
+
+func retentionFromProto(pb *boltpb.Retention) Retention {
+	return Retention{
+		MaxAge:      time.Duration(pb.MaxAge),
+		MaxMessages: pb.MaxMessages,
+		MaxBytes:    pb.MaxBytes,
+	}
+}
+
+func retentionToProto(r Retention) *boltpb.Retention {
+	return &boltpb.Retention{
+		MaxAge:      int64(r.MaxAge),
+		MaxMessages: r.MaxMessages,
+		MaxBytes:    r.MaxBytes,
+	}
+}
@@ -1,13 +1,19 @@
 package bolt
 
 import (
+	"context"
+	"strconv"
+	"time"
+
 	"github.com/asdine/lobby"
 	"github.com/asdine/lobby/bolt/boltpb"
 	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
 	"github.com/pkg/errors"
 )
 
-var _ lobby.Topic = new(Topic)
+var _ lobby.TopicReader = new(Topic)
+var _ lobby.BatchSender = new(Topic)
 
 // NewTopic returns a Topic
 func NewTopic(node storm.Node) *Topic {
@@ -22,27 +28,107 @@ type Topic struct {
 }
 
 // Send a message to the topic.
-func (t *Topic) Send(message *lobby.Message) error {
+func (t *Topic) Send(ctx context.Context, message *lobby.Message) error {
+	_, err := t.SendBatch(ctx, []*lobby.Message{message})
+	return err
+}
+
+// SendBatch saves all the messages in a single transaction.
+// Either all the messages are saved or none of them is.
+// The context is checked before the transaction is started.
+func (t *Topic) SendBatch(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
 	tx, err := t.node.Begin(true)
 	if err != nil {
-		return errors.Wrap(err, "failed to create bolt transaction")
+		return nil, errors.Wrap(err, "failed to create bolt transaction")
 	}
 	defer tx.Rollback()
 
-	err = tx.Save(&boltpb.Message{
-		Group: message.Group,
-		Value: message.Value,
-	})
-	if err != nil {
-		return err
+	for _, message := range messages {
+		m := boltpb.Message{
+			MessageId: message.ID,
+			Group:     message.Group,
+			Value:     message.Value,
+			Headers:   message.Headers,
+		}
+
+		if !message.CreatedAt.IsZero() {
+			m.CreatedAt = message.CreatedAt.UnixNano()
+		}
+
+		err = tx.Save(&m)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return errors.Wrap(err, "failed to commit bolt transaction")
+		return nil, errors.Wrap(err, "failed to commit bolt transaction")
 	}
 
-	return nil
+	return make([]error, len(messages)), nil
+}
+
+// Read messages stored after the given cursor. The cursor is the id of the last read message.
+func (t *Topic) Read(ctx context.Context, group, cursor string, limit int) ([]lobby.Message, string, error) {
+	var after int64
+
+	err := ctx.Err()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if cursor != "" {
+		after, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil || after < 0 {
+			return nil, "", lobby.ErrInvalidCursor
+		}
+	}
+
+	matchers := []q.Matcher{q.Gt("Id", after)}
+	if group != "" {
+		matchers = append(matchers, q.Eq("Group", group))
+	}
+
+	var list []boltpb.Message
+
+	query := t.node.Select(matchers...)
+	if limit > 0 {
+		// fetching one more message to know if there are more messages to read.
+		query = query.Limit(limit + 1)
+	}
+
+	err = query.Find(&list)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, "", errors.Wrap(err, "failed to read messages")
+	}
+
+	var next string
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+		next = strconv.FormatInt(list[limit-1].Id, 10)
+	}
+
+	messages := make([]lobby.Message, len(list))
+	for i := range list {
+		messages[i] = lobby.Message{
+			ID:      list[i].MessageId,
+			Group:   list[i].Group,
+			Value:   list[i].Value,
+			Headers: list[i].Headers,
+		}
+
+		if list[i].CreatedAt != 0 {
+			messages[i].CreatedAt = time.Unix(0, list[i].CreatedAt).UTC()
+		}
+	}
+
+	return messages, next, nil
 }
 
 // Close the topic session.
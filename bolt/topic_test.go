@@ -1,6 +1,8 @@
 package bolt_test
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/asdine/lobby"
@@ -16,10 +18,10 @@ func TestTopicSend(t *testing.T) {
 	bk, err := bolt.NewBackend(path)
 	require.NoError(t, err)
 
-	tp, err := bk.Topic("1a")
+	tp, err := bk.Topic("1a", nil)
 	require.NoError(t, err)
 
-	err = tp.Send(&lobby.Message{
+	err = tp.Send(context.Background(), &lobby.Message{
 		Group: "2a",
 		Value: []byte("Value"),
 	})
@@ -30,7 +32,7 @@ func TestTopicSend(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, m, 1)
 
-	err = tp.Send(&lobby.Message{
+	err = tp.Send(context.Background(), &lobby.Message{
 		Group: "2a",
 		Value: []byte("New Value"),
 	})
@@ -43,3 +45,107 @@ func TestTopicSend(t *testing.T) {
 	err = tp.Close()
 	require.NoError(t, err)
 }
+
+func TestTopicSendBatch(t *testing.T) {
+	path, cleanup := preparePath(t, "store.db")
+	defer cleanup()
+
+	bk, err := bolt.NewBackend(path)
+	require.NoError(t, err)
+	defer bk.Close()
+
+	tp, err := bk.Topic("topic", nil)
+	require.NoError(t, err)
+	defer tp.Close()
+
+	errs, err := tp.(lobby.BatchSender).SendBatch(context.Background(), []*lobby.Message{
+		{Group: "a", Value: []byte("Value1")},
+		{Group: "a", Value: []byte("Value2")},
+		{Group: "b", Value: []byte("Value3")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []error{nil, nil, nil}, errs)
+
+	var m []boltpb.Message
+	err = bk.DB.From("topic").All(&m)
+	require.NoError(t, err)
+	require.Len(t, m, 3)
+	require.Equal(t, "Value3", string(m[2].Value))
+}
+
+func TestTopicRead(t *testing.T) {
+	path, cleanup := preparePath(t, "store.db")
+	defer cleanup()
+
+	bk, err := bolt.NewBackend(path)
+	require.NoError(t, err)
+	defer bk.Close()
+
+	tp, err := bk.Topic("topic", nil)
+	require.NoError(t, err)
+	defer tp.Close()
+
+	r := tp.(lobby.TopicReader)
+
+	list, next, err := r.Read(context.Background(), "", "", 10)
+	require.NoError(t, err)
+	require.Len(t, list, 0)
+	require.Empty(t, next)
+
+	for i := 0; i < 10; i++ {
+		group := "a"
+		if i%2 == 0 {
+			group = "b"
+		}
+
+		err = tp.Send(context.Background(), lobby.NewMessage(group, []byte(fmt.Sprintf("Value%d", i)), map[string]string{"index": fmt.Sprint(i)}))
+		require.NoError(t, err)
+	}
+
+	list, next, err = r.Read(context.Background(), "", "", 4)
+	require.NoError(t, err)
+	require.Len(t, list, 4)
+	require.Equal(t, "Value0", string(list[0].Value))
+	require.Equal(t, "b", list[0].Group)
+	require.NotEmpty(t, list[0].ID)
+	require.False(t, list[0].CreatedAt.IsZero())
+	require.Equal(t, "0", list[0].Headers["index"])
+	require.NotEmpty(t, next)
+
+	list, next, err = r.Read(context.Background(), "", next, 4)
+	require.NoError(t, err)
+	require.Len(t, list, 4)
+	require.Equal(t, "Value4", string(list[0].Value))
+	require.NotEmpty(t, next)
+
+	list, next, err = r.Read(context.Background(), "", next, 4)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, "Value8", string(list[0].Value))
+	require.Empty(t, next)
+
+	list, next, err = r.Read(context.Background(), "a", "", 0)
+	require.NoError(t, err)
+	require.Len(t, list, 5)
+	require.Equal(t, "Value1", string(list[0].Value))
+	require.Empty(t, next)
+
+	list, next, err = r.Read(context.Background(), "a", "", 3)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	require.NotEmpty(t, next)
+
+	list, next, err = r.Read(context.Background(), "a", next, 3)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, "Value7", string(list[0].Value))
+	require.Empty(t, next)
+
+	_, _, err = r.Read(context.Background(), "", "some cursor", 3)
+	require.Equal(t, lobby.ErrInvalidCursor, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = r.Read(ctx, "", "", 3)
+	require.Equal(t, context.Canceled, err)
+}
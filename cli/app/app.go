@@ -7,7 +7,10 @@ import (
 	"sync"
 
 	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/auth"
+	"github.com/asdine/lobby/health"
 	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/ratelimit"
 )
 
 // App is the main application. It bootstraps all the components
@@ -21,6 +24,9 @@ type App struct {
 	errc     chan error
 	out      io.Writer
 	registry lobby.Registry
+	health   *health.Monitor
+	keys     *auth.Keys
+	limiter  *ratelimit.Limiter
 	steps    steps
 }
 
@@ -32,21 +38,22 @@ func (a *App) Run(ctx context.Context) error {
 		a.out = os.Stderr
 	}
 
-	a.Logger = log.New(
-		log.Prefix("lobby:"),
-		log.Output(a.out),
-		log.Debug(a.Config.Debug),
-	)
+	a.Logger = newLogger(a, "lobby:")
 
 	a.logLobbyInfos()
 
 	if a.steps == nil {
 		a.steps = []step{
 			directoriesStep(),
+			new(tracingStep),
+			authStep(),
+			rateLimitStep(),
+			new(healthStep),
 			new(registryStep),
 			boltBackendStep(),
 			newBackendPluginsStep(),
 			newGRPCUnixSocketStep(a),
+			newServerPluginsStep(),
 			newGRPCPortStep(a),
 			newHTTPStep(a),
 		}
@@ -54,7 +61,7 @@ func (a *App) Run(ctx context.Context) error {
 
 	err := a.steps.setup(ctx, a)
 	if err != nil && err != context.Canceled {
-		a.Logger.Println(err)
+		a.Logger.Error(err)
 		errs = append(errs, err)
 	}
 
@@ -98,6 +105,16 @@ func (a *App) Run(ctx context.Context) error {
 	return nil
 }
 
+// newLogger returns a logger configured by the app, identified by the prefix.
+func newLogger(app *App, prefix string) *log.Logger {
+	return log.New(
+		log.Prefix(prefix),
+		log.Output(app.out),
+		log.Debug(app.Config.Debug),
+		log.Format(app.Config.Log.Format),
+	)
+}
+
 func (a *App) logLobbyInfos() {
 	a.Logger.Println("lobby Version:", lobby.Version)
 	a.Logger.Debug("Debug mode enabled")
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+
+	"github.com/asdine/lobby/auth"
+)
+
+func authStep() step {
+	return setupFunc(func(ctx context.Context, app *App) error {
+		if app.Config.Auth.KeyFile == "" {
+			app.Logger.Debug("Authentication disabled")
+			return nil
+		}
+
+		keys, err := auth.LoadKeyFile(app.Config.Auth.KeyFile)
+		if err != nil {
+			return err
+		}
+
+		app.keys = keys
+		return nil
+	})
+}
+
+// authenticator returns the authenticator of the clients, or nil if authentication is disabled.
+func authenticator(app *App) auth.Authenticator {
+	if app.keys == nil {
+		return nil
+	}
+
+	return app.keys
+}
@@ -27,13 +27,30 @@ func boltBackendStep() step {
 
 		backendPath := path.Join(boltPath, "backend.db")
 
+		interval := app.Config.Bolt.CompactionInterval.Duration
+		if interval == 0 {
+			interval = bolt.DefaultCompactionInterval
+		}
+
+		retention := bolt.Retention{
+			MaxAge:      app.Config.Bolt.Retention.MaxAge.Duration,
+			MaxMessages: app.Config.Bolt.Retention.MaxMessages,
+			MaxBytes:    app.Config.Bolt.Retention.MaxBytes,
+		}
+
 		// Creating default backend.
-		bck, err := bolt.NewBackend(backendPath)
+		bck, err := bolt.NewBackend(
+			backendPath,
+			bolt.DefaultRetention(retention),
+			bolt.CompactionInterval(interval),
+			bolt.Logger(newLogger(app, "bolt backend:")),
+		)
 		if err != nil {
 			return err
 		}
 
 		app.registry.RegisterBackend("bolt", bck)
+		monitorBackend(app, "bolt", bck)
 		return nil
 	})
 }
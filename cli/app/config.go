@@ -4,34 +4,265 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/middleware"
+	"github.com/asdine/lobby/outbox"
+	"github.com/asdine/lobby/trace"
 	"github.com/coreos/etcd/clientv3"
 	"github.com/pkg/errors"
 )
 
 // Config of the application.
 type Config struct {
-	Debug    bool
+	Debug bool
+	// Output of the logs of Lobby and its plugins.
+	Log struct {
+		// Format of the logs, "text" or "json". Defaults to "text".
+		Format string
+	}
 	Registry string
 	HTTP     struct {
 		Port int
+		TLS  TLS
 	}
 	Grpc struct {
 		Port int
+		TLS  TLS
 	}
 	Bolt struct {
 		Backend bool
+		// Period at which retention policies are enforced. Defaults to bolt.DefaultCompactionInterval.
+		CompactionInterval Duration `toml:"compaction-interval"`
+		// Retention policy of the topics without their own.
+		Retention struct {
+			MaxAge      Duration `toml:"max-age"`
+			MaxMessages int64    `toml:"max-messages"`
+			MaxBytes    int64    `toml:"max-bytes"`
+		}
+	}
+	// Authentication of the HTTP and gRPC clients. Disabled if no key file is set.
+	Auth struct {
+		// Path of the file containing the API keys, the JWT keys and the permissions of the clients.
+		KeyFile string `toml:"key-file"`
+	}
+	// Rate limits of the HTTP and gRPC clients. Limits with a zero rate are disabled.
+	RateLimit struct {
+		// Limit of the requests of each client, identified by its verified identity or its address.
+		Client Limit
+		// Default limit of the messages sent to each topic, a batch counts each of its messages.
+		Topic Limit
+		// Limits of specific topics, indexed by topic name.
+		Topics map[string]Limit
+	} `toml:"rate-limit"`
+	// Health checks of the backends.
+	Health struct {
+		// Period of the checks. Defaults to health.DefaultInterval.
+		Interval Duration
+		// Maximum duration of a check. Defaults to health.DefaultTimeout.
+		Timeout Duration
 	}
+	// Middlewares processing the messages before they are sent to their topic.
+	Middlewares Middlewares
+	// Tracing of the requests, shared by Lobby and its plugins.
+	Tracing Tracing
 	Etcd    clientv3.Config
 	Paths   Paths
 	Plugins Plugins
 }
 
+// Tracing configuration. Spans are only exported if an exporter is set.
+type Tracing struct {
+	// Exporter of the spans, either "file" or "otlp".
+	Exporter string
+	// Path of the file the spans are appended to by the file exporter.
+	File string
+	// URL of the traces route of the OpenTelemetry collector used by the otlp exporter.
+	// Defaults to DefaultOTLPEndpoint.
+	Endpoint string
+}
+
+// DefaultOTLPEndpoint is the default URL spans are sent to by the otlp exporter.
+const DefaultOTLPEndpoint = "http://localhost:4318/v1/traces"
+
+// NewExporter returns the exporter of the spans of the given service, or nil if tracing is disabled.
+func (t *Tracing) NewExporter(service string, logger *log.Logger) (trace.Exporter, error) {
+	switch t.Exporter {
+	case "":
+		return nil, nil
+	case "file":
+		if t.File == "" {
+			return nil, errors.New("unspecified trace file")
+		}
+
+		return trace.NewFileExporter(t.File, service)
+	case "otlp":
+		endpoint := t.Endpoint
+		if endpoint == "" {
+			endpoint = DefaultOTLPEndpoint
+		}
+
+		return trace.NewOTLPExporter(endpoint, service, logger), nil
+	default:
+		return nil, errors.Errorf("unknown trace exporter %q", t.Exporter)
+	}
+}
+
 // Plugins contains the list of backend and server plugins.
 type Plugins struct {
 	Backends []string
+	Servers  []string
 	Config   map[string]toml.Primitive
+	// Maximum duration of the operations of each backend plugin, indexed by plugin name.
+	// Defaults to rpc.DefaultTimeout.
+	Timeouts map[string]Duration
+	// Restart policy of the backend plugins that crash. Unset fields default to rpc.DefaultRestartPolicy.
+	Restart struct {
+		// Maximum number of restarts within the window. Restarts are disabled if negative.
+		MaxRestarts int `toml:"max-restarts"`
+		Window      Duration
+		MinBackoff  Duration `toml:"min-backoff"`
+		MaxBackoff  Duration `toml:"max-backoff"`
+	}
+	// Durable outbox of the backend plugins. Messages that can't be sent because a backend is unavailable
+	// are stored in the data directory and sent again, in order, once it recovers.
+	Outbox Outbox
+}
+
+// Outbox configuration. The outbox is disabled if no backend uses it.
+type Outbox struct {
+	// Backend plugins using the outbox.
+	Backends []string
+	// Maximum number of messages stored per backend. Defaults to outbox.DefaultMaxMessages.
+	MaxMessages int64 `toml:"max-messages"`
+	// Maximum size of the messages stored per backend, in bytes. Disabled if zero.
+	MaxBytes int64 `toml:"max-bytes"`
+	// Behaviour when the outbox of a backend is full, "reject" new messages or "drop-oldest". Defaults to "reject".
+	Overflow string
+	// Period at which the stored messages are sent again. Defaults to outbox.DefaultRetryInterval.
+	RetryInterval Duration `toml:"retry-interval"`
+}
+
+// Enabled returns true if the given backend uses the outbox.
+func (o *Outbox) Enabled(backend string) bool {
+	for _, name := range o.Backends {
+		if name == backend {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Policy returns the size policy of the outbox of each backend.
+func (o *Outbox) Policy() (outbox.Policy, error) {
+	p := outbox.Policy{
+		MaxMessages: o.MaxMessages,
+		MaxBytes:    o.MaxBytes,
+	}
+
+	if p.MaxMessages == 0 {
+		p.MaxMessages = outbox.DefaultMaxMessages
+	}
+
+	switch o.Overflow {
+	case "", "reject":
+	case "drop-oldest":
+		p.DropOldest = true
+	default:
+		return p, errors.Errorf("unknown outbox overflow policy %q", o.Overflow)
+	}
+
+	return p, nil
+}
+
+// TLS configuration of a server. TLS is enabled if a certificate is set.
+type TLS struct {
+	// Certificate and private key of the server, PEM encoded. They are reloaded when changed.
+	CertFile string `toml:"cert-file"`
+	KeyFile  string `toml:"key-file"`
+	// Certificate authorities used to verify client certificates. If set, clients must present a valid certificate.
+	ClientCAFile string `toml:"client-ca-file"`
+	// Minimum TLS version, like "1.2". Defaults to "1.2".
+	MinVersion string `toml:"min-version"`
+}
+
+// Middlewares configuration.
+type Middlewares struct {
+	// Middlewares, indexed by name.
+	Define map[string]Middleware
+	// Names of the middlewares applied to the messages of each topic, in order, indexed by topic name.
+	// The chain of "*" applies to every topic, before its own chain.
+	Topics map[string][]string
+}
+
+// Chains returns the chains of middlewares of the topics.
+func (m *Middlewares) Chains() (map[string][]lobby.Middleware, error) {
+	defined := make(map[string]lobby.Middleware, len(m.Define))
+	for name, cfg := range m.Define {
+		mw, err := middleware.New(middleware.Config(cfg))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid middleware '%s'", name)
+		}
+
+		defined[name] = mw
+	}
+
+	chains := make(map[string][]lobby.Middleware, len(m.Topics))
+	for topic, names := range m.Topics {
+		for _, name := range names {
+			mw, ok := defined[name]
+			if !ok {
+				return nil, errors.Errorf("unknown middleware '%s' in the chain of topic '%s'", name, topic)
+			}
+
+			chains[topic] = append(chains[topic], mw)
+		}
+	}
+
+	return chains, nil
+}
+
+// Middleware configuration.
+type Middleware struct {
+	// Type of the middleware: "timestamp", "host", "max-size" or "rewrite".
+	Type string
+	// Header set by the timestamp and host middlewares.
+	Header string
+	// Maximum size of the values, in bytes, used by the max-size middleware.
+	Size int
+	// Regular expression replaced in the values by the rewrite middleware.
+	Pattern string
+	// Replacement of the rewrite middleware, which can refer to submatches like $1.
+	Replacement string
+}
+
+// Limit is a token bucket limit: rate requests, or messages for topics, per second, with bursts of up to burst.
+// The burst defaults to the rate.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Duration is a time.Duration decoded from strings like "5s" or "1m30s".
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalTOML parses the duration. It is used instead of encoding.TextUnmarshaler
+// which the toml package ignores for map values.
+func (d *Duration) UnmarshalTOML(v interface{}) error {
+	text, ok := v.(string)
+	if !ok {
+		return errors.Errorf("invalid duration %v", v)
+	}
+
+	var err error
+	d.Duration, err = time.ParseDuration(text)
+	return err
 }
 
 // Paths contains directory paths needed by the app.
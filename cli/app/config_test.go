@@ -5,7 +5,12 @@ import (
 	"os"
 	"path"
 	"testing"
+	"time"
 
+	"github.com/BurntSushi/toml"
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/outbox"
+	"github.com/asdine/lobby/trace"
 	"github.com/stretchr/testify/require"
 )
 
@@ -80,3 +85,155 @@ func TestPaths(t *testing.T) {
 		})
 	})
 }
+
+func TestPluginTimeouts(t *testing.T) {
+	var cfg Config
+
+	_, err := toml.Decode(`
+[plugins.timeouts]
+mongo = "5s"
+redis = "1m30s"
+`, &cfg)
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Second, cfg.Plugins.Timeouts["mongo"].Duration)
+	require.Equal(t, 90*time.Second, cfg.Plugins.Timeouts["redis"].Duration)
+
+	_, err = toml.Decode(`
+[plugins.timeouts]
+mongo = "five seconds"
+`, &cfg)
+	require.Error(t, err)
+}
+
+func TestOutboxConfig(t *testing.T) {
+	var cfg Config
+
+	_, err := toml.Decode(`
+[plugins.outbox]
+backends = ["redis"]
+max-bytes = 1048576
+overflow = "drop-oldest"
+retry-interval = "2s"
+`, &cfg)
+	require.NoError(t, err)
+	require.True(t, cfg.Plugins.Outbox.Enabled("redis"))
+	require.False(t, cfg.Plugins.Outbox.Enabled("mongo"))
+	require.Equal(t, 2*time.Second, cfg.Plugins.Outbox.RetryInterval.Duration)
+
+	p, err := cfg.Plugins.Outbox.Policy()
+	require.NoError(t, err)
+	require.Equal(t, outbox.Policy{MaxMessages: outbox.DefaultMaxMessages, MaxBytes: 1048576, DropOldest: true}, p)
+
+	cfg.Plugins.Outbox.Overflow = "block"
+	_, err = cfg.Plugins.Outbox.Policy()
+	require.Error(t, err)
+}
+
+func TestMiddlewaresConfig(t *testing.T) {
+	var cfg Config
+
+	_, err := toml.Decode(`
+[middlewares.define.stamp]
+type = "timestamp"
+header = "received-at"
+
+[middlewares.define.small]
+type = "max-size"
+size = 1024
+
+[middlewares.topics]
+"*" = ["stamp"]
+quotes = ["small", "stamp"]
+`, &cfg)
+	require.NoError(t, err)
+
+	chains, err := cfg.Middlewares.Chains()
+	require.NoError(t, err)
+	require.Len(t, chains["*"], 1)
+	require.Len(t, chains["quotes"], 2)
+
+	cfg.Middlewares.Topics["logs"] = []string{"unknown"}
+	_, err = cfg.Middlewares.Chains()
+	require.Error(t, err)
+
+	cfg.Middlewares.Topics = nil
+	cfg.Middlewares.Define["small"] = Middleware{Type: "max-size"}
+	_, err = cfg.Middlewares.Chains()
+	require.Error(t, err)
+}
+
+func TestBoltRetention(t *testing.T) {
+	var cfg Config
+
+	_, err := toml.Decode(`
+[bolt]
+compaction-interval = "30s"
+
+[bolt.retention]
+max-age = "168h"
+max-messages = 1000
+max-bytes = 1048576
+`, &cfg)
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Second, cfg.Bolt.CompactionInterval.Duration)
+	require.Equal(t, 168*time.Hour, cfg.Bolt.Retention.MaxAge.Duration)
+	require.EqualValues(t, 1000, cfg.Bolt.Retention.MaxMessages)
+	require.EqualValues(t, 1048576, cfg.Bolt.Retention.MaxBytes)
+}
+
+func TestTracingExporter(t *testing.T) {
+	logger := log.New(log.Output(ioutil.Discard))
+
+	var cfg Config
+	e, err := cfg.Tracing.NewExporter("lobby", logger)
+	require.NoError(t, err)
+	require.Nil(t, e)
+
+	dir, err := ioutil.TempDir("", "lobby")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = toml.Decode(`
+[tracing]
+exporter = "file"
+file = "`+path.Join(dir, "spans.json")+`"
+`, &cfg)
+	require.NoError(t, err)
+
+	e, err = cfg.Tracing.NewExporter("lobby", logger)
+	require.NoError(t, err)
+	require.IsType(t, new(trace.FileExporter), e)
+	require.NoError(t, e.Close())
+
+	cfg.Tracing = Tracing{Exporter: "otlp"}
+	e, err = cfg.Tracing.NewExporter("lobby", logger)
+	require.NoError(t, err)
+	require.IsType(t, new(trace.OTLPExporter), e)
+	require.NoError(t, e.Close())
+
+	cfg.Tracing = Tracing{Exporter: "file"}
+	_, err = cfg.Tracing.NewExporter("lobby", logger)
+	require.Error(t, err)
+
+	cfg.Tracing = Tracing{Exporter: "zipkin"}
+	_, err = cfg.Tracing.NewExporter("lobby", logger)
+	require.Error(t, err)
+}
+
+func TestRateLimitConfig(t *testing.T) {
+	var cfg Config
+
+	_, err := toml.Decode(`
+[rate-limit]
+client = { rate = 100.0, burst = 200 }
+topic = { rate = 1000.0 }
+
+[rate-limit.topics.quotes]
+rate = 10.0
+burst = 20
+`, &cfg)
+	require.NoError(t, err)
+	require.Equal(t, Limit{Rate: 100, Burst: 200}, cfg.RateLimit.Client)
+	require.Equal(t, Limit{Rate: 1000}, cfg.RateLimit.Topic)
+	require.Equal(t, map[string]Limit{"quotes": {Rate: 10, Burst: 20}}, cfg.RateLimit.Topics)
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/health"
+)
+
+type healthStep int
+
+func (healthStep) setup(ctx context.Context, app *App) error {
+	interval := app.Config.Health.Interval.Duration
+	if interval == 0 {
+		interval = health.DefaultInterval
+	}
+
+	timeout := app.Config.Health.Timeout.Duration
+	if timeout == 0 {
+		timeout = health.DefaultTimeout
+	}
+
+	app.health = health.NewMonitor(
+		interval,
+		timeout,
+		newLogger(app, "health:"),
+	)
+	return nil
+}
+
+func (healthStep) teardown(ctx context.Context, app *App) error {
+	if app.health != nil {
+		err := app.health.Close()
+		app.health = nil
+		return err
+	}
+
+	return nil
+}
+
+// monitorBackend checks the health of the backend periodically, if it is able to report it.
+func monitorBackend(app *App, name string, bck lobby.Backend) {
+	if app.health == nil {
+		return
+	}
+
+	if c, ok := bck.(lobby.HealthChecker); ok {
+		app.health.Register(name+" backend", c)
+	}
+}
+
+// healthChecker returns the health monitor of the app, or nil if there is none.
+func healthChecker(app *App) lobby.HealthChecker {
+	if app.health == nil {
+		return nil
+	}
+
+	return app.health
+}
@@ -7,19 +7,22 @@ import (
 	"time"
 
 	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/outbox"
 	"github.com/asdine/lobby/rpc"
 	"github.com/pkg/errors"
 )
 
 func newBackendPluginsStep() *backendPluginsStep {
 	return &backendPluginsStep{
-		pluginLoader: rpc.LoadBackendPlugin,
+		pluginLoader: rpc.SuperviseBackendPlugin,
 	}
 }
 
 type backendPluginsStep struct {
-	pluginLoader func(context.Context, string, string, string, string) (lobby.Backend, lobby.Plugin, error)
+	pluginLoader func(context.Context, string, string, string, string, time.Duration, rpc.RestartPolicy, *log.Logger) (lobby.Backend, lobby.Plugin, error)
 	plugins      []lobby.Plugin
+	outbox       *outbox.Outbox
 }
 
 func (s *backendPluginsStep) setup(ctx context.Context, app *App) error {
@@ -27,52 +30,181 @@ func (s *backendPluginsStep) setup(ctx context.Context, app *App) error {
 		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 		defer cancel()
 
+		timeout := rpc.DefaultTimeout
+		if d, ok := app.Config.Plugins.Timeouts[name]; ok {
+			timeout = d.Duration
+		}
+
 		bck, plg, err := s.pluginLoader(
 			ctx,
 			name,
 			path.Join(app.Config.Paths.PluginDir, fmt.Sprintf("lobby-%s", name)),
 			app.Config.Paths.DataDir,
 			app.ConfigPath,
+			timeout,
+			restartPolicy(app),
+			app.Logger,
 		)
 		if err != nil {
 			return errors.Wrapf(err, "failed to run backend '%s'", name)
 		}
 
 		app.Logger.Debugf("Started %s plugin \n", name)
-		app.registry.RegisterBackend(name, bck)
 		s.plugins = append(s.plugins, plg)
+		watchPlugin(app, plg)
+		monitorBackend(app, name, bck)
 
-		app.wg.Add(1)
-		go func(p lobby.Plugin) {
-			defer app.wg.Done()
-
-			err := p.Wait()
+		if app.Config.Plugins.Outbox.Enabled(name) {
+			bck, err = s.withOutbox(app, name, bck)
 			if err != nil {
-				app.Logger.Println(err)
-				app.errc <- err
+				return err
 			}
-		}(plg)
+		}
+
+		app.registry.RegisterBackend(name, bck)
 	}
 
 	return nil
 }
 
+// withOutbox returns a backend storing the messages in the outbox when the plugin is unavailable.
+// The outbox is opened the first time it is used.
+func (s *backendPluginsStep) withOutbox(app *App, name string, bck lobby.Backend) (lobby.Backend, error) {
+	if s.outbox == nil {
+		cfg := app.Config.Plugins.Outbox
+
+		policy, err := cfg.Policy()
+		if err != nil {
+			return nil, err
+		}
+
+		opts := []func(*outbox.Outbox){
+			outbox.WithPolicy(policy),
+			outbox.Transient(rpc.IsTransient),
+			outbox.Logger(newLogger(app, "outbox:")),
+		}
+
+		if cfg.RetryInterval.Duration > 0 {
+			opts = append(opts, outbox.RetryInterval(cfg.RetryInterval.Duration))
+		}
+
+		s.outbox, err = outbox.Open(path.Join(app.Config.Paths.DataDir, "outbox.db"), opts...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	app.Logger.Debugf("Enabled outbox of %s backend\n", name)
+	return s.outbox.Wrap(name, bck)
+}
+
 func (s *backendPluginsStep) teardown(ctx context.Context, app *App) error {
-	for _, p := range s.plugins {
+	closePlugins(app, s.plugins)
+
+	if s.outbox != nil {
+		err := s.outbox.Close()
+		s.outbox = nil
+		return err
+	}
+
+	return nil
+}
+
+// restartPolicy returns the restart policy of the backend plugins defined in the config.
+func restartPolicy(app *App) rpc.RestartPolicy {
+	cfg := app.Config.Plugins.Restart
+	policy := rpc.DefaultRestartPolicy
+
+	switch {
+	case cfg.MaxRestarts < 0:
+		policy.MaxRestarts = 0
+	case cfg.MaxRestarts > 0:
+		policy.MaxRestarts = cfg.MaxRestarts
+	}
+
+	if cfg.Window.Duration > 0 {
+		policy.Window = cfg.Window.Duration
+	}
+
+	if cfg.MinBackoff.Duration > 0 {
+		policy.MinBackoff = cfg.MinBackoff.Duration
+	}
+
+	if cfg.MaxBackoff.Duration > 0 {
+		policy.MaxBackoff = cfg.MaxBackoff.Duration
+	}
+
+	return policy
+}
+
+func newServerPluginsStep() *serverPluginsStep {
+	return &serverPluginsStep{
+		pluginLoader: rpc.LoadServerPlugin,
+	}
+}
+
+type serverPluginsStep struct {
+	pluginLoader func(context.Context, string, string, string, string) (lobby.Plugin, error)
+	plugins      []lobby.Plugin
+}
+
+func (s *serverPluginsStep) setup(ctx context.Context, app *App) error {
+	for _, name := range app.Config.Plugins.Servers {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		plg, err := s.pluginLoader(
+			ctx,
+			name,
+			path.Join(app.Config.Paths.PluginDir, fmt.Sprintf("lobby-%s-server", name)),
+			app.Config.Paths.DataDir,
+			app.ConfigPath,
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to run server '%s'", name)
+		}
+
+		app.Logger.Debugf("Started %s plugin \n", name)
+		s.plugins = append(s.plugins, plg)
+		watchPlugin(app, plg)
+	}
+
+	return nil
+}
+
+func (s *serverPluginsStep) teardown(ctx context.Context, app *App) error {
+	closePlugins(app, s.plugins)
+	return nil
+}
+
+// watchPlugin reports an error to the app if the plugin exits unexpectedly.
+func watchPlugin(app *App, p lobby.Plugin) {
+	app.wg.Add(1)
+	go func() {
+		defer app.wg.Done()
+
+		err := p.Wait()
+		if err != nil {
+			app.Logger.Error(err)
+			app.errc <- err
+		}
+	}()
+}
+
+func closePlugins(app *App, plugins []lobby.Plugin) {
+	for _, p := range plugins {
 		err := p.Close()
 		if err != nil {
-			app.Logger.Printf("Error while closing plugin %s: %s\n", p.Name(), err)
+			app.Logger.Errorf("Error while closing plugin %s: %s\n", p.Name(), err)
 			app.errc <- err
 		}
 
 		err = p.Wait()
 		if err != nil {
-			app.Logger.Printf("Error while waiting for plugin %s to close: %s\n", p.Name(), err)
+			app.Logger.Errorf("Error while waiting for plugin %s to close: %s\n", p.Name(), err)
 			app.errc <- err
 		}
 
 		app.Logger.Debugf("Stopped %s plugin\n", p.Name())
 	}
-
-	return nil
 }
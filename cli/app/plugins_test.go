@@ -4,10 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path"
 	"testing"
+	"time"
 
 	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
 	"github.com/asdine/lobby/mock"
+	"github.com/asdine/lobby/outbox"
+	"github.com/asdine/lobby/rpc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -29,7 +34,7 @@ func TestBackendPluginsSteps(t *testing.T) {
 
 		s := newBackendPluginsStep()
 		var i int
-		s.pluginLoader = func(ctx context.Context, name, cmdPath, dataDir, configFile string) (lobby.Backend, lobby.Plugin, error) {
+		s.pluginLoader = func(ctx context.Context, name, cmdPath, dataDir, configFile string, timeout time.Duration, policy rpc.RestartPolicy, logger *log.Logger) (lobby.Backend, lobby.Plugin, error) {
 			i++
 			if i == 3 {
 				return nil, nil, errors.New("unexpected error")
@@ -65,7 +70,7 @@ func TestBackendPluginsSteps(t *testing.T) {
 		}
 
 		s := newBackendPluginsStep()
-		s.pluginLoader = func(ctx context.Context, name, cmdPath, dataDir, configFile string) (lobby.Backend, lobby.Plugin, error) {
+		s.pluginLoader = func(ctx context.Context, name, cmdPath, dataDir, configFile string, timeout time.Duration, policy rpc.RestartPolicy, logger *log.Logger) (lobby.Backend, lobby.Plugin, error) {
 			return new(mock.Backend), new(mock.Plugin), nil
 		}
 
@@ -108,13 +113,26 @@ func TestBackendPluginsSteps(t *testing.T) {
 		for i := 0; i < 5; i++ {
 			app.Config.Plugins.Backends[i] = fmt.Sprintf("plugin%d", i)
 		}
+		app.Config.Plugins.Timeouts = map[string]Duration{
+			"plugin1": {time.Second},
+		}
+		app.Config.Plugins.Restart.MaxRestarts = 2
+		app.Config.Plugins.Restart.MaxBackoff = Duration{time.Second}
 
 		s := newBackendPluginsStep()
 		var i int
-		s.pluginLoader = func(ctx context.Context, name, cmdPath, dataDir, configFile string) (lobby.Backend, lobby.Plugin, error) {
+		s.pluginLoader = func(ctx context.Context, name, cmdPath, dataDir, configFile string, timeout time.Duration, policy rpc.RestartPolicy, logger *log.Logger) (lobby.Backend, lobby.Plugin, error) {
 			require.Equal(t, fmt.Sprintf("plugin%d", i), name)
 			require.Equal(t, fmt.Sprintf("pluginDir/lobby-plugin%d", i), cmdPath)
 			require.Equal(t, "dataDir", dataDir)
+			if i == 1 {
+				require.Equal(t, time.Second, timeout)
+			} else {
+				require.Equal(t, rpc.DefaultTimeout, timeout)
+			}
+			require.Equal(t, 2, policy.MaxRestarts)
+			require.Equal(t, rpc.DefaultRestartPolicy.Window, policy.Window)
+			require.Equal(t, time.Second, policy.MaxBackoff)
 			i++
 			return new(mock.Backend), new(mock.Plugin), nil
 		}
@@ -130,4 +148,131 @@ func TestBackendPluginsSteps(t *testing.T) {
 			require.Equal(t, 1, p.(*mock.Plugin).CloseInvoked)
 		}
 	})
+
+	t.Run("Health", func(t *testing.T) {
+		app, cleanup := appHelper(t)
+		defer cleanup()
+
+		err := new(healthStep).setup(context.Background(), app)
+		require.NoError(t, err)
+		defer new(healthStep).teardown(context.Background(), app)
+
+		app.Config.Plugins.Backends = []string{"mongo", "nsq"}
+		app.registry = new(mock.Registry)
+
+		s := newBackendPluginsStep()
+		s.pluginLoader = func(ctx context.Context, name, cmdPath, dataDir, configFile string, timeout time.Duration, policy rpc.RestartPolicy, logger *log.Logger) (lobby.Backend, lobby.Plugin, error) {
+			if name == "mongo" {
+				return &struct {
+					mock.Backend
+					mock.HealthChecker
+				}{}, new(mock.Plugin), nil
+			}
+
+			return new(mock.Backend), new(mock.Plugin), nil
+		}
+
+		err = s.setup(context.Background(), app)
+		require.NoError(t, err)
+		defer s.teardown(context.Background(), app)
+
+		statuses := app.health.Statuses()
+		require.Len(t, statuses, 1)
+		require.Contains(t, statuses, "mongo backend")
+	})
+
+	t.Run("Outbox", func(t *testing.T) {
+		app, cleanup := appHelper(t)
+		defer cleanup()
+
+		app.Config.Plugins.Backends = []string{"mongo", "redis"}
+		app.Config.Plugins.Outbox.Backends = []string{"redis"}
+		var m mock.Registry
+		app.registry = &m
+
+		s := newBackendPluginsStep()
+		s.pluginLoader = func(ctx context.Context, name, cmdPath, dataDir, configFile string, timeout time.Duration, policy rpc.RestartPolicy, logger *log.Logger) (lobby.Backend, lobby.Plugin, error) {
+			return new(mock.Backend), new(mock.Plugin), nil
+		}
+
+		err := s.setup(context.Background(), app)
+		require.NoError(t, err)
+		require.Len(t, m.Backends, 2)
+		require.IsType(t, new(mock.Backend), m.Backends["mongo"])
+		require.IsType(t, new(outbox.Backend), m.Backends["redis"])
+		require.FileExists(t, path.Join(app.Config.Paths.DataDir, "outbox.db"))
+
+		err = s.teardown(context.Background(), app)
+		require.NoError(t, err)
+		require.Nil(t, s.outbox)
+	})
+}
+
+func TestServerPluginsSteps(t *testing.T) {
+	t.Run("ErrorsDuringSetup", func(t *testing.T) {
+		app, cleanup := appHelper(t)
+		defer cleanup()
+
+		app.Config.Paths.DataDir = "dataDir"
+		app.Config.Paths.PluginDir = "pluginDir"
+		app.Config.Plugins.Servers = make([]string, 5)
+
+		for i := 0; i < 5; i++ {
+			app.Config.Plugins.Servers[i] = fmt.Sprintf("plugin%d", i)
+		}
+
+		s := newServerPluginsStep()
+		var i int
+		s.pluginLoader = func(ctx context.Context, name, cmdPath, dataDir, configFile string) (lobby.Plugin, error) {
+			i++
+			if i == 3 {
+				return nil, errors.New("unexpected error")
+			}
+
+			return new(mock.Plugin), nil
+		}
+
+		err := s.setup(context.Background(), app)
+		require.Error(t, err)
+		require.Len(t, s.plugins, 2)
+
+		err = s.teardown(context.Background(), app)
+		require.NoError(t, err)
+		for _, p := range s.plugins {
+			require.Equal(t, 1, p.(*mock.Plugin).CloseInvoked)
+		}
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		app, cleanup := appHelper(t)
+		defer cleanup()
+
+		app.Config.Paths.DataDir = "dataDir"
+		app.Config.Paths.PluginDir = "pluginDir"
+		app.Config.Plugins.Servers = make([]string, 5)
+
+		for i := 0; i < 5; i++ {
+			app.Config.Plugins.Servers[i] = fmt.Sprintf("plugin%d", i)
+		}
+
+		s := newServerPluginsStep()
+		var i int
+		s.pluginLoader = func(ctx context.Context, name, cmdPath, dataDir, configFile string) (lobby.Plugin, error) {
+			require.Equal(t, fmt.Sprintf("plugin%d", i), name)
+			require.Equal(t, fmt.Sprintf("pluginDir/lobby-plugin%d-server", i), cmdPath)
+			require.Equal(t, "dataDir", dataDir)
+			i++
+			return new(mock.Plugin), nil
+		}
+
+		err := s.setup(context.Background(), app)
+		require.NoError(t, err)
+		require.Len(t, s.plugins, 5)
+
+		err = s.teardown(context.Background(), app)
+		require.NoError(t, err)
+		for _, p := range s.plugins {
+			require.Equal(t, 1, p.(*mock.Plugin).CloseInvoked)
+		}
+	})
 }
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"context"
+
+	"github.com/asdine/lobby/ratelimit"
+)
+
+func rateLimitStep() step {
+	return setupFunc(func(ctx context.Context, app *App) error {
+		cfg := app.Config.RateLimit
+
+		enabled := cfg.Client.Rate > 0 || cfg.Topic.Rate > 0
+		topics := make(map[string]ratelimit.Limit, len(cfg.Topics))
+		for name, l := range cfg.Topics {
+			topics[name] = ratelimit.Limit(l)
+			enabled = enabled || l.Rate > 0
+		}
+
+		if !enabled {
+			app.Logger.Debug("Rate limiting disabled")
+			return nil
+		}
+
+		app.limiter = ratelimit.New(ratelimit.Limit(cfg.Client), ratelimit.Limit(cfg.Topic), topics)
+		return nil
+	})
+}
@@ -7,7 +7,11 @@ import (
 	"github.com/asdine/lobby"
 	"github.com/asdine/lobby/bolt"
 	"github.com/asdine/lobby/etcd"
-	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/middleware"
+	"github.com/asdine/lobby/mirror"
+	"github.com/asdine/lobby/pubsub"
+	"github.com/asdine/lobby/schema"
+	"github.com/asdine/lobby/telemetry"
 	"github.com/coreos/etcd/clientv3"
 	"github.com/pkg/errors"
 )
@@ -15,8 +19,12 @@ import (
 type registryStep int
 
 func (registryStep) setup(ctx context.Context, app *App) error {
+	chains, err := app.Config.Middlewares.Chains()
+	if err != nil {
+		return err
+	}
+
 	var reg lobby.Registry
-	var err error
 	switch app.Config.Registry {
 	case "":
 		fallthrough
@@ -33,7 +41,17 @@ func (registryStep) setup(ctx context.Context, app *App) error {
 		return err
 	}
 
-	app.registry = reg
+	// schemas are checked once per message, before it is sent to the mirrors.
+	reg = mirror.NewRegistry(schema.NewRegistry(reg), newLogger(app, "mirror:"))
+	if len(chains) > 0 {
+		reg = middleware.NewRegistry(reg, chains)
+	}
+
+	app.registry = pubsub.NewRegistry(
+		telemetry.NewRegistry(reg),
+		pubsub.DefaultBufferSize,
+		newLogger(app, "pubsub:"),
+	)
 	return nil
 }
 
@@ -52,7 +70,7 @@ func boltRegistry(ctx context.Context, app *App) (lobby.Registry, error) {
 
 	registryPath := path.Join(boltPath, "registry.db")
 
-	return bolt.NewRegistry(registryPath, log.New(log.Prefix("bolt registry:"), log.Debug(app.Config.Debug)))
+	return bolt.NewRegistry(registryPath, newLogger(app, "bolt registry:"))
 }
 
 func etcdRegistry(ctx context.Context, app *App) (lobby.Registry, error) {
@@ -63,7 +81,7 @@ func etcdRegistry(ctx context.Context, app *App) (lobby.Registry, error) {
 
 	return etcd.NewRegistry(
 		client,
-		log.New(log.Prefix("etcd registry:"), log.Debug(app.Config.Debug)),
+		newLogger(app, "etcd registry:"),
 		"lobby",
 	)
 }
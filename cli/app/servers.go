@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"path"
@@ -10,11 +11,14 @@ import (
 	"github.com/asdine/lobby/http"
 	"github.com/asdine/lobby/log"
 	"github.com/asdine/lobby/rpc"
+	"google.golang.org/grpc"
 )
 
 type serverStep struct {
 	logger *log.Logger
 	srv    lobby.Server
+	// reloads the certificate of the server, if TLS is enabled.
+	reloader *certReloader
 }
 
 func (s *serverStep) runServer(srv lobby.Server, l net.Listener, app *App) error {
@@ -29,7 +33,7 @@ func (s *serverStep) runServer(srv lobby.Server, l net.Listener, app *App) error
 		close(c)
 		err := srv.Serve(l)
 		if err != nil {
-			s.logger.Println(err)
+			s.logger.Error(err)
 			app.errc <- err
 		}
 	}()
@@ -40,6 +44,12 @@ func (s *serverStep) runServer(srv lobby.Server, l net.Listener, app *App) error
 
 func (s *serverStep) teardown(ctx context.Context, app *App) error {
 	s.logger.Debugf("Shutting down")
+
+	if s.reloader != nil {
+		s.reloader.Close()
+		s.reloader = nil
+	}
+
 	if s.srv != nil {
 		err := s.srv.Stop()
 		s.srv = nil
@@ -52,11 +62,7 @@ func (s *serverStep) teardown(ctx context.Context, app *App) error {
 func newGRPCUnixSocketStep(app *App) *gRPCUnixSocketStep {
 	return &gRPCUnixSocketStep{
 		serverStep: &serverStep{
-			logger: log.New(
-				log.Prefix("gRPC server:"),
-				log.Output(app.out),
-				log.Debug(app.Config.Debug),
-			),
+			logger: newLogger(app, "gRPC server:"),
 		},
 	}
 }
@@ -71,10 +77,13 @@ func (g *gRPCUnixSocketStep) setup(ctx context.Context, app *App) error {
 		return err
 	}
 
+	// the socket is only reachable locally, it is used by the server plugins
+	// which are neither authenticated nor rate limited.
 	srv := rpc.NewServer(
 		g.serverStep.logger,
-		rpc.WithTopicService(app.registry),
-		rpc.WithRegistryService(app.registry),
+		rpc.WithRegistryTopicService(app.registry, nil),
+		rpc.WithRegistryService(app.registry, nil),
+		rpc.WithHealthService(healthChecker(app)),
 	)
 	return g.runServer(srv, l, app)
 }
@@ -82,11 +91,7 @@ func (g *gRPCUnixSocketStep) setup(ctx context.Context, app *App) error {
 func newGRPCPortStep(app *App) *gRPCPortStep {
 	return &gRPCPortStep{
 		serverStep: &serverStep{
-			logger: log.New(
-				log.Prefix("gRPC server:"),
-				log.Output(app.out),
-				log.Debug(app.Config.Debug),
-			),
+			logger: newLogger(app, "gRPC server:"),
 		},
 	}
 }
@@ -96,27 +101,36 @@ type gRPCPortStep struct {
 }
 
 func (g *gRPCPortStep) setup(ctx context.Context, app *App) error {
+	cfg, reloader, err := tlsConfig(&app.Config.Grpc.TLS, g.serverStep.logger)
+	if err != nil {
+		return err
+	}
+	g.reloader = reloader
+
 	l, err := net.Listen("tcp", fmt.Sprintf(":%d", app.Config.Grpc.Port))
 	if err != nil {
 		return err
 	}
 
-	srv := rpc.NewServer(
-		g.serverStep.logger,
-		rpc.WithTopicService(app.registry),
-		rpc.WithRegistryService(app.registry),
-	)
+	services := []func(*grpc.Server, *log.Logger){
+		rpc.WithRegistryTopicService(app.registry, authenticator(app)),
+		rpc.WithRegistryService(app.registry, authenticator(app)),
+		rpc.WithHealthService(healthChecker(app)),
+	}
+
+	srv := rpc.NewServerWithConfig(g.serverStep.logger, rpc.ServerConfig{
+		TLS:     cfg,
+		Limiter: app.limiter,
+		Auth:    authenticator(app),
+	}, services...)
+
 	return g.runServer(srv, l, app)
 }
 
 func newHTTPStep(app *App) *httpStep {
 	return &httpStep{
 		serverStep: &serverStep{
-			logger: log.New(
-				log.Prefix("http server:"),
-				log.Output(app.out),
-				log.Debug(app.Config.Debug),
-			),
+			logger: newLogger(app, "http server:"),
 		},
 	}
 }
@@ -126,13 +140,23 @@ type httpStep struct {
 }
 
 func (h *httpStep) setup(ctx context.Context, app *App) error {
+	cfg, reloader, err := tlsConfig(&app.Config.HTTP.TLS, h.serverStep.logger)
+	if err != nil {
+		return err
+	}
+	h.reloader = reloader
+
 	l, err := net.Listen("tcp", fmt.Sprintf(":%d", app.Config.HTTP.Port))
 	if err != nil {
 		return err
 	}
 
+	if cfg != nil {
+		l = tls.NewListener(l, cfg)
+	}
+
 	srv := http.NewServer(
-		http.NewHandler(app.registry, h.logger),
+		http.NewHandler(app.registry, app.health, authenticator(app), app.limiter, h.logger),
 	)
 	return h.runServer(srv, l, app)
 }
@@ -0,0 +1,201 @@
+package app
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/asdine/lobby/log"
+	"github.com/pkg/errors"
+)
+
+// Period at which certificate files are checked for changes.
+const certReloadInterval = 10 * time.Second
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsConfig returns the TLS configuration of a server and the reloader of its certificate.
+// It returns nil if TLS is disabled.
+func tlsConfig(cfg *TLS, logger *log.Logger) (*tls.Config, *certReloader, error) {
+	if cfg.CertFile == "" && cfg.KeyFile == "" {
+		return nil, nil, nil
+	}
+
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, nil, errors.New("both a certificate and a key file are required to enable TLS")
+	}
+
+	minVersion := uint16(tls.VersionTLS12)
+	if cfg.MinVersion != "" {
+		v, ok := tlsVersions[cfg.MinVersion]
+		if !ok {
+			return nil, nil, errors.Errorf("unsupported TLS version '%s'", cfg.MinVersion)
+		}
+		minVersion = v
+	}
+
+	reloader, err := newCertReloader(cfg.CertFile, cfg.KeyFile, certReloadInterval, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := tls.Config{
+		MinVersion:     minVersion,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			reloader.Close()
+			return nil, nil, errors.Wrapf(err, "failed to read client CA file %s", cfg.ClientCAFile)
+		}
+
+		c.ClientCAs = x509.NewCertPool()
+		if !c.ClientCAs.AppendCertsFromPEM(pem) {
+			reloader.Close()
+			return nil, nil, errors.Errorf("no certificate found in client CA file %s", cfg.ClientCAFile)
+		}
+
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return &c, reloader, nil
+}
+
+// newCertReloader loads a certificate and reloads it every time its files change.
+func newCertReloader(certFile, keyFile string, interval time.Duration, logger *log.Logger) (*certReloader, error) {
+	r := certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   logger,
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	err := r.reload()
+	if err != nil {
+		return nil, err
+	}
+
+	go r.run(interval)
+
+	return &r, nil
+}
+
+// certReloader serves the last valid version of a certificate.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	logger   *log.Logger
+
+	m    sync.RWMutex
+	cert *tls.Certificate
+	// modification time of the files of the loaded certificate.
+	modTimes [2]time.Time
+
+	closeOnce sync.Once
+	quit      chan struct{}
+	done      chan struct{}
+}
+
+// GetCertificate returns the current certificate. It is meant to be used as tls.Config.GetCertificate.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.m.RLock()
+	defer r.m.RUnlock()
+
+	return r.cert, nil
+}
+
+// Close stops watching the certificate files.
+func (r *certReloader) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.quit)
+	})
+	<-r.done
+
+	return nil
+}
+
+func (r *certReloader) run(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.quit:
+			return
+		case <-ticker.C:
+			changed, err := r.changed()
+			if err != nil || !changed {
+				continue
+			}
+
+			// the previous certificate is kept if the new one is invalid, e.g. if only one of the files was replaced yet.
+			err = r.reload()
+			if err != nil {
+				r.logger.Errorf("Failed to reload certificate %s: %s\n", r.certFile, err)
+				continue
+			}
+
+			r.logger.Printf("Reloaded certificate %s\n", r.certFile)
+		}
+	}
+}
+
+// changed returns true if one of the certificate files was modified since the last reload.
+func (r *certReloader) changed() (bool, error) {
+	modTimes, err := r.stat()
+	if err != nil {
+		return false, err
+	}
+
+	r.m.RLock()
+	defer r.m.RUnlock()
+
+	return modTimes != r.modTimes, nil
+}
+
+func (r *certReloader) stat() ([2]time.Time, error) {
+	var modTimes [2]time.Time
+
+	for i, name := range []string{r.certFile, r.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return modTimes, err
+		}
+
+		modTimes[i] = fi.ModTime()
+	}
+
+	return modTimes, nil
+}
+
+func (r *certReloader) reload() error {
+	modTimes, err := r.stat()
+	if err != nil {
+		return errors.Wrap(err, "failed to read certificate")
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to load certificate")
+	}
+
+	r.m.Lock()
+	r.cert = &cert
+	r.modTimes = modTimes
+	r.m.Unlock()
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/asdine/lobby/log"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCertificate generates a self-signed certificate and writes it to dir, along with its key.
+func writeCertificate(t *testing.T, dir, cn string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = path.Join(dir, cn+".crt")
+	keyFile = path.Join(dir, cn+".key")
+
+	err = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	require.NoError(t, err)
+
+	return certFile, keyFile
+}
+
+func TestTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lobby")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	logger := log.New(log.Output(ioutil.Discard))
+	certFile, keyFile := writeCertificate(t, dir, "lobby")
+	caFile, _ := writeCertificate(t, dir, "ca")
+
+	t.Run("Disabled", func(t *testing.T) {
+		cfg, reloader, err := tlsConfig(new(TLS), logger)
+		require.NoError(t, err)
+		require.Nil(t, cfg)
+		require.Nil(t, reloader)
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		_, _, err := tlsConfig(&TLS{CertFile: certFile}, logger)
+		require.Error(t, err)
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		_, _, err := tlsConfig(&TLS{CertFile: certFile, KeyFile: keyFile, MinVersion: "2.0"}, logger)
+		require.EqualError(t, err, "unsupported TLS version '2.0'")
+	})
+
+	t.Run("InvalidClientCA", func(t *testing.T) {
+		_, _, err := tlsConfig(&TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile}, logger)
+		require.Error(t, err)
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		cfg, reloader, err := tlsConfig(&TLS{CertFile: certFile, KeyFile: keyFile}, logger)
+		require.NoError(t, err)
+		defer reloader.Close()
+
+		require.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+		require.Equal(t, tls.NoClientCert, cfg.ClientAuth)
+		cert, err := cfg.GetCertificate(nil)
+		require.NoError(t, err)
+		require.NotNil(t, cert)
+	})
+
+	t.Run("MutualTLS", func(t *testing.T) {
+		cfg, reloader, err := tlsConfig(&TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, MinVersion: "1.3"}, logger)
+		require.NoError(t, err)
+		defer reloader.Close()
+
+		require.Equal(t, uint16(tls.VersionTLS13), cfg.MinVersion)
+		require.Equal(t, tls.RequireAndVerifyClientCert, cfg.ClientAuth)
+		require.NotNil(t, cfg.ClientCAs)
+	})
+}
+
+func TestCertReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lobby")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeCertificate(t, dir, "lobby")
+
+	r, err := newCertReloader(certFile, keyFile, time.Millisecond, log.New(log.Output(ioutil.Discard)))
+	require.NoError(t, err)
+	defer r.Close()
+
+	first, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+
+	// replacing the certificate with a new one, with a different modification time.
+	newCert, newKey := writeCertificate(t, dir, "new")
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Rename(newCert, certFile))
+	require.NoError(t, os.Rename(newKey, keyFile))
+	require.NoError(t, os.Chtimes(certFile, future, future))
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		cert, err := r.GetCertificate(nil)
+		require.NoError(t, err)
+		if cert != first {
+			leaf, err := x509.ParseCertificate(cert.Certificate[0])
+			require.NoError(t, err)
+			require.Equal(t, "new", leaf.Subject.CommonName)
+			break
+		}
+
+		require.True(t, time.Now().Before(deadline), "certificate wasn't reloaded")
+		time.Sleep(time.Millisecond)
+	}
+
+	// invalid certificates are ignored.
+	require.NoError(t, ioutil.WriteFile(certFile, []byte("invalid"), 0600))
+	require.NoError(t, os.Chtimes(certFile, future.Add(time.Minute), future.Add(time.Minute)))
+	time.Sleep(10 * time.Millisecond)
+
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	require.NotNil(t, cert)
+}
+
+func TestServersStepsTLS(t *testing.T) {
+	app, cleanup := appHelper(t)
+	defer cleanup()
+
+	certFile, keyFile := writeCertificate(t, app.Config.Paths.DataDir, "lobby")
+	app.Config.HTTP.TLS = TLS{CertFile: certFile, KeyFile: keyFile}
+	app.Config.Grpc.TLS = TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile}
+
+	testCases := []step{
+		newHTTPStep(app),
+		newGRPCPortStep(app),
+	}
+
+	for _, s := range testCases {
+		err := s.setup(context.Background(), app)
+		require.NoError(t, err)
+	}
+
+	// bug when calling stop right after serve on http.
+	time.Sleep(10 * time.Millisecond)
+
+	for _, s := range testCases {
+		err := s.teardown(context.Background(), app)
+		require.NoError(t, err)
+	}
+
+	app.wg.Wait()
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+
+	"github.com/asdine/lobby/trace"
+)
+
+type tracingStep struct {
+	exporter trace.Exporter
+}
+
+func (t *tracingStep) setup(ctx context.Context, app *App) error {
+	exporter, err := app.Config.Tracing.NewExporter(
+		"lobby",
+		newLogger(app, "trace:"),
+	)
+	if err != nil {
+		return err
+	}
+
+	if exporter == nil {
+		app.Logger.Debug("Tracing disabled")
+		return nil
+	}
+
+	t.exporter = exporter
+	trace.SetExporter(exporter)
+	return nil
+}
+
+func (t *tracingStep) teardown(ctx context.Context, app *App) error {
+	if t.exporter == nil {
+		return nil
+	}
+
+	trace.SetExporter(nil)
+	err := t.exporter.Close()
+	t.exporter = nil
+	return err
+}
@@ -13,6 +13,7 @@ func New() *cobra.Command {
 	var app app.App
 	cmd := newRootCmd(&app)
 	setCoreCmd(cmd.Command, &app)
+	cmd.AddCommand(newTopicCmd(&app))
 	return cmd.Command
 }
 
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	stdlog "log"
 	"net"
 	"os"
@@ -9,19 +10,92 @@ import (
 	"path"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/asdine/lobby"
 	cliapp "github.com/asdine/lobby/cli/app"
 	"github.com/asdine/lobby/log"
 	"github.com/asdine/lobby/rpc"
+	"github.com/asdine/lobby/trace"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
 )
 
-// RunBackend runs a plugin as a backend.
+// RunBackend runs a plugin as a backend. If the backend implements lobby.HealthChecker,
+// its health is reported to Lobby.
 func RunBackend(name string, fn func() (lobby.Backend, error), cfg interface{}) {
+	runPlugin(name, name, cfg, func(app *cliapp.App) (lobby.Server, io.Closer, error) {
+		bck, err := fn()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// backends that can't report their health are always considered healthy.
+		checker, _ := bck.(lobby.HealthChecker)
+
+		return rpc.NewServer(
+			log.New(log.Debug(app.Config.Debug), log.Format(app.Config.Log.Format)),
+			rpc.WithTopicService(bck),
+			rpc.WithHealthService(checker),
+		), bck, nil
+	})
+}
+
+// RunServer runs a plugin as a server. The given function receives a registry connected
+// to Lobby that can be used to manipulate topics. The returned server is given a listener
+// on the plugin socket.
+// The plugin executable must be named lobby-<name>-server.
+func RunServer(name string, fn func(lobby.Registry) (lobby.Server, error), cfg interface{}) {
+	runPlugin(name, fmt.Sprintf("%s-server", name), cfg, func(app *cliapp.App) (lobby.Server, io.Closer, error) {
+		reg, err := dialRegistry(app)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		srv, err := fn(reg)
+		if err != nil {
+			reg.Close()
+			return nil, nil, err
+		}
+
+		return srv, reg, nil
+	})
+}
+
+// dialRegistry returns a registry connected to Lobby through its local socket.
+func dialRegistry(app *cliapp.App) (*rpc.Registry, error) {
+	socketPath := path.Join(app.Config.Paths.SocketDir, "lobby.sock")
+
+	opts := append([]grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithTimeout(5 * time.Second),
+		grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout("unix", socketPath, timeout)
+		}),
+	}, rpc.DialOptions()...)
+
+	conn, err := grpc.Dial("", opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	reg, err := rpc.NewRegistry(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return reg, nil
+}
+
+// runPlugin decodes the plugin configuration, creates the server and serves it on the plugin socket
+// until the process receives a termination signal. The closer is closed once the server is stopped.
+// The id is used to name the command and the socket, the name to select the plugin configuration.
+func runPlugin(name, id string, cfg interface{}, fn func(*cliapp.App) (lobby.Server, io.Closer, error)) {
 	var app cliapp.App
 	root := newRootCmd(&app)
-	root.Use = fmt.Sprintf("lobby-%s", name)
+	root.Use = fmt.Sprintf("lobby-%s", id)
 	root.Short = fmt.Sprintf("%s plugin", name)
 	root.RunE = func(cmd *cobra.Command, args []string) error {
 		var wg sync.WaitGroup
@@ -40,24 +114,38 @@ func RunBackend(name string, fn func() (lobby.Backend, error), cfg interface{})
 			return err
 		}
 
-		bck, err := fn()
+		stdlog.SetFlags(0)
+
+		// the spans of the plugin are exported like the ones of Lobby, under their own service name.
+		exporter, err := app.Config.Tracing.NewExporter(fmt.Sprintf("lobby-%s", id), log.New(
+			log.Prefix("trace:"),
+			log.Debug(app.Config.Debug),
+			log.Format(app.Config.Log.Format),
+		))
 		if err != nil {
 			return err
 		}
-		defer bck.Close()
+
+		if exporter != nil {
+			trace.SetExporter(exporter)
+			defer exporter.Close()
+		}
+
+		srv, closer, err := fn(&app)
+		if err != nil {
+			return err
+		}
+		defer closer.Close()
 
 		ch := make(chan os.Signal, 1)
 		signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
 
-		l, err := net.Listen("unix", path.Join(app.Config.Paths.SocketDir, fmt.Sprintf("%s.sock", name)))
+		l, err := net.Listen("unix", path.Join(app.Config.Paths.SocketDir, fmt.Sprintf("%s.sock", id)))
 		if err != nil {
 			return err
 		}
 		defer l.Close()
 
-		stdlog.SetFlags(0)
-		srv := rpc.NewServer(log.New(), rpc.WithTopicService(bck))
-
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -41,6 +41,7 @@ func setCoreCmd(cmd *cobra.Command, app *app.App) {
 	}
 
 	cmd.Flags().StringSliceVar(&app.Config.Plugins.Backends, "backend", nil, "Name of the backend to use")
+	cmd.Flags().StringSliceVar(&app.Config.Plugins.Servers, "server", nil, "Name of the server to run")
 	cmd.Flags().StringVar(&app.Config.Paths.PluginDir, "plugin-dir", "", "Location of plugins")
 	cmd.Flags().IntVar(&app.Config.Grpc.Port, "grpc-port", 5656, "gRPC API port to listen on")
 	cmd.Flags().IntVar(&app.Config.HTTP.Port, "http-port", 5657, "HTTP API port to listen on")
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/asdine/lobby/cli/app"
+	"github.com/spf13/cobra"
+)
+
+func newTopicCmd(app *app.App) *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "topic",
+		Short: "manage the topics of a running lobby server",
+	}
+
+	cmd.AddCommand(newTopicMoveCmd(app))
+	return &cmd
+}
+
+func newTopicMoveCmd(app *app.App) *cobra.Command {
+	var copyMessages, reset bool
+	var opts []string
+
+	cmd := cobra.Command{
+		Use:   "move <topic> <backend>",
+		Short: "bind a topic to another backend",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("requires a topic and a backend")
+			}
+
+			err := app.Config.Paths.Create()
+			if err != nil {
+				return err
+			}
+
+			reg, err := dialRegistry(app)
+			if err != nil {
+				return err
+			}
+			defer reg.Close()
+
+			topicName, backendName := args[0], args[1]
+
+			options := make(map[string]string)
+			if !reset {
+				info, err := reg.Info(topicName)
+				if err != nil {
+					return err
+				}
+
+				for k, v := range info.Options {
+					options[k] = v
+				}
+			}
+
+			for _, opt := range opts {
+				kv := strings.SplitN(opt, "=", 2)
+				if len(kv) != 2 || kv[0] == "" {
+					return fmt.Errorf("invalid option %q, must be of the form key=value", opt)
+				}
+
+				if kv[1] == "" {
+					delete(options, kv[0])
+				} else {
+					options[kv[0]] = kv[1]
+				}
+			}
+
+			if copyMessages {
+				err = reg.Migrate(context.Background(), topicName, backendName, options)
+			} else {
+				err = reg.Rebind(topicName, backendName, options)
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Topic %s moved to %s\n", topicName, backendName)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&copyMessages, "copy", false, "Copy the messages of the topic to the new backend")
+	cmd.Flags().StringArrayVar(&opts, "option", nil, "Option of the topic, of the form key=value. An empty value removes the option")
+	cmd.Flags().BoolVar(&reset, "reset-options", false, "Don't keep the current options of the topic")
+
+	return &cmd
+}
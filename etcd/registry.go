@@ -3,6 +3,7 @@ package etcd
 import (
 	"context"
 	"path"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -88,7 +89,7 @@ func (r *Registry) watchTopics(c clientv3.WatchChan) {
 					r.logger.Debugf("Synchronizing new topic %s from etcd registry\n", ev.Kv.Key)
 				}
 			case mvccpb.DELETE:
-				k := string(ev.Kv.Key)
+				k := strings.TrimPrefix(string(ev.Kv.Key), r.topicsPrefix)
 				r.topics.delete(k)
 				r.logger.Debugf("Deleting topic %s\n", k)
 			}
@@ -108,14 +109,16 @@ func (r *Registry) storeTopic(key, value []byte) error {
 }
 
 // Create a topic in the registry.
-func (r *Registry) Create(backendName, topicName string) error {
+func (r *Registry) Create(backendName, topicName string, options map[string]string) error {
 	if _, ok := r.backends[backendName]; !ok {
 		return lobby.ErrBackendNotFound
 	}
 
 	topic := etcdpb.Topic{
-		Name:    topicName,
-		Backend: backendName,
+		Name:      topicName,
+		Backend:   backendName,
+		CreatedAt: time.Now().UnixNano(),
+		Options:   options,
 	}
 
 	exists := r.topics.setIfNotExist(topicName, &topic)
@@ -132,6 +135,100 @@ func (r *Registry) Create(backendName, topicName string) error {
 	return errors.Wrapf(err, "failed to create topic %s", topicName)
 }
 
+// Delete a topic from the registry.
+func (r *Registry) Delete(topicName string) error {
+	if _, ok := r.topics.get(topicName); !ok {
+		return lobby.ErrTopicNotFound
+	}
+
+	resp, err := r.client.Delete(context.Background(), path.Join(r.topicsPrefix, topicName))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete topic %s", topicName)
+	}
+
+	r.topics.delete(topicName)
+
+	if resp.Deleted == 0 {
+		return lobby.ErrTopicNotFound
+	}
+
+	return nil
+}
+
+// Rebind a topic to another backend and replace its options.
+func (r *Registry) Rebind(topicName, backendName string, options map[string]string) error {
+	if _, ok := r.backends[backendName]; !ok {
+		return lobby.ErrBackendNotFound
+	}
+
+	current, ok := r.topics.get(topicName)
+	if !ok {
+		return lobby.ErrTopicNotFound
+	}
+
+	topic := etcdpb.Topic{
+		Name:      topicName,
+		Backend:   backendName,
+		CreatedAt: current.CreatedAt,
+		Options:   options,
+	}
+
+	raw, err := proto.Marshal(&topic)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode topic %s", topicName)
+	}
+
+	// the topic is only updated if it wasn't deleted in the meantime.
+	key := path.Join(r.topicsPrefix, topicName)
+	resp, err := r.client.Txn(context.Background()).
+		If(clientv3.Compare(clientv3.Version(key), ">", 0)).
+		Then(clientv3.OpPut(key, string(raw))).
+		Commit()
+	if err != nil {
+		return errors.Wrapf(err, "failed to rebind topic %s", topicName)
+	}
+
+	if !resp.Succeeded {
+		r.topics.delete(topicName)
+		return lobby.ErrTopicNotFound
+	}
+
+	r.topics.set(topicName, &topic)
+	return nil
+}
+
+// Info returns informations about the selected topic.
+func (r *Registry) Info(topicName string) (*lobby.TopicInfo, error) {
+	topic, ok := r.topics.get(topicName)
+	if !ok {
+		return nil, lobby.ErrTopicNotFound
+	}
+
+	info := topicInfo(topic)
+	return &info, nil
+}
+
+// List topics ordered by name.
+func (r *Registry) List(offset, limit int) ([]lobby.TopicInfo, error) {
+	topics := r.topics.list()
+
+	if offset >= len(topics) {
+		return []lobby.TopicInfo{}, nil
+	}
+
+	topics = topics[offset:]
+	if limit > 0 && limit < len(topics) {
+		topics = topics[:limit]
+	}
+
+	list := make([]lobby.TopicInfo, len(topics))
+	for i := range topics {
+		list[i] = topicInfo(topics[i])
+	}
+
+	return list, nil
+}
+
 // Topic returns the selected topic from the Backend.
 func (r *Registry) Topic(name string) (lobby.Topic, error) {
 	topic, ok := r.topics.get(name)
@@ -144,7 +241,21 @@ func (r *Registry) Topic(name string) (lobby.Topic, error) {
 		return nil, lobby.ErrTopicNotFound
 	}
 
-	return backend.Topic(name)
+	return backend.Topic(name, topic.Options)
+}
+
+func topicInfo(t *etcdpb.Topic) lobby.TopicInfo {
+	info := lobby.TopicInfo{
+		Name:    t.Name,
+		Backend: t.Backend,
+		Options: t.Options,
+	}
+
+	if t.CreatedAt != 0 {
+		info.CreatedAt = time.Unix(0, t.CreatedAt)
+	}
+
+	return info
 }
 
 // Close etcd connection and registered backends.
@@ -201,6 +312,23 @@ func (t *topics) delete(k string) {
 	t.Unlock()
 }
 
+// list returns the topics sorted by name.
+func (t *topics) list() []*etcdpb.Topic {
+	t.RLock()
+	names := make([]string, 0, len(t.topics))
+	for k := range t.topics {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	list := make([]*etcdpb.Topic, len(names))
+	for i, k := range names {
+		list[i] = t.topics[k]
+	}
+	t.RUnlock()
+	return list
+}
+
 func (t *topics) size() int {
 	t.RLock()
 	size := len(t.topics)
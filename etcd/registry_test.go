@@ -49,17 +49,66 @@ func TestEtcdRegistry(t *testing.T) {
 	require.Equal(t, reg.topics.size(), 5)
 
 	reg.RegisterBackend("backend", new(mock.Backend))
-	err = reg.Create("backend", "sometopic")
+	err = reg.Create("backend", "sometopic", nil)
 	require.NoError(t, err)
 	require.Equal(t, reg.topics.size(), 6)
 
-	err = reg.Create("backend", "sometopic")
+	err = reg.Create("backend", "sometopic", nil)
 	require.Equal(t, lobby.ErrTopicAlreadyExists, err)
 	require.Equal(t, reg.topics.size(), 6)
 
 	_, err = reg.Topic("sometopic")
 	require.NoError(t, err)
 
+	info, err := reg.Info("sometopic")
+	require.NoError(t, err)
+	require.Equal(t, "sometopic", info.Name)
+	require.Equal(t, "backend", info.Backend)
+	require.False(t, info.CreatedAt.IsZero())
+
+	list, err := reg.List(0, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 6)
+	require.Equal(t, "sometopic", list[0].Name)
+
+	list, err = reg.List(2, 3)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	require.Equal(t, "topic-1", list[0].Name)
+
+	list, err = reg.List(10, 3)
+	require.NoError(t, err)
+	require.Len(t, list, 0)
+
+	reg.RegisterBackend("other", new(mock.Backend))
+	err = reg.Rebind("sometopic", "other", map[string]string{"key": "value"})
+	require.NoError(t, err)
+
+	info, err = reg.Info("sometopic")
+	require.NoError(t, err)
+	require.Equal(t, "other", info.Backend)
+	require.Equal(t, map[string]string{"key": "value"}, info.Options)
+	require.False(t, info.CreatedAt.IsZero())
+
+	err = reg.Rebind("sometopic", "unknown", nil)
+	require.Equal(t, lobby.ErrBackendNotFound, err)
+
+	err = reg.Rebind("unknown", "other", nil)
+	require.Equal(t, lobby.ErrTopicNotFound, err)
+
+	err = reg.Delete("sometopic")
+	require.NoError(t, err)
+	require.Equal(t, reg.topics.size(), 5)
+
+	_, err = reg.Topic("sometopic")
+	require.Equal(t, lobby.ErrTopicNotFound, err)
+
+	err = reg.Delete("sometopic")
+	require.Equal(t, lobby.ErrTopicNotFound, err)
+
+	_, err = reg.Info("sometopic")
+	require.Equal(t, lobby.ErrTopicNotFound, err)
+
 	err = reg.Close()
 	require.NoError(t, err)
 }
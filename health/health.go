@@ -0,0 +1,177 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
+	"github.com/pkg/errors"
+)
+
+// Default settings of a Monitor.
+const (
+	DefaultInterval = 10 * time.Second
+	DefaultTimeout  = 2 * time.Second
+)
+
+var errNotChecked = errors.New("not checked yet")
+
+// Status of a monitored component.
+type Status struct {
+	// Error returned by the last check. Nil if the component is healthy.
+	Err error
+	// Time of the last check. Zero if the component wasn't checked yet.
+	CheckedAt time.Time
+}
+
+// Healthy returns true if the component was checked and is healthy.
+func (s Status) Healthy() bool {
+	return !s.CheckedAt.IsZero() && s.Err == nil
+}
+
+var _ lobby.HealthChecker = new(Monitor)
+
+// NewMonitor returns a Monitor that checks the health of its components every interval.
+// Checks are aborted after the given timeout.
+func NewMonitor(interval, timeout time.Duration, logger *log.Logger) *Monitor {
+	m := Monitor{
+		interval: interval,
+		timeout:  timeout,
+		logger:   logger,
+		checkers: make(map[string]lobby.HealthChecker),
+		statuses: make(map[string]Status),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go m.run()
+
+	return &m
+}
+
+// Monitor periodically checks the health of a set of components and caches the results.
+type Monitor struct {
+	interval time.Duration
+	timeout  time.Duration
+	logger   *log.Logger
+
+	m        sync.RWMutex
+	checkers map[string]lobby.HealthChecker
+	statuses map[string]Status
+
+	closeOnce sync.Once
+	quit      chan struct{}
+	done      chan struct{}
+}
+
+// Register a component under the given name. It is checked right away, then periodically.
+func (m *Monitor) Register(name string, c lobby.HealthChecker) {
+	m.m.Lock()
+	m.checkers[name] = c
+	m.statuses[name] = Status{Err: errNotChecked}
+	m.m.Unlock()
+
+	go m.check(name, c)
+}
+
+// Statuses returns the status of every component, indexed by name.
+func (m *Monitor) Statuses() map[string]Status {
+	m.m.RLock()
+	defer m.m.RUnlock()
+
+	statuses := make(map[string]Status, len(m.statuses))
+	for name, s := range m.statuses {
+		statuses[name] = s
+	}
+
+	return statuses
+}
+
+// Health returns an error if one of the components is unhealthy.
+// It uses the result of the last checks and never blocks.
+func (m *Monitor) Health(ctx context.Context) error {
+	statuses := m.Statuses()
+
+	names := make([]string, 0, len(statuses))
+	for name := range statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if s := statuses[name]; !s.Healthy() {
+			return errors.Wrapf(s.Err, "%s is unhealthy", name)
+		}
+	}
+
+	return nil
+}
+
+// Close stops checking the components.
+func (m *Monitor) Close() error {
+	m.closeOnce.Do(func() {
+		close(m.quit)
+	})
+	<-m.done
+
+	return nil
+}
+
+func (m *Monitor) run() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.quit:
+			return
+		case <-ticker.C:
+			m.checkAll()
+		}
+	}
+}
+
+// checkAll checks all the components concurrently, so that a slow component doesn't delay the others.
+func (m *Monitor) checkAll() {
+	m.m.RLock()
+	checkers := make(map[string]lobby.HealthChecker, len(m.checkers))
+	for name, c := range m.checkers {
+		checkers[name] = c
+	}
+	m.m.RUnlock()
+
+	var wg sync.WaitGroup
+	for name, c := range checkers {
+		wg.Add(1)
+		go func(name string, c lobby.HealthChecker) {
+			defer wg.Done()
+			m.check(name, c)
+		}(name, c)
+	}
+	wg.Wait()
+}
+
+func (m *Monitor) check(name string, c lobby.HealthChecker) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	err := c.Health(ctx)
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	prev := m.statuses[name]
+	m.statuses[name] = Status{Err: err, CheckedAt: time.Now()}
+
+	switch {
+	case err != nil && (prev.Healthy() || prev.CheckedAt.IsZero()):
+		m.logger.Warnf("%s is unhealthy: %s\n", name, err)
+	case err == nil && !prev.Healthy() && !prev.CheckedAt.IsZero():
+		m.logger.Printf("%s is healthy again\n", name)
+	}
+}
@@ -0,0 +1,129 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/asdine/lobby/health"
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// waitForCheck blocks until the component was checked at least once.
+func waitForCheck(t *testing.T, m *health.Monitor, name string) health.Status {
+	deadline := time.Now().Add(time.Second)
+	for {
+		s := m.Statuses()[name]
+		if !s.CheckedAt.IsZero() {
+			return s
+		}
+
+		require.True(t, time.Now().Before(deadline), "component wasn't checked")
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	logger := log.New(log.Output(ioutil.Discard))
+
+	t.Run("Empty", func(t *testing.T) {
+		m := health.NewMonitor(time.Hour, time.Second, logger)
+		defer m.Close()
+
+		require.NoError(t, m.Health(context.Background()))
+		require.Empty(t, m.Statuses())
+	})
+
+	t.Run("Healthy", func(t *testing.T) {
+		m := health.NewMonitor(time.Hour, time.Second, logger)
+		defer m.Close()
+
+		m.Register("a", new(mock.HealthChecker))
+		m.Register("b", new(mock.HealthChecker))
+
+		require.True(t, waitForCheck(t, m, "a").Healthy())
+		require.True(t, waitForCheck(t, m, "b").Healthy())
+		require.NoError(t, m.Health(context.Background()))
+	})
+
+	t.Run("Unhealthy", func(t *testing.T) {
+		m := health.NewMonitor(time.Hour, time.Second, logger)
+		defer m.Close()
+
+		m.Register("a", new(mock.HealthChecker))
+		m.Register("b", &mock.HealthChecker{
+			HealthFn: func(ctx context.Context) error {
+				return errors.New("connection refused")
+			},
+		})
+
+		waitForCheck(t, m, "a")
+		s := waitForCheck(t, m, "b")
+		require.False(t, s.Healthy())
+		require.EqualError(t, s.Err, "connection refused")
+		require.EqualError(t, m.Health(context.Background()), "b is unhealthy: connection refused")
+	})
+
+	t.Run("NotChecked", func(t *testing.T) {
+		m := health.NewMonitor(time.Hour, time.Second, logger)
+		defer m.Close()
+
+		block := make(chan struct{})
+		defer close(block)
+
+		m.Register("a", &mock.HealthChecker{
+			HealthFn: func(ctx context.Context) error {
+				<-block
+				return nil
+			},
+		})
+
+		require.Error(t, m.Health(context.Background()))
+		require.False(t, m.Statuses()["a"].Healthy())
+	})
+
+	t.Run("Periodic", func(t *testing.T) {
+		m := health.NewMonitor(time.Millisecond, time.Second, logger)
+		defer m.Close()
+
+		healthy := make(chan bool, 1)
+		healthy <- false
+		m.Register("a", &mock.HealthChecker{
+			HealthFn: func(ctx context.Context) error {
+				select {
+				case ok := <-healthy:
+					if !ok {
+						return errors.New("starting")
+					}
+				default:
+				}
+				return nil
+			},
+		})
+
+		deadline := time.Now().Add(time.Second)
+		for m.Health(context.Background()) != nil {
+			require.True(t, time.Now().Before(deadline), "component wasn't checked again")
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		m := health.NewMonitor(time.Hour, time.Millisecond, logger)
+		defer m.Close()
+
+		m.Register("a", &mock.HealthChecker{
+			HealthFn: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})
+
+		s := waitForCheck(t, m, "a")
+		require.Equal(t, context.DeadlineExceeded, s.Err)
+	})
+}
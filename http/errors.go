@@ -14,12 +14,19 @@ const (
 	errInvalidJSON  = lobby.Error("invalid_json")
 	errInternal     = lobby.Error("internal_error")
 	errEmptyContent = lobby.Error("empty_content")
+	errTimeout      = lobby.Error("timeout")
+	errRateLimited  = lobby.Error("rate_limited")
+	errTooLarge     = lobby.Error("message_too_large")
 )
 
 // writeError writes an API error message to the response and logger.
 func writeError(w http.ResponseWriter, err error, code int, logger *log.Logger) {
-	// Log error.
-	logger.Debugf("http error: %s (code=%d)", err, code)
+	// Log error. Internal errors are unexpected, the others are caused by the client.
+	if code >= http.StatusInternalServerError {
+		logger.Errorf("http error: %s (code=%d)", err, code)
+	} else {
+		logger.Debugf("http error: %s (code=%d)", err, code)
+	}
 
 	// Hide error from client if it is internal.
 	if code == http.StatusInternalServerError {
@@ -44,7 +51,9 @@ func writeError(w http.ResponseWriter, err error, code int, logger *log.Logger)
 		err = enc.Encode(&errorResponse{Err: err.Error()})
 	}
 
-	logger.Println(err)
+	if err != nil {
+		logger.Errorf("failed to write error response: %s", err)
+	}
 }
 
 // errorResponse is a generic response for sending an error.
@@ -1,22 +1,37 @@
 package http
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/auth"
+	"github.com/asdine/lobby/health"
 	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/metrics"
+	"github.com/asdine/lobby/ratelimit"
+	"github.com/asdine/lobby/trace"
 	"github.com/asdine/lobby/validation"
 	"github.com/julienschmidt/httprouter"
 )
 
 const maxBodySize = 1024 * 1024
 
+// maxBatchSize is the maximum number of messages that can be sent in a batch.
+const maxBatchSize = 1000
+
+// ndjsonContentType is the content type of the requests sending a batch of messages.
+const ndjsonContentType = "application/x-ndjson"
+
 // NewServer returns an http lobby server.
 func NewServer(handler http.Handler) lobby.Server {
 	return &Server{
@@ -55,6 +70,8 @@ func (s *Server) Stop() error {
 
 type wrapper struct {
 	handler http.Handler
+	auth    auth.Authenticator
+	limiter *ratelimit.Limiter
 	logger  *log.Logger
 }
 
@@ -64,23 +81,130 @@ func (s *wrapper) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	rw := newResponseWriter(w)
 
+	// the identity of verified client certificates is used for authorization.
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		r = r.WithContext(auth.NewCertificateContext(r.Context(), r.TLS.VerifiedChains[0][0]))
+	}
+
+	// requests sent with a W3C trace context are part of the trace of the client.
+	ctx, span := trace.Start(trace.ContextWithTraceparent(r.Context(), r.Header.Get("Traceparent")), "HTTP "+r.Method, trace.Server)
+
+	// the request id sent by the client is reused, so that its logs can be correlated with Lobby's.
+	id := r.Header.Get(requestIDHeader)
+	if !validRequestID(id) {
+		id = log.NewRequestID()
+	}
+	rw.Header().Set(requestIDHeader, id)
+
+	r = r.WithContext(log.NewContext(ctx, log.Fields{
+		log.RequestIDKey: id,
+		"trace_id":       span.Context.TraceID.String(),
+	}))
+	r = s.authenticate(r)
+
 	if r.ContentLength > maxBodySize {
-		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		rw.WriteHeader(http.StatusRequestEntityTooLarge)
+	} else if ok, wait := s.allow(r); !ok {
+		rw.Header().Set("Retry-After", strconv.Itoa(ratelimit.RetryAfter(wait)))
+		writeError(rw, lobby.ErrRateLimited, http.StatusTooManyRequests, s.logger.WithContext(r.Context()))
 	} else {
 		s.handler.ServeHTTP(rw, r)
 	}
 
-	s.logger.Debugf(
+	span.SetAttribute("http.method", r.Method)
+	span.SetAttribute("http.target", r.URL.RequestURI())
+	span.SetAttribute("http.status_code", strconv.Itoa(rw.status))
+	if rw.status >= http.StatusInternalServerError {
+		span.SetError(errors.New(http.StatusText(rw.status)))
+	}
+	span.Finish()
+
+	elapsed := time.Since(start)
+	httpRequests.Inc(r.Method, strconv.Itoa(rw.status))
+	httpRequestDuration.Observe(elapsed.Seconds(), r.Method)
+	httpResponseBytes.Add(float64(rw.len), r.Method)
+
+	s.logger.WithContext(r.Context()).Debugf(
 		"%s %s %s %d %d %s",
 		clientIP(r),
 		r.Method,
 		r.URL,
 		rw.status,
 		rw.len,
-		time.Since(start),
+		elapsed,
 	)
 }
 
+// authenticate stores the identity of the client in the context of the request if its credentials are valid.
+// The identity is used to enforce the rate limits of the client and reused to authorize the request.
+func (s *wrapper) authenticate(r *http.Request) *http.Request {
+	if s.auth == nil {
+		return r
+	}
+
+	id, err := auth.Authenticate(r.Context(), s.auth, auth.BearerToken(r.Header.Get("Authorization")))
+	if err != nil {
+		// the handler reports the error if the endpoint requires authentication.
+		return r
+	}
+
+	return r.WithContext(auth.NewIdentityContext(r.Context(), id))
+}
+
+// allow checks the rate limits of the client and, if the request sends a message, of the topic.
+// Batches are charged to the topic by postBatch, once their messages are counted.
+// The health and metrics endpoints are never limited.
+func (s *wrapper) allow(r *http.Request) (bool, time.Duration) {
+	if s.limiter == nil || !strings.HasPrefix(r.URL.Path, "/v1/") {
+		return true, 0
+	}
+
+	var topic string
+	if r.Method == "POST" && !strings.HasPrefix(r.Header.Get("Content-Type"), ndjsonContentType) {
+		// messages are posted to /v1/topics/:topic and /v1/topics/:topic/:group.
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/topics/"), "/")
+		if parts[0] != "" && parts[0] != r.URL.Path {
+			topic = parts[0]
+		}
+	}
+
+	return s.limiter.Allow(clientKey(r), topic)
+}
+
+// clientKey identifies the client of a request for rate limits, by its verified identity or by its address.
+func clientKey(r *http.Request) string {
+	var identity string
+	if id, ok := auth.IdentityFromContext(r.Context()); ok {
+		identity = id.Name
+	}
+
+	// the address of the connection is used rather than clientIP which trusts headers set by the client.
+	addr, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		addr = r.RemoteAddr
+	}
+
+	return ratelimit.ClientKey(identity, addr)
+}
+
+// requestIDHeader holds the id of a request, sent by the client or generated by Lobby.
+const requestIDHeader = "X-Request-Id"
+
+// validRequestID returns true if the id sent by the client is short and printable.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
 // newResponseWriter instantiates a responseWriter.
 func newResponseWriter(w http.ResponseWriter) *responseWriter {
 	return &responseWriter{
@@ -106,93 +230,377 @@ func (w *responseWriter) WriteHeader(status int) {
 }
 
 func (w *responseWriter) Write(data []byte) (int, error) {
-	w.len = len(data)
-	return w.ResponseWriter.Write(data)
+	n, err := w.ResponseWriter.Write(data)
+	w.len += n
+	return n, err
 }
 
-// encodeJSON encodes v to w in JSON format. Error() is called if encoding fails.
-func encodeJSON(w http.ResponseWriter, v interface{}, status int, logger *log.Logger) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	if err := json.NewEncoder(w).Encode(v); err != nil {
-		writeError(w, err, http.StatusInternalServerError, logger)
+// Hijack lets the caller take over the connection. Required by websockets.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer doesn't support hijacking")
 	}
+
+	w.status = http.StatusSwitchingProtocols
+	return h.Hijack()
 }
 
 // encodeJSON encodes v to w in JSON format. Error() is called if encoding fails.
-func writeRawJSON(w http.ResponseWriter, v []byte, status int, logger *log.Logger) {
+func encodeJSON(w http.ResponseWriter, v interface{}, status int, logger *log.Logger) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	if _, err := w.Write(v); err != nil {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
 		writeError(w, err, http.StatusInternalServerError, logger)
 	}
 }
 
-// NewHandler instantiates a configured Handler.
-func NewHandler(r lobby.Registry, logger *log.Logger) http.Handler {
+// NewHandler instantiates a configured Handler. The monitor is used to report the health of Lobby,
+// if nil Lobby is always reported as healthy. Requests are authenticated using the authenticator,
+// if nil authentication is disabled. Requests exceeding the limits of the limiter are rejected,
+// if nil requests are not limited.
+func NewHandler(r lobby.Registry, m *health.Monitor, a auth.Authenticator, l *ratelimit.Limiter, logger *log.Logger) http.Handler {
 	router := httprouter.New()
 
 	h := handler{
 		registry: r,
+		monitor:  m,
+		auth:     a,
+		limiter:  l,
 		logger:   logger,
 		router:   router,
 	}
 
 	router.POST("/v1/topics", h.createTopic)
-	router.POST("/v1/topics/:topic", h.postMessage)
-	router.POST("/v1/topics/:topic/:group", h.postMessage)
-	return &wrapper{handler: router, logger: h.logger}
+	router.GET("/v1/topics", h.listTopics)
+	router.GET("/v1/topics/:topic", withTopic(h.getTopic))
+	router.GET("/v1/topics/:topic/messages", withTopic(h.readMessages))
+	router.GET("/v1/topics/:topic/messages/:group", withTopic(h.readMessages))
+	router.GET("/v1/topics/:topic/ws", withTopic(h.topicWebsocket))
+	router.POST("/v1/topics/:topic", withTopic(h.postMessage))
+	router.POST("/v1/topics/:topic/:group", withTopic(h.postMessage))
+	router.PUT("/v1/topics/:topic", withTopic(h.moveTopic))
+	router.DELETE("/v1/topics/:topic", withTopic(h.deleteTopic))
+	router.GET("/health", h.health)
+	router.GET("/metrics", h.metrics)
+	return &wrapper{handler: router, auth: a, limiter: l, logger: h.logger}
 }
 
 type handler struct {
 	registry lobby.Registry
+	monitor  *health.Monitor
+	auth     auth.Authenticator
+	limiter  *ratelimit.Limiter
 	router   *httprouter.Router
 	logger   *log.Logger
 }
 
+// withTopic adds the topic of the request to its log fields.
+func withTopic(handle httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		log.AddFields(r.Context(), log.Fields{"topic": ps.ByName("topic")})
+		handle(w, r, ps)
+	}
+}
+
+// log returns the logger of the request, adding its fields to every entry.
+func (h *handler) log(r *http.Request) *log.Logger {
+	return h.logger.WithContext(r.Context())
+}
+
+// authorize checks that the client is granted the permissions on the topic.
+// If not, it replies with an error and returns false.
+func (h *handler) authorize(w http.ResponseWriter, r *http.Request, topic string, permissions ...auth.Permission) bool {
+	err := auth.Authorize(r.Context(), h.auth, auth.BearerToken(r.Header.Get("Authorization")), topic, permissions...)
+	if err != nil {
+		h.writeAuthError(w, r, err)
+		return false
+	}
+
+	return true
+}
+
+func (h *handler) writeAuthError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case lobby.ErrUnauthenticated:
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, err, http.StatusUnauthorized, h.log(r))
+	case lobby.ErrPermissionDenied:
+		writeError(w, err, http.StatusForbidden, h.log(r))
+	default:
+		writeError(w, err, http.StatusInternalServerError, h.log(r))
+	}
+}
+
+// metrics reports the metrics of Lobby in the Prometheus text format.
+func (h *handler) metrics(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	metrics.Handler(metrics.DefaultRegistry).ServeHTTP(w, r)
+}
+
+// health reports the result of the last health checks. Lobby is live as long as it responds,
+// and ready if all of its components are healthy.
+func (h *handler) health(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	resp := healthResponse{
+		Live:   true,
+		Ready:  true,
+		Checks: make(map[string]*healthCheckResponse),
+	}
+
+	if h.monitor != nil {
+		for name, s := range h.monitor.Statuses() {
+			resp.Checks[name] = newHealthCheckResponse(&s)
+			if !s.Healthy() {
+				resp.Ready = false
+			}
+		}
+	}
+
+	status := http.StatusOK
+	if !resp.Ready {
+		status = http.StatusServiceUnavailable
+	}
+
+	encodeJSON(w, &resp, status, h.log(r))
+}
+
 func (h *handler) createTopic(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	var req topicCreationRequest
 
 	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
-		writeError(w, nil, http.StatusUnsupportedMediaType, h.logger)
+		writeError(w, nil, http.StatusUnsupportedMediaType, h.log(r))
 		return
 	}
 
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		writeError(w, errInvalidJSON, http.StatusBadRequest, h.logger)
+		writeError(w, errInvalidJSON, http.StatusBadRequest, h.log(r))
 		return
 	}
 
 	err = req.Validate()
 	if err != nil {
-		writeError(w, err, http.StatusBadRequest, h.logger)
+		writeError(w, err, http.StatusBadRequest, h.log(r))
+		return
+	}
+
+	if !h.authorize(w, r, req.Name, auth.Create) {
+		return
+	}
+
+	err = h.registry.Create(req.Backend, req.Name, req.Options)
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusCreated)
+	case err == lobby.ErrBackendNotFound:
+		http.NotFound(w, r)
+	case err == lobby.ErrTopicAlreadyExists:
+		writeError(w, validation.AddError(nil, "name", err), http.StatusBadRequest, h.log(r))
+	case validation.IsError(err):
+		// invalid options.
+		writeError(w, err, http.StatusBadRequest, h.log(r))
+	default:
+		writeError(w, err, http.StatusInternalServerError, h.log(r))
+	}
+}
+
+func (h *handler) listTopics(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	p, err := parsePage(r.URL.Query())
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest, h.log(r))
+		return
+	}
+
+	var id *auth.Identity
+	if h.auth != nil {
+		id, err = auth.Authenticate(r.Context(), h.auth, auth.BearerToken(r.Header.Get("Authorization")))
+		if err != nil {
+			h.writeAuthError(w, r, err)
+			return
+		}
+	}
+
+	// topics the client can't read are omitted, offset and limit apply to the others.
+	list, err := auth.List(h.registry, id, p.Offset, p.Limit)
+	if err != nil {
+		writeError(w, err, http.StatusInternalServerError, h.log(r))
+		return
+	}
+
+	resp := topicListResponse{
+		Topics: make([]*topicResponse, 0, len(list)),
+		Offset: p.Offset,
+		Limit:  p.Limit,
+	}
+
+	for i := range list {
+		resp.Topics = append(resp.Topics, newTopicResponse(&list[i]))
+	}
+
+	encodeJSON(w, &resp, http.StatusOK, h.log(r))
+}
+
+func (h *handler) getTopic(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !h.authorize(w, r, ps.ByName("topic"), auth.Read) {
 		return
 	}
 
-	err = h.registry.Create(req.Backend, req.Name)
+	info, err := h.registry.Info(ps.ByName("topic"))
 	switch err {
 	case nil:
-		w.WriteHeader(http.StatusCreated)
-	case lobby.ErrBackendNotFound:
+		encodeJSON(w, newTopicResponse(info), http.StatusOK, h.log(r))
+	case lobby.ErrTopicNotFound:
 		http.NotFound(w, r)
-	case lobby.ErrTopicAlreadyExists:
-		writeError(w, validation.AddError(nil, "name", err), http.StatusBadRequest, h.logger)
 	default:
-		writeError(w, err, http.StatusInternalServerError, h.logger)
+		writeError(w, err, http.StatusInternalServerError, h.log(r))
+	}
+}
+
+func (h *handler) readMessages(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !h.authorize(w, r, ps.ByName("topic"), auth.Read) {
+		return
+	}
+
+	limit, err := parseLimit(r.URL.Query())
+	if err != nil {
+		writeError(w, validation.AddError(nil, "limit", err), http.StatusBadRequest, h.log(r))
+		return
+	}
+
+	t, err := h.registry.Topic(ps.ByName("topic"))
+	if err != nil {
+		if err == lobby.ErrTopicNotFound {
+			http.NotFound(w, r)
+			return
+		}
+
+		writeError(w, err, http.StatusInternalServerError, h.log(r))
+		return
+	}
+	defer t.Close()
+
+	reader, ok := t.(lobby.TopicReader)
+	if !ok {
+		writeError(w, lobby.ErrNotSupported, http.StatusNotImplemented, h.log(r))
+		return
+	}
+
+	list, next, err := reader.Read(r.Context(), ps.ByName("group"), r.URL.Query().Get("cursor"), limit)
+	switch err {
+	case nil:
+	case context.DeadlineExceeded:
+		writeError(w, errTimeout, http.StatusGatewayTimeout, h.log(r))
+		return
+	case lobby.ErrInvalidCursor:
+		writeError(w, validation.AddError(nil, "cursor", err), http.StatusBadRequest, h.log(r))
+		return
+	case lobby.ErrNotSupported:
+		writeError(w, err, http.StatusNotImplemented, h.log(r))
+		return
+	default:
+		writeError(w, err, http.StatusInternalServerError, h.log(r))
+		return
+	}
+
+	resp := messageListResponse{
+		Messages: make([]*messageResponse, len(list)),
+		Next:     next,
+	}
+
+	for i := range list {
+		resp.Messages[i] = newMessageResponse(&list[i])
+	}
+
+	encodeJSON(w, &resp, http.StatusOK, h.log(r))
+}
+
+func (h *handler) deleteTopic(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !h.authorize(w, r, ps.ByName("topic"), auth.Create) {
+		return
+	}
+
+	err := h.registry.Delete(ps.ByName("topic"))
+	switch err {
+	case nil:
+		w.WriteHeader(http.StatusNoContent)
+	case lobby.ErrTopicNotFound:
+		http.NotFound(w, r)
+	default:
+		writeError(w, err, http.StatusInternalServerError, h.log(r))
+	}
+}
+
+func (h *handler) moveTopic(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var req topicMoveRequest
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		writeError(w, nil, http.StatusUnsupportedMediaType, h.log(r))
+		return
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeError(w, errInvalidJSON, http.StatusBadRequest, h.log(r))
+		return
+	}
+
+	err = req.Validate()
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest, h.log(r))
+		return
+	}
+
+	if !h.authorize(w, r, ps.ByName("topic"), auth.Create) {
+		return
+	}
+
+	if req.Copy {
+		m, ok := h.registry.(lobby.Migrator)
+		if !ok {
+			writeError(w, lobby.ErrNotSupported, http.StatusNotImplemented, h.log(r))
+			return
+		}
+
+		err = m.Migrate(r.Context(), ps.ByName("topic"), req.Backend, req.Options)
+	} else {
+		err = h.registry.Rebind(ps.ByName("topic"), req.Backend, req.Options)
+	}
+
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case err == lobby.ErrTopicNotFound || err == lobby.ErrBackendNotFound:
+		http.NotFound(w, r)
+	case err == lobby.ErrNotSupported:
+		// the current backend of the topic can't read its messages back.
+		writeError(w, err, http.StatusNotImplemented, h.log(r))
+	case err == context.DeadlineExceeded:
+		writeError(w, errTimeout, http.StatusGatewayTimeout, h.log(r))
+	case validation.IsError(err):
+		writeError(w, err, http.StatusBadRequest, h.log(r))
+	default:
+		writeError(w, err, http.StatusInternalServerError, h.log(r))
 	}
 }
 
 func (h *handler) postMessage(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !h.authorize(w, r, ps.ByName("topic"), auth.Send) {
+		return
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), ndjsonContentType) {
+		h.postBatch(w, r, ps)
+		return
+	}
+
 	if r.ContentLength == 0 {
-		writeError(w, errEmptyContent, http.StatusBadRequest, h.logger)
+		writeError(w, errEmptyContent, http.StatusBadRequest, h.log(r))
 		return
 	}
 
 	defer r.Body.Close()
 	value, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		writeError(w, err, http.StatusInternalServerError, h.logger)
+		writeError(w, err, http.StatusInternalServerError, h.log(r))
 		return
 	}
 
@@ -203,25 +611,157 @@ func (h *handler) postMessage(w http.ResponseWriter, r *http.Request, ps httprou
 			return
 		}
 
-		writeError(w, err, http.StatusInternalServerError, h.logger)
+		writeError(w, err, http.StatusInternalServerError, h.log(r))
+		return
+	}
+	defer t.Close()
+
+	m := lobby.NewMessage(ps.ByName("group"), value, parseMetaHeaders(r.Header))
+	err = t.Send(r.Context(), m)
+	switch {
+	case err == nil:
+	case err == context.DeadlineExceeded:
+		writeError(w, errTimeout, http.StatusGatewayTimeout, h.log(r))
+		return
+	case err == lobby.ErrMessageTooLarge:
+		writeError(w, err, http.StatusRequestEntityTooLarge, h.log(r))
+		return
+	case validation.IsError(err):
+		// rejected by a middleware.
+		writeError(w, err, http.StatusBadRequest, h.log(r))
+		return
+	default:
+		writeError(w, err, http.StatusInternalServerError, h.log(r))
 		return
 	}
 
-	err = t.Send(&lobby.Message{
-		Group: ps.ByName("group"),
-		Value: value,
-	})
+	encodeJSON(w, &messageCreatedResponse{ID: m.ID}, http.StatusCreated, h.log(r))
+}
+
+// postBatch sends the messages of a NDJSON body, one message per line, in a single batch.
+// Messages without group are given the group of the URL, if any.
+// The result of each message is returned in the same order.
+func (h *handler) postBatch(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	defer r.Body.Close()
+
+	t, err := h.registry.Topic(ps.ByName("topic"))
 	if err != nil {
-		writeError(w, err, http.StatusInternalServerError, h.logger)
+		if err == lobby.ErrTopicNotFound {
+			http.NotFound(w, r)
+			return
+		}
+
+		writeError(w, err, http.StatusInternalServerError, h.log(r))
 		return
 	}
+	defer t.Close()
+
+	var results []*batchResultResponse
+	var messages []*lobby.Message
+	// position in the batch of each valid message.
+	var indexes []int
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(nil, maxBodySize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		if len(results) == maxBatchSize {
+			writeError(w, validation.AddError(nil, "messages", errInvalidBatch), http.StatusBadRequest, h.log(r))
+			return
+		}
+
+		var res batchResultResponse
+		results = append(results, &res)
+
+		var req messageRequest
+		err = json.Unmarshal(line, &req)
+		if err != nil {
+			res.Err = errInvalidJSON.Error()
+			continue
+		}
+
+		if len(req.Value) == 0 {
+			res.Err = errEmptyContent.Error()
+			continue
+		}
+
+		group := req.Group
+		if group == "" {
+			group = ps.ByName("group")
+		}
 
-	writeRawJSON(w, nil, http.StatusCreated, h.logger)
+		m := lobby.NewMessage(group, req.Value, req.Headers)
+		res.ID = m.ID
+		messages = append(messages, m)
+		indexes = append(indexes, len(results)-1)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		if err == bufio.ErrTooLong {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		writeError(w, err, http.StatusInternalServerError, h.log(r))
+		return
+	}
+
+	if len(results) == 0 {
+		writeError(w, errEmptyContent, http.StatusBadRequest, h.log(r))
+		return
+	}
+
+	if h.limiter != nil && len(messages) > 0 {
+		// every message of the batch counts towards the limit of the topic.
+		if ok, wait := h.limiter.AllowN("", ps.ByName("topic"), len(messages)); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(ratelimit.RetryAfter(wait)))
+			writeError(w, lobby.ErrRateLimited, http.StatusTooManyRequests, h.log(r))
+			return
+		}
+	}
+
+	if len(messages) > 0 {
+		errs, err := lobby.SendBatch(r.Context(), t, messages)
+		switch err {
+		case nil:
+		case context.DeadlineExceeded:
+			writeError(w, errTimeout, http.StatusGatewayTimeout, h.log(r))
+			return
+		default:
+			writeError(w, err, http.StatusInternalServerError, h.log(r))
+			return
+		}
+
+		for i := range errs {
+			if errs[i] == nil {
+				continue
+			}
+
+			res := results[indexes[i]]
+			if _, ok := errs[i].(lobby.Error); ok {
+				res.Err = errs[i].Error()
+			} else if validation.IsError(errs[i]) {
+				res.Err = "validation error"
+				res.Fields = errs[i]
+			} else {
+				h.log(r).Debugf("http batch error: %s", errs[i])
+				res.Err = errInternal.Error()
+			}
+		}
+	}
+
+	encodeJSON(w, &batchResponse{Results: results}, http.StatusOK, h.log(r))
 }
 
 type topicCreationRequest struct {
-	Name    string `json:"name" valid:"required,alphanum,stringlength(1|64)"`
-	Backend string `json:"backend" valid:"required,alphanum"`
+	Name    string            `json:"name" valid:"required,alphanum,stringlength(1|64)"`
+	Backend string            `json:"backend" valid:"required,alphanum"`
+	Options map[string]string `json:"options"`
 }
 
 func (t *topicCreationRequest) Validate() error {
@@ -230,3 +770,121 @@ func (t *topicCreationRequest) Validate() error {
 
 	return validation.Validate(t)
 }
+
+type topicMoveRequest struct {
+	Backend string            `json:"backend" valid:"required,alphanum"`
+	Options map[string]string `json:"options"`
+	// Copy the messages of the topic to the new backend.
+	Copy bool `json:"copy"`
+}
+
+func (t *topicMoveRequest) Validate() error {
+	t.Backend = strings.TrimSpace(t.Backend)
+
+	return validation.Validate(t)
+}
+
+type topicResponse struct {
+	Name      string            `json:"name"`
+	Backend   string            `json:"backend"`
+	Options   map[string]string `json:"options,omitempty"`
+	CreatedAt *time.Time        `json:"created_at,omitempty"`
+}
+
+func newTopicResponse(info *lobby.TopicInfo) *topicResponse {
+	t := topicResponse{
+		Name:    info.Name,
+		Backend: info.Backend,
+		Options: info.Options,
+	}
+
+	if !info.CreatedAt.IsZero() {
+		t.CreatedAt = &info.CreatedAt
+	}
+
+	return &t
+}
+
+type topicListResponse struct {
+	Topics []*topicResponse `json:"topics"`
+	Offset int              `json:"offset"`
+	Limit  int              `json:"limit"`
+}
+
+type messageRequest struct {
+	Group   string            `json:"group"`
+	Value   []byte            `json:"value"`
+	Headers map[string]string `json:"headers"`
+}
+
+type messageResponse struct {
+	ID        string            `json:"id,omitempty"`
+	Group     string            `json:"group,omitempty"`
+	Value     []byte            `json:"value"`
+	CreatedAt *time.Time        `json:"created_at,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+}
+
+func newMessageResponse(m *lobby.Message) *messageResponse {
+	resp := messageResponse{
+		ID:      m.ID,
+		Group:   m.Group,
+		Value:   m.Value,
+		Headers: m.Headers,
+	}
+
+	if !m.CreatedAt.IsZero() {
+		resp.CreatedAt = &m.CreatedAt
+	}
+
+	return &resp
+}
+
+type messageCreatedResponse struct {
+	ID string `json:"id"`
+}
+
+type messageListResponse struct {
+	Messages []*messageResponse `json:"messages"`
+	Next     string             `json:"next,omitempty"`
+}
+
+type batchResultResponse struct {
+	ID  string `json:"id,omitempty"`
+	Err string `json:"err,omitempty"`
+	// Fields is set for messages failing validation, as in validationErrorResponse.
+	Fields error `json:"fields,omitempty"`
+}
+
+type batchResponse struct {
+	Results []*batchResultResponse `json:"results"`
+}
+
+type healthResponse struct {
+	Live   bool                            `json:"live"`
+	Ready  bool                            `json:"ready"`
+	Checks map[string]*healthCheckResponse `json:"checks"`
+}
+
+type healthCheckResponse struct {
+	Healthy   bool       `json:"healthy"`
+	Err       string     `json:"err,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+}
+
+func newHealthCheckResponse(s *health.Status) *healthCheckResponse {
+	c := healthCheckResponse{
+		Healthy: s.Healthy(),
+	}
+
+	if s.Err != nil {
+		c.Err = s.Err.Error()
+	}
+
+	if !s.CheckedAt.IsZero() {
+		checkedAt := s.CheckedAt.UTC()
+		c.CheckedAt = &checkedAt
+	}
+
+	return &c
+}
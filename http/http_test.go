@@ -2,18 +2,35 @@ package http_test
 
 import (
 	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
 	"errors"
 	"io"
 	"io/ioutil"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/auth"
+	"github.com/asdine/lobby/health"
 	lobbyHttp "github.com/asdine/lobby/http"
 	"github.com/asdine/lobby/log"
 	"github.com/asdine/lobby/mock"
+	"github.com/asdine/lobby/ratelimit"
+	"github.com/asdine/lobby/schema"
+	"github.com/asdine/lobby/trace"
+	"github.com/asdine/lobby/validation"
 	"github.com/stretchr/testify/require"
 )
 
@@ -27,7 +44,7 @@ func createTopicRequest(t *testing.T, r io.Reader) *http.Request {
 func TestCreateTopic(t *testing.T) {
 	t.Run("EmptyBody", func(t *testing.T) {
 		var registry mock.Registry
-		h := lobbyHttp.NewHandler(&registry, log.New(log.Output(ioutil.Discard)))
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
 
 		w := httptest.NewRecorder()
 		r := createTopicRequest(t, bytes.NewReader([]byte(nil)))
@@ -37,7 +54,7 @@ func TestCreateTopic(t *testing.T) {
 
 	t.Run("InvalidJSON", func(t *testing.T) {
 		var registry mock.Registry
-		h := lobbyHttp.NewHandler(&registry, log.New(log.Output(ioutil.Discard)))
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
 
 		w := httptest.NewRecorder()
 		r := createTopicRequest(t, strings.NewReader(`hello`))
@@ -47,7 +64,7 @@ func TestCreateTopic(t *testing.T) {
 
 	t.Run("ValidationError", func(t *testing.T) {
 		var registry mock.Registry
-		h := lobbyHttp.NewHandler(&registry, log.New(log.Output(ioutil.Discard)))
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
 
 		w := httptest.NewRecorder()
 		r := createTopicRequest(t, strings.NewReader(`{"name": "   "}`))
@@ -58,14 +75,14 @@ func TestCreateTopic(t *testing.T) {
 	t.Run("BackendNotFound", func(t *testing.T) {
 		var registry mock.Registry
 
-		registry.CreateFn = func(backendName, topicName string) error {
+		registry.CreateFn = func(backendName, topicName string, options map[string]string) error {
 			require.Equal(t, "backend", backendName)
 			require.Equal(t, "topic", topicName)
 
 			return lobby.ErrBackendNotFound
 		}
 
-		h := lobbyHttp.NewHandler(&registry, log.New(log.Output(ioutil.Discard)))
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
 
 		w := httptest.NewRecorder()
 		r := createTopicRequest(t, strings.NewReader(`{"name": "   topic   ", "backend": "backend"}`))
@@ -76,14 +93,14 @@ func TestCreateTopic(t *testing.T) {
 	t.Run("BackendConflict", func(t *testing.T) {
 		var registry mock.Registry
 
-		registry.CreateFn = func(backendName, topicName string) error {
+		registry.CreateFn = func(backendName, topicName string, options map[string]string) error {
 			require.Equal(t, "backend", backendName)
 			require.Equal(t, "topic", topicName)
 
 			return lobby.ErrTopicAlreadyExists
 		}
 
-		h := lobbyHttp.NewHandler(&registry, log.New(log.Output(ioutil.Discard)))
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
 
 		w := httptest.NewRecorder()
 		r := createTopicRequest(t, strings.NewReader(`{"name": "   topic   ","backend": "backend"}`))
@@ -94,14 +111,14 @@ func TestCreateTopic(t *testing.T) {
 	t.Run("InternalError", func(t *testing.T) {
 		var registry mock.Registry
 
-		registry.CreateFn = func(backendName, topicName string) error {
+		registry.CreateFn = func(backendName, topicName string, options map[string]string) error {
 			require.Equal(t, "backend", backendName)
 			require.Equal(t, "topic", topicName)
 
 			return errors.New("something unexpected happened !")
 		}
 
-		h := lobbyHttp.NewHandler(&registry, log.New(log.Output(ioutil.Discard)))
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
 
 		w := httptest.NewRecorder()
 		r := createTopicRequest(t, strings.NewReader(`{"name": "   topic   ", "backend": "backend"}`))
@@ -112,26 +129,336 @@ func TestCreateTopic(t *testing.T) {
 	t.Run("OK", func(t *testing.T) {
 		var registry mock.Registry
 
-		registry.CreateFn = func(backendName, topicName string) error {
+		registry.CreateFn = func(backendName, topicName string, options map[string]string) error {
 			require.Equal(t, "backend", backendName)
 			require.Equal(t, "topic", topicName)
 
 			return nil
 		}
 
-		h := lobbyHttp.NewHandler(&registry, log.New(log.Output(ioutil.Discard)))
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
 
 		w := httptest.NewRecorder()
 		r := createTopicRequest(t, strings.NewReader(`{"name": "   topic   ", "backend": "backend"}`))
 		h.ServeHTTP(w, r)
 		require.Equal(t, http.StatusCreated, w.Code)
 	})
+	t.Run("Options", func(t *testing.T) {
+		var registry mock.Registry
+
+		registry.CreateFn = func(backendName, topicName string, options map[string]string) error {
+			require.Equal(t, map[string]string{"collection": "events"}, options)
+
+			return nil
+		}
+
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		r := createTopicRequest(t, strings.NewReader(`{"name": "topic", "backend": "backend", "options": {"collection": "events"}}`))
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("InvalidOptions", func(t *testing.T) {
+		var registry mock.Registry
+
+		registry.CreateFn = func(backendName, topicName string, options map[string]string) error {
+			return validation.AddError(nil, "mirror-ack", errors.New("must be one of all, any or primary-only"))
+		}
+
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		r := createTopicRequest(t, strings.NewReader(`{"name": "topic", "backend": "backend", "options": {"mirrors": "nsq", "mirror-ack": "some"}}`))
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.JSONEq(t, `{"err": "validation error", "fields": {"mirror-ack": ["must be one of all, any or primary-only"]}}`, w.Body.String())
+	})
+}
+
+func TestListTopics(t *testing.T) {
+	t.Run("InvalidPagination", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		for _, query := range []string{"offset=-1", "offset=a", "limit=0", "limit=1000", "limit=a"} {
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest("GET", "/v1/topics?"+query, nil)
+			h.ServeHTTP(w, r)
+			require.Equal(t, http.StatusBadRequest, w.Code)
+		}
+		require.Zero(t, registry.ListInvoked)
+	})
+
+	t.Run("InternalError", func(t *testing.T) {
+		var registry mock.Registry
+
+		registry.ListFn = func(offset, limit int) ([]lobby.TopicInfo, error) {
+			return nil, errors.New("something unexpected happened !")
+		}
+
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/topics", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		var registry mock.Registry
+
+		registry.ListFn = func(offset, limit int) ([]lobby.TopicInfo, error) {
+			require.Equal(t, 10, offset)
+			require.Equal(t, 2, limit)
+
+			return []lobby.TopicInfo{
+				{Name: "a", Backend: "bolt", CreatedAt: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)},
+				{Name: "b", Backend: "redis"},
+			}, nil
+		}
+
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/topics?offset=10&limit=2", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{
+			"topics": [
+				{"name": "a", "backend": "bolt", "created_at": "2018-01-01T00:00:00Z"},
+				{"name": "b", "backend": "redis"}
+			],
+			"offset": 10,
+			"limit": 2
+		}`, w.Body.String())
+	})
+
+	t.Run("DefaultPagination", func(t *testing.T) {
+		var registry mock.Registry
+
+		registry.ListFn = func(offset, limit int) ([]lobby.TopicInfo, error) {
+			require.Equal(t, 0, offset)
+			require.Equal(t, 20, limit)
+
+			return nil, nil
+		}
+
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/topics", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"topics": [], "offset": 0, "limit": 20}`, w.Body.String())
+	})
+}
+
+func TestGetTopic(t *testing.T) {
+	t.Run("TopicNotFound", func(t *testing.T) {
+		var registry mock.Registry
+
+		registry.InfoFn = func(topicName string) (*lobby.TopicInfo, error) {
+			require.Equal(t, "topic", topicName)
+
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/topics/topic", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InternalError", func(t *testing.T) {
+		var registry mock.Registry
+
+		registry.InfoFn = func(topicName string) (*lobby.TopicInfo, error) {
+			return nil, errors.New("something unexpected happened !")
+		}
+
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/topics/topic", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		var registry mock.Registry
+
+		registry.InfoFn = func(topicName string) (*lobby.TopicInfo, error) {
+			require.Equal(t, "topic", topicName)
+
+			return &lobby.TopicInfo{
+				Name:      "topic",
+				Backend:   "bolt",
+				Options:   map[string]string{"key": "value"},
+				CreatedAt: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+			}, nil
+		}
+
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/topics/topic", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"name": "topic", "backend": "bolt", "options": {"key": "value"}, "created_at": "2018-01-01T00:00:00Z"}`, w.Body.String())
+	})
+}
+
+func TestDeleteTopic(t *testing.T) {
+	t.Run("TopicNotFound", func(t *testing.T) {
+		var registry mock.Registry
+
+		registry.DeleteFn = func(topicName string) error {
+			require.Equal(t, "topic", topicName)
+
+			return lobby.ErrTopicNotFound
+		}
+
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("DELETE", "/v1/topics/topic", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InternalError", func(t *testing.T) {
+		var registry mock.Registry
+
+		registry.DeleteFn = func(topicName string) error {
+			require.Equal(t, "topic", topicName)
+
+			return errors.New("something unexpected happened !")
+		}
+
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("DELETE", "/v1/topics/topic", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		var registry mock.Registry
+
+		registry.DeleteFn = func(topicName string) error {
+			require.Equal(t, "topic", topicName)
+
+			return nil
+		}
+
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("DELETE", "/v1/topics/topic", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Equal(t, 1, registry.DeleteInvoked)
+	})
+}
+
+// migrator is a registry able to migrate topics.
+type migrator struct {
+	mock.Registry
+
+	migrateFn func(ctx context.Context, topicName, backendName string, options map[string]string) error
+}
+
+func (m *migrator) Migrate(ctx context.Context, topicName, backendName string, options map[string]string) error {
+	return m.migrateFn(ctx, topicName, backendName, options)
+}
+
+func moveTopicRequest(t *testing.T, body string) *http.Request {
+	req, err := http.NewRequest("PUT", "/v1/topics/topic", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestMoveTopic(t *testing.T) {
+	t.Run("InvalidBody", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, moveTopicRequest(t, `{"options": {"a": "b"}}`))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Zero(t, registry.RebindInvoked)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		registry.RebindFn = func(topicName, backendName string, options map[string]string) error {
+			return lobby.ErrBackendNotFound
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, moveTopicRequest(t, `{"backend": "redis"}`))
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Rebind", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		registry.RebindFn = func(topicName, backendName string, options map[string]string) error {
+			require.Equal(t, "topic", topicName)
+			require.Equal(t, "redis", backendName)
+			require.Equal(t, map[string]string{"key": "quotes"}, options)
+			return nil
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, moveTopicRequest(t, `{"backend": "redis", "options": {"key": "quotes"}}`))
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Equal(t, 1, registry.RebindInvoked)
+	})
+
+	t.Run("CopyNotSupported", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, moveTopicRequest(t, `{"backend": "redis", "copy": true}`))
+		require.Equal(t, http.StatusNotImplemented, w.Code)
+		require.Zero(t, registry.RebindInvoked)
+	})
+
+	t.Run("Copy", func(t *testing.T) {
+		var invoked int
+		registry := migrator{
+			migrateFn: func(ctx context.Context, topicName, backendName string, options map[string]string) error {
+				invoked++
+				require.Equal(t, "topic", topicName)
+				require.Equal(t, "redis", backendName)
+				return nil
+			},
+		}
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, moveTopicRequest(t, `{"backend": "redis", "copy": true}`))
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Equal(t, 1, invoked)
+		require.Zero(t, registry.RebindInvoked)
+	})
 }
 
 func TestSaveMessage(t *testing.T) {
 	t.Run("EmptyBody", func(t *testing.T) {
 		var registry mock.Registry
-		h := lobbyHttp.NewHandler(&registry, log.New(log.Output(ioutil.Discard)))
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
 
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest("POST", "/v1/topics/topic/key", bytes.NewReader([]byte(nil)))
@@ -141,7 +468,7 @@ func TestSaveMessage(t *testing.T) {
 
 	t.Run("TopicNotFound", func(t *testing.T) {
 		var registry mock.Registry
-		h := lobbyHttp.NewHandler(&registry, log.New(log.Output(ioutil.Discard)))
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
 
 		registry.TopicFn = func(name string) (lobby.Topic, error) {
 			require.Equal(t, "topic", name)
@@ -157,7 +484,7 @@ func TestSaveMessage(t *testing.T) {
 
 	t.Run("InternalError", func(t *testing.T) {
 		var registry mock.Registry
-		h := lobbyHttp.NewHandler(&registry, log.New(log.Output(ioutil.Discard)))
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
 
 		registry.TopicFn = func(name string) (lobby.Topic, error) {
 			require.Equal(t, "topic", name)
@@ -171,26 +498,838 @@ func TestSaveMessage(t *testing.T) {
 		require.Equal(t, http.StatusInternalServerError, w.Code)
 	})
 
+	t.Run("Timeout", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		registry.TopicFn = func(name string) (lobby.Topic, error) {
+			return &mock.Topic{
+				SendFn: func(ctx context.Context, message *lobby.Message) error {
+					return context.DeadlineExceeded
+				},
+			}, nil
+		}
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/v1/topics/topic", strings.NewReader(`hello`))
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusGatewayTimeout, w.Code)
+		require.JSONEq(t, `{"err": "timeout"}`, w.Body.String())
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		registry.TopicFn = func(name string) (lobby.Topic, error) {
+			return &mock.Topic{
+				SendFn: func(ctx context.Context, message *lobby.Message) error {
+					return lobby.ErrMessageTooLarge
+				},
+			}, nil
+		}
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/v1/topics/topic", strings.NewReader(`hello`))
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		require.JSONEq(t, `{"err": "message too large"}`, w.Body.String())
+	})
+
+	t.Run("Schema", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		s, err := schema.Compile([]byte(`{"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}}}`))
+		require.NoError(t, err)
+
+		var tp mock.Topic
+		registry.TopicFn = func(name string) (lobby.Topic, error) {
+			return schema.Middleware(s)(&tp), nil
+		}
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/v1/topics/topic", strings.NewReader(`{"id": "a"}`))
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.JSONEq(t, `{"err": "validation error", "fields": {"value.id": ["must be of type integer"]}}`, w.Body.String())
+		require.Zero(t, tp.SendInvoked)
+	})
+
 	t.Run("OK", func(t *testing.T) {
 		var registry mock.Registry
-		h := lobbyHttp.NewHandler(&registry, log.New(log.Output(ioutil.Discard)))
+		var id string
+		var topic mock.Topic
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
 
 		registry.TopicFn = func(name string) (lobby.Topic, error) {
 			require.Equal(t, "topic", name)
 
-			return &mock.Topic{
-				SendFn: func(message *lobby.Message) error {
+			topic = mock.Topic{
+				SendFn: func(ctx context.Context, message *lobby.Message) error {
 					require.Equal(t, "group", message.Group)
 					require.Equal(t, []byte(`hello`), message.Value)
+					require.NotEmpty(t, message.ID)
+					require.False(t, message.CreatedAt.IsZero())
+					require.Equal(t, map[string]string{"source": "test", "trace-id": "abc"}, message.Headers)
+					id = message.ID
 
 					return nil
 				},
-			}, nil
+			}
+			return &topic, nil
 		}
 
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest("POST", "/v1/topics/topic/group", strings.NewReader(`hello`))
+		r.Header.Set("X-Lobby-Meta-Source", "test")
+		r.Header.Set("X-Lobby-Meta-Trace-Id", "abc")
+		r.Header.Set("X-Other", "other")
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.JSONEq(t, `{"id": "`+id+`"}`, w.Body.String())
+		require.Equal(t, 1, topic.CloseInvoked)
+	})
+}
+
+func TestSaveBatch(t *testing.T) {
+	t.Run("TopicNotFound", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		registry.TopicFn = func(name string) (lobby.Topic, error) {
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/v1/topics/topic", strings.NewReader(`{"value": "MQ=="}`))
+		r.Header.Set("Content-Type", "application/x-ndjson")
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("EmptyBody", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		registry.TopicFn = func(name string) (lobby.Topic, error) {
+			return new(mock.Topic), nil
+		}
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/v1/topics/topic", strings.NewReader("\n\n"))
+		r.Header.Set("Content-Type", "application/x-ndjson")
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("TooManyMessages", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		registry.TopicFn = func(name string) (lobby.Topic, error) {
+			return new(mock.Topic), nil
+		}
+
+		w := httptest.NewRecorder()
+		body := strings.Repeat(`{"value": "MQ=="}`+"\n", 1001)
+		r, _ := http.NewRequest("POST", "/v1/topics/topic", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-ndjson")
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		var registry mock.Registry
+		var ids []string
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		registry.TopicFn = func(name string) (lobby.Topic, error) {
+			require.Equal(t, "topic", name)
+
+			return &mock.Topic{
+				SendBatchFn: func(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+					require.Len(t, messages, 3)
+					require.Equal(t, "a", messages[0].Group)
+					require.Equal(t, "1", string(messages[0].Value))
+					require.Equal(t, "test", messages[0].Headers["source"])
+					require.Equal(t, "group", messages[1].Group)
+					for _, m := range messages {
+						ids = append(ids, m.ID)
+					}
+
+					return []error{nil, errors.New("something unexpected happened !"), lobby.ErrNotSupported}, nil
+				},
+			}, nil
+		}
+
+		w := httptest.NewRecorder()
+		body := `{"group": "a", "value": "MQ==", "headers": {"source": "test"}}
+{"value": "Mg=="}
+hello
+{"group": "a"}
+
+{"value": "Mw=="}
+`
+		r, _ := http.NewRequest("POST", "/v1/topics/topic/group", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-ndjson")
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, ids, 3)
+		require.JSONEq(t, `{"results": [
+			{"id": "`+ids[0]+`"},
+			{"id": "`+ids[1]+`", "err": "internal_error"},
+			{"err": "invalid_json"},
+			{"err": "empty_content"},
+			{"id": "`+ids[2]+`", "err": "operation not supported"}
+		]}`, w.Body.String())
+	})
+
+	t.Run("Schema", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		s, err := schema.Compile([]byte(`{"type": "string"}`))
+		require.NoError(t, err)
+
+		registry.TopicFn = func(name string) (lobby.Topic, error) {
+			return schema.Middleware(s)(&mock.Topic{
+				SendBatchFn: func(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+					require.Len(t, messages, 1)
+					return make([]error, len(messages)), nil
+				},
+			}), nil
+		}
+
+		w := httptest.NewRecorder()
+		body := `{"value": "ImEi"}
+{"value": "MQ=="}
+`
+		r, _ := http.NewRequest("POST", "/v1/topics/topic", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-ndjson")
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Results []struct {
+				ID     string              `json:"id"`
+				Err    string              `json:"err"`
+				Fields map[string][]string `json:"fields"`
+			} `json:"results"`
+		}
+		err = json.NewDecoder(w.Body).Decode(&resp)
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 2)
+		require.Empty(t, resp.Results[0].Err)
+		require.Equal(t, "validation error", resp.Results[1].Err)
+		require.Equal(t, map[string][]string{"value": {"must be of type string"}}, resp.Results[1].Fields)
+	})
+}
+
+func TestReadMessages(t *testing.T) {
+	t.Run("TopicNotFound", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		registry.TopicFn = func(name string) (lobby.Topic, error) {
+			require.Equal(t, "topic", name)
+
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/topics/topic/messages", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidLimit", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/topics/topic/messages?limit=1000", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Zero(t, registry.TopicInvoked)
+	})
+
+	t.Run("NotSupported", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		registry.TopicFn = func(name string) (lobby.Topic, error) {
+			return lobby.TopicFunc(func(context.Context, *lobby.Message) error {
+				return nil
+			}), nil
+		}
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/topics/topic/messages", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+
+	t.Run("InvalidCursor", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		registry.TopicFn = func(name string) (lobby.Topic, error) {
+			return &mock.Topic{
+				ReadFn: func(ctx context.Context, group, cursor string, limit int) ([]lobby.Message, string, error) {
+					return nil, "", lobby.ErrInvalidCursor
+				},
+			}, nil
+		}
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/topics/topic/messages?cursor=abc", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		topic := mock.Topic{
+			ReadFn: func(ctx context.Context, group, cursor string, limit int) ([]lobby.Message, string, error) {
+				require.Equal(t, "group", group)
+				require.Equal(t, "10", cursor)
+				require.Equal(t, 2, limit)
+
+				return []lobby.Message{
+					{Group: "group", Value: []byte("a")},
+					{Group: "group", Value: []byte("b")},
+				}, "12", nil
+			},
+		}
+
+		registry.TopicFn = func(name string) (lobby.Topic, error) {
+			require.Equal(t, "topic", name)
+
+			return &topic, nil
+		}
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/topics/topic/messages/group?cursor=10&limit=2", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{
+			"messages": [
+				{"group": "group", "value": "YQ=="},
+				{"group": "group", "value": "Yg=="}
+			],
+			"next": "12"
+		}`, w.Body.String())
+		require.Equal(t, 1, topic.CloseInvoked)
+	})
+}
+
+func TestHealth(t *testing.T) {
+	logger := log.New(log.Output(ioutil.Discard))
+
+	// waitForCheck blocks until the component was checked.
+	waitForCheck := func(t *testing.T, m *health.Monitor, name string) {
+		deadline := time.Now().Add(time.Second)
+		for m.Statuses()[name].CheckedAt.IsZero() {
+			require.True(t, time.Now().Before(deadline), "component wasn't checked")
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	t.Run("NoMonitor", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, logger)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/health", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"live": true, "ready": true, "checks": {}}`, w.Body.String())
+	})
+
+	t.Run("Ready", func(t *testing.T) {
+		m := health.NewMonitor(time.Hour, time.Second, logger)
+		defer m.Close()
+		m.Register("mongo backend", new(mock.HealthChecker))
+		waitForCheck(t, m, "mongo backend")
+
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, m, nil, nil, logger)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/health", nil)
 		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), `"ready":true`)
+		require.Contains(t, w.Body.String(), `"mongo backend":{"healthy":true,"checked_at":`)
+	})
+
+	t.Run("NotReady", func(t *testing.T) {
+		m := health.NewMonitor(time.Hour, time.Second, logger)
+		defer m.Close()
+		m.Register("mongo backend", new(mock.HealthChecker))
+		m.Register("redis backend", &mock.HealthChecker{
+			HealthFn: func(ctx context.Context) error {
+				return errors.New("connection refused")
+			},
+		})
+		waitForCheck(t, m, "mongo backend")
+		waitForCheck(t, m, "redis backend")
+
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, m, nil, nil, logger)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/health", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Contains(t, w.Body.String(), `"live":true,"ready":false`)
+		require.Contains(t, w.Body.String(), `"redis backend":{"healthy":false,"err":"connection refused","checked_at":`)
+	})
+}
+
+func TestAuth(t *testing.T) {
+	var registry mock.Registry
+	registry.TopicFn = func(name string) (lobby.Topic, error) {
+		return new(mock.Topic), nil
+	}
+	registry.ListFn = func(offset, limit int) ([]lobby.TopicInfo, error) {
+		// the client can't read the topic in the middle of the list.
+		all := []lobby.TopicInfo{
+			{Name: "logs-app", Backend: "bolt"},
+			{Name: "quotes", Backend: "bolt"},
+			{Name: "logs-db", Backend: "bolt"},
+		}
+
+		if offset > len(all) {
+			offset = len(all)
+		}
+		end := len(all)
+		if limit > 0 && offset+limit < end {
+			end = offset + limit
+		}
+		return all[offset:end], nil
+	}
+
+	a := mock.Authenticator{
+		AuthenticateFn: func(ctx context.Context, token string) (*auth.Identity, error) {
+			if token != "key" {
+				return nil, lobby.ErrUnauthenticated
+			}
+
+			return &auth.Identity{
+				Name: "producer",
+				Rules: []auth.Rule{
+					{Topic: "quotes", Permissions: []auth.Permission{auth.Send}},
+					{Topic: "logs-*", Permissions: []auth.Permission{auth.Read}},
+				},
+			}, nil
+		},
+	}
+
+	h := lobbyHttp.NewHandler(&registry, nil, &a, nil, log.New(log.Output(ioutil.Discard)))
+
+	request := func(method, url, token string, body io.Reader) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(method, url, body)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("NoToken", func(t *testing.T) {
+		w := request("POST", "/v1/topics/quotes", "", strings.NewReader("value"))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+		require.JSONEq(t, `{"err": "unauthenticated"}`, w.Body.String())
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		w := request("POST", "/v1/topics/quotes", "other", strings.NewReader("value"))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("PermissionDenied", func(t *testing.T) {
+		w := request("GET", "/v1/topics/quotes/messages", "key", nil)
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.JSONEq(t, `{"err": "permission denied"}`, w.Body.String())
+
+		w = request("DELETE", "/v1/topics/quotes", "key", nil)
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Zero(t, registry.DeleteInvoked)
+
+		w = httptest.NewRecorder()
+		r := createTopicRequest(t, strings.NewReader(`{"name": "quotes", "backend": "bolt"}`))
+		r.Header.Set("Authorization", "Bearer key")
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Zero(t, registry.CreateInvoked)
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		w := request("POST", "/v1/topics/quotes", "key", strings.NewReader("value"))
 		require.Equal(t, http.StatusCreated, w.Code)
 	})
+
+	t.Run("List", func(t *testing.T) {
+		w := request("GET", "/v1/topics", "", nil)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = request("GET", "/v1/topics", "key", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{
+			"topics": [{"name": "logs-app", "backend": "bolt"}, {"name": "logs-db", "backend": "bolt"}],
+			"offset": 0,
+			"limit": 20
+		}`, w.Body.String())
+
+		// pages only count the topics the client can read.
+		w = request("GET", "/v1/topics?offset=0&limit=1", "key", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{
+			"topics": [{"name": "logs-app", "backend": "bolt"}],
+			"offset": 0,
+			"limit": 1
+		}`, w.Body.String())
+
+		w = request("GET", "/v1/topics?offset=1&limit=1", "key", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{
+			"topics": [{"name": "logs-db", "backend": "bolt"}],
+			"offset": 1,
+			"limit": 1
+		}`, w.Body.String())
+	})
+
+	t.Run("Health", func(t *testing.T) {
+		w := request("GET", "/health", "", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+// newCertificate generates a self-signed certificate.
+func newCertificate(t *testing.T, cn string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+}
+
+func TestAuthCertificate(t *testing.T) {
+	var registry mock.Registry
+	registry.TopicFn = func(name string) (lobby.Topic, error) {
+		return new(mock.Topic), nil
+	}
+
+	a := mock.Authenticator{
+		AuthenticateCertificateFn: func(ctx context.Context, cert *x509.Certificate) (*auth.Identity, error) {
+			if cert.Subject.CommonName != "producer" {
+				return nil, lobby.ErrUnauthenticated
+			}
+
+			return &auth.Identity{
+				Name: "producer",
+				Rules: []auth.Rule{
+					{Topic: "quotes", Permissions: []auth.Permission{auth.Send}},
+				},
+			}, nil
+		},
+	}
+
+	client := newCertificate(t, "producer")
+	other := newCertificate(t, "other")
+	pool := x509.NewCertPool()
+	pool.AddCert(client.Leaf)
+	pool.AddCert(other.Leaf)
+
+	srv := httptest.NewUnstartedServer(lobbyHttp.NewHandler(&registry, nil, &a, nil, log.New(log.Output(ioutil.Discard))))
+	srv.TLS = &tls.Config{
+		ClientCAs:  pool,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	post := func(cert tls.Certificate, topic string) int {
+		// a new transport is used so that connections aren't reused between certificates.
+		c := http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs:      srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs,
+					Certificates: []tls.Certificate{cert},
+				},
+			},
+		}
+
+		resp, err := c.Post(srv.URL+"/v1/topics/"+topic, "text/plain", strings.NewReader("value"))
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusCreated, post(client, "quotes"))
+	require.Equal(t, http.StatusForbidden, post(client, "other"))
+	require.Equal(t, http.StatusUnauthorized, post(other, "quotes"))
+}
+
+func TestMetrics(t *testing.T) {
+	var registry mock.Registry
+	registry.InfoFn = func(name string) (*lobby.TopicInfo, error) {
+		return nil, lobby.ErrTopicNotFound
+	}
+
+	h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+	scrape := func(series string) string {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/metrics", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Contains(t, w.Body.String(), "# TYPE lobby_http_requests_total counter")
+
+		for _, line := range strings.Split(w.Body.String(), "\n") {
+			if strings.HasPrefix(line, series+" ") {
+				return strings.TrimPrefix(line, series+" ")
+			}
+		}
+		return "0"
+	}
+
+	series := `lobby_http_requests_total{method="GET",code="404"}`
+	before, err := strconv.Atoi(scrape(series))
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/v1/topics/metrics", nil)
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	after, err := strconv.Atoi(scrape(series))
+	require.NoError(t, err)
+	require.Equal(t, before+1, after)
+}
+
+func TestTracing(t *testing.T) {
+	var spans []*trace.Span
+	var e mock.Exporter
+	e.ExportFn = func(s *trace.Span) {
+		spans = append(spans, s)
+	}
+
+	trace.SetExporter(&e)
+	defer trace.SetExporter(nil)
+
+	var registry mock.Registry
+	var sc trace.SpanContext
+	registry.TopicFn = func(name string) (lobby.Topic, error) {
+		return &mock.Topic{
+			SendFn: func(ctx context.Context, message *lobby.Message) error {
+				sc, _ = trace.FromContext(ctx)
+				return nil
+			},
+		}, nil
+	}
+
+	h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/v1/topics/topic", strings.NewReader(`"Value"`))
+	r.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	require.Len(t, spans, 1)
+	require.Equal(t, "HTTP POST", spans[0].Name)
+	require.Equal(t, trace.Server, spans[0].Kind)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].Context.TraceID.String())
+	require.Equal(t, "00f067aa0ba902b7", spans[0].Parent.String())
+	require.Equal(t, "/v1/topics/topic", spans[0].Attributes["http.target"])
+	require.Equal(t, "201", spans[0].Attributes["http.status_code"])
+	require.Empty(t, spans[0].Err)
+
+	// the topic receives the context of the request span.
+	require.Equal(t, spans[0].Context, sc)
+}
+
+func TestRequestLogs(t *testing.T) {
+	var registry mock.Registry
+	registry.TopicFn = func(name string) (lobby.Topic, error) {
+		return nil, errors.New("unexpected error")
+	}
+
+	var buf bytes.Buffer
+	h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(&buf), log.Format(log.JSONFormat)))
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/v1/topics/quotes", strings.NewReader(`"Value"`))
+	r.Header.Set("X-Request-Id", "my-request")
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, "my-request", w.Header().Get("X-Request-Id"))
+
+	var entry map[string]interface{}
+	err := json.NewDecoder(&buf).Decode(&entry)
+	require.NoError(t, err)
+	require.Equal(t, "error", entry["level"])
+	require.Equal(t, "my-request", entry["request_id"])
+	require.Equal(t, "quotes", entry["topic"])
+	require.Len(t, entry["trace_id"], 32)
+
+	// invalid request ids are replaced.
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", "/v1/topics", nil)
+	r.Header.Set("X-Request-Id", "invalid id")
+	registry.ListFn = func(offset, limit int) ([]lobby.TopicInfo, error) {
+		return nil, nil
+	}
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, w.Header().Get("X-Request-Id"), 16)
+}
+
+func TestRateLimit(t *testing.T) {
+	var registry mock.Registry
+	registry.TopicFn = func(name string) (lobby.Topic, error) {
+		return new(mock.Topic), nil
+	}
+
+	limiter := ratelimit.New(ratelimit.Limit{Rate: 0.001, Burst: 2}, ratelimit.Limit{Rate: 0.001, Burst: 1}, nil)
+	h := lobbyHttp.NewHandler(&registry, nil, nil, limiter, log.New(log.Output(ioutil.Discard)))
+
+	post := func(token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/v1/topics/quotes", strings.NewReader(`"Value"`))
+		r.RemoteAddr = "10.0.0.1:4000"
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := post("")
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// the limit of the topic applies to every client.
+	w = post("key")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "1000", w.Header().Get("Retry-After"))
+	require.JSONEq(t, `{"err": "rate limit exceeded"}`, w.Body.String())
+
+	// requests that don't send messages are only limited by the limit of the client.
+	w = httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/v1/topics/quotes/messages", nil)
+	r.RemoteAddr = "10.0.0.1:4000"
+	registry.TopicFn = func(name string) (lobby.Topic, error) {
+		return nil, lobby.ErrTopicNotFound
+	}
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	// the health endpoint is never limited.
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", "/health", nil)
+	r.RemoteAddr = "10.0.0.1:4000"
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRateLimitBatch(t *testing.T) {
+	var registry mock.Registry
+	registry.TopicFn = func(name string) (lobby.Topic, error) {
+		return new(mock.Topic), nil
+	}
+
+	limiter := ratelimit.New(ratelimit.Limit{}, ratelimit.Limit{Rate: 0.001, Burst: 3}, nil)
+	h := lobbyHttp.NewHandler(&registry, nil, nil, limiter, log.New(log.Output(ioutil.Discard)))
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/v1/topics/quotes", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-ndjson")
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// each message of a batch counts towards the limit of the topic.
+	w := post("{\"value\": \"MQ==\"}\n{\"value\": \"Mg==\"}\n")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = post("{\"value\": \"Mw==\"}\n{\"value\": \"NA==\"}\n")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "1000", w.Header().Get("Retry-After"))
+	require.JSONEq(t, `{"err": "rate limit exceeded"}`, w.Body.String())
+
+	w = post("{\"value\": \"NQ==\"}\n")
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRateLimitIdentity(t *testing.T) {
+	var registry mock.Registry
+	registry.ListFn = func(offset, limit int) ([]lobby.TopicInfo, error) {
+		return nil, nil
+	}
+
+	a := mock.Authenticator{
+		AuthenticateFn: func(ctx context.Context, token string) (*auth.Identity, error) {
+			if token != "key" {
+				return nil, lobby.ErrUnauthenticated
+			}
+
+			return &auth.Identity{Name: "producer"}, nil
+		},
+	}
+
+	limiter := ratelimit.New(ratelimit.Limit{Rate: 0.001, Burst: 1}, ratelimit.Limit{}, nil)
+	h := lobbyHttp.NewHandler(&registry, nil, &a, limiter, log.New(log.Output(ioutil.Discard)))
+
+	list := func(token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/topics", nil)
+		r.RemoteAddr = "10.0.0.1:4000"
+		r.Header.Set("Authorization", "Bearer "+token)
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// unverified tokens are limited by the address of the client.
+	w := list("random-1")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	w = list("random-2")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	// verified clients have their own limit.
+	w = list("key")
+	require.Equal(t, http.StatusOK, w.Code)
+	w = list("key")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
 }
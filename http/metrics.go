@@ -0,0 +1,24 @@
+package http
+
+import (
+	"github.com/asdine/lobby/metrics"
+)
+
+var (
+	httpRequests = metrics.NewCounter(
+		"lobby_http_requests_total",
+		"Number of HTTP requests handled, by method and status code.",
+		"method", "code",
+	)
+	httpRequestDuration = metrics.NewHistogram(
+		"lobby_http_request_duration_seconds",
+		"Duration of the HTTP requests, by method.",
+		nil,
+		"method",
+	)
+	httpResponseBytes = metrics.NewCounter(
+		"lobby_http_response_bytes_total",
+		"Size of the HTTP response bodies, by method.",
+		"method",
+	)
+)
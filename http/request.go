@@ -3,9 +3,32 @@ package http
 import (
 	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/validation"
+)
+
+// Pagination limits.
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// Pagination errors.
+const (
+	errInvalidOffset = lobby.Error("must be a positive integer")
+	errInvalidLimit  = lobby.Error("must be between 1 and 100")
 )
 
+// errInvalidBatch is returned when a batch contains too many messages.
+const errInvalidBatch = lobby.Error("must contain at most 1000 messages")
+
+// metaHeaderPrefix is the prefix of the request headers stored as message headers.
+const metaHeaderPrefix = "X-Lobby-Meta-"
+
 // returns the client real ip address.
 // inspired by https://github.com/gin-gonic/gin/blob/32cab500ecc71d2975f5699c8a65c6debb29cfbe/context.go#L341
 func clientIP(r *http.Request) string {
@@ -30,3 +53,71 @@ func clientIP(r *http.Request) string {
 
 	return ""
 }
+
+// page holds the pagination parameters of a request.
+type page struct {
+	Offset int
+	Limit  int
+}
+
+// parsePage reads the offset and limit query parameters.
+func parsePage(q url.Values) (*page, error) {
+	var p page
+	var verr error
+	var err error
+
+	if v := q.Get("offset"); v != "" {
+		p.Offset, err = strconv.Atoi(v)
+		if err != nil || p.Offset < 0 {
+			verr = validation.AddError(verr, "offset", errInvalidOffset)
+		}
+	}
+
+	p.Limit, err = parseLimit(q)
+	if err != nil {
+		verr = validation.AddError(verr, "limit", err)
+	}
+
+	if verr != nil {
+		return nil, verr
+	}
+
+	return &p, nil
+}
+
+// parseLimit reads the limit query parameter.
+func parseLimit(q url.Values) (int, error) {
+	v := q.Get("limit")
+	if v == "" {
+		return defaultPageLimit, nil
+	}
+
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		return 0, errInvalidLimit
+	}
+
+	return limit, nil
+}
+
+// parseMetaHeaders returns the X-Lobby-Meta-* headers of the request,
+// indexed by their lowercased name without the prefix.
+// Only the first value of each header is kept.
+func parseMetaHeaders(h http.Header) map[string]string {
+	var headers map[string]string
+
+	for k, v := range h {
+		k = http.CanonicalHeaderKey(k)
+		if !strings.HasPrefix(k, metaHeaderPrefix) || len(k) == len(metaHeaderPrefix) || len(v) == 0 {
+			continue
+		}
+
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+
+		headers[strings.ToLower(k[len(metaHeaderPrefix):])] = v[0]
+	}
+
+	return headers
+}
@@ -0,0 +1,259 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/auth"
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/ratelimit"
+	"github.com/asdine/lobby/validation"
+	"github.com/gorilla/websocket"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Websocket keepalive settings.
+const (
+	// Time allowed to write a frame to the client.
+	wsWriteWait = 10 * time.Second
+	// Time allowed to read the next pong from the client.
+	wsPongWait = 60 * time.Second
+	// Pings are sent with this period. Must be lower than wsPongWait.
+	wsPingPeriod = (wsPongWait * 9) / 10
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+}
+
+func (h *handler) topicWebsocket(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	// the connection is used both to read and to send messages.
+	if !h.authorize(w, r, ps.ByName("topic"), auth.Read, auth.Send) {
+		return
+	}
+
+	subscriber, ok := h.registry.(lobby.Subscriber)
+	if !ok {
+		writeError(w, lobby.ErrNotSupported, http.StatusNotImplemented, h.log(r))
+		return
+	}
+
+	t, err := h.registry.Topic(ps.ByName("topic"))
+	if err != nil {
+		if err == lobby.ErrTopicNotFound {
+			http.NotFound(w, r)
+			return
+		}
+
+		writeError(w, err, http.StatusInternalServerError, h.log(r))
+		return
+	}
+	defer t.Close()
+
+	sub, err := subscriber.Subscribe(ps.ByName("topic"), r.URL.Query().Get("group"))
+	if err != nil {
+		if err == lobby.ErrTopicNotFound {
+			http.NotFound(w, r)
+			return
+		}
+
+		writeError(w, err, http.StatusInternalServerError, h.log(r))
+		return
+	}
+	defer sub.Close()
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader already replied to the client.
+		h.log(r).Debugf("websocket upgrade failed: %s", err)
+		return
+	}
+
+	// in-flight sends are canceled when the connection is closed.
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	c := wsConn{
+		ctx:     ctx,
+		cancel:  cancel,
+		conn:    conn,
+		topic:   t,
+		name:    ps.ByName("topic"),
+		sub:     sub,
+		limiter: h.limiter,
+		client:  clientKey(r),
+		logger:  h.log(r),
+		done:    make(chan struct{}),
+		quit:    make(chan struct{}),
+		errc:    make(chan error),
+	}
+
+	c.run()
+}
+
+// wsConn publishes the messages received from a websocket connection to a topic
+// and writes the messages sent to that topic to the connection.
+type wsConn struct {
+	ctx    context.Context
+	cancel func()
+	conn   *websocket.Conn
+	topic  lobby.Topic
+	name   string
+	sub    lobby.Subscription
+	logger *log.Logger
+
+	// every message is subject to the rate limits of the client and of the topic.
+	limiter *ratelimit.Limiter
+	client  string
+
+	// closed by the reader when it stops.
+	done chan struct{}
+	// closed by the writer when it stops.
+	quit chan struct{}
+	// errors to report to the client.
+	errc chan error
+}
+
+func (c *wsConn) run() {
+	go c.read()
+
+	c.write()
+
+	c.cancel()
+	c.conn.Close()
+	<-c.done
+}
+
+// read messages from the connection and sends them to the topic.
+// Only one goroutine can read from the connection.
+func (c *wsConn) read() {
+	defer close(c.done)
+
+	c.conn.SetReadLimit(maxBodySize)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				c.logger.Debugf("websocket error: %s", err)
+			}
+			return
+		}
+
+		err = c.send(data)
+		if err == nil {
+			continue
+		}
+
+		select {
+		case c.errc <- err:
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+func (c *wsConn) send(data []byte) error {
+	var req messageRequest
+
+	err := json.Unmarshal(data, &req)
+	if err != nil {
+		return errInvalidJSON
+	}
+
+	if len(req.Value) == 0 {
+		return errEmptyContent
+	}
+
+	if c.limiter != nil {
+		if ok, _ := c.limiter.Allow(c.client, c.name); !ok {
+			return errRateLimited
+		}
+	}
+
+	err = c.topic.Send(c.ctx, lobby.NewMessage(req.Group, req.Value, req.Headers))
+	switch {
+	case err == nil:
+		return nil
+	case err == lobby.ErrMessageTooLarge:
+		return errTooLarge
+	case validation.IsError(err):
+		// rejected by a middleware.
+		return err
+	default:
+		c.logger.Debugf("websocket error: %s", err)
+		return errInternal
+	}
+}
+
+// write the messages of the subscription and the errors to the connection,
+// and pings the client periodically.
+// Only one goroutine can write to the connection.
+func (c *wsConn) write() {
+	defer close(c.quit)
+
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-c.done:
+			return
+		case m, ok := <-c.sub.Messages():
+			if !ok {
+				c.close(c.sub.Err())
+				return
+			}
+
+			err = c.writeJSON(newMessageResponse(m))
+		case e := <-c.errc:
+			if validation.IsError(e) {
+				err = c.writeJSON(&validationErrorResponse{Err: "validation error", Fields: e})
+			} else {
+				err = c.writeJSON(&errorResponse{Err: e.Error()})
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = c.conn.WriteMessage(websocket.PingMessage, nil)
+		}
+
+		if err != nil {
+			c.logger.Debugf("websocket error: %s", err)
+			return
+		}
+	}
+}
+
+func (c *wsConn) writeJSON(v interface{}) error {
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return c.conn.WriteJSON(v)
+}
+
+// close sends a close frame to the client explaining why the subscription ended.
+func (c *wsConn) close(err error) {
+	code := websocket.CloseGoingAway
+	if err == lobby.ErrSlowConsumer {
+		code = websocket.ClosePolicyViolation
+	}
+
+	var reason string
+	if err != nil {
+		reason = err.Error()
+	}
+
+	c.conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(wsWriteWait),
+	)
+}
@@ -0,0 +1,235 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asdine/lobby"
+	lobbyHttp "github.com/asdine/lobby/http"
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/mock"
+	"github.com/asdine/lobby/pubsub"
+	"github.com/asdine/lobby/ratelimit"
+	"github.com/asdine/lobby/validation"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func newPubSubRegistry(sendFn func(context.Context, *lobby.Message) error) *pubsub.Registry {
+	var m mock.Registry
+
+	m.InfoFn = func(name string) (*lobby.TopicInfo, error) {
+		if name != "topic" {
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		return &lobby.TopicInfo{Name: name}, nil
+	}
+
+	m.TopicFn = func(name string) (lobby.Topic, error) {
+		if name != "topic" {
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		return &mock.Topic{SendFn: sendFn}, nil
+	}
+
+	return pubsub.NewRegistry(&m, 10, log.New(log.Output(ioutil.Discard)))
+}
+
+type wsMessage struct {
+	ID        string            `json:"id"`
+	Group     string            `json:"group"`
+	Value     []byte            `json:"value"`
+	CreatedAt time.Time         `json:"created_at"`
+	Headers   map[string]string `json:"headers"`
+}
+
+func dialWebsocket(t *testing.T, srv *httptest.Server, path string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+path, nil)
+	require.NoError(t, err)
+	return conn
+}
+
+func TestTopicWebsocket(t *testing.T) {
+	t.Run("NotSupported", func(t *testing.T) {
+		var registry mock.Registry
+		h := lobbyHttp.NewHandler(&registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/topics/topic/ws", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+
+	t.Run("TopicNotFound", func(t *testing.T) {
+		registry := newPubSubRegistry(nil)
+		h := lobbyHttp.NewHandler(registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/topics/unknown/ws", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("NotAWebsocket", func(t *testing.T) {
+		registry := newPubSubRegistry(nil)
+		h := lobbyHttp.NewHandler(registry, nil, nil, nil, log.New(log.Output(ioutil.Discard)))
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/v1/topics/topic/ws", nil)
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("PubSub", func(t *testing.T) {
+		var sent []lobby.Message
+		registry := newPubSubRegistry(func(ctx context.Context, m *lobby.Message) error {
+			sent = append(sent, *m)
+			return nil
+		})
+		srv := httptest.NewServer(lobbyHttp.NewHandler(registry, nil, nil, nil, log.New(log.Output(ioutil.Discard))))
+		defer srv.Close()
+
+		all := dialWebsocket(t, srv, "/v1/topics/topic/ws")
+		defer all.Close()
+
+		group := dialWebsocket(t, srv, "/v1/topics/topic/ws?group=b")
+		defer group.Close()
+
+		err := all.WriteMessage(websocket.TextMessage, []byte(`{"group": "a", "value": "MQ==", "headers": {"source": "test"}}`))
+		require.NoError(t, err)
+		err = all.WriteMessage(websocket.TextMessage, []byte(`{"group": "b", "value": "Mg=="}`))
+		require.NoError(t, err)
+
+		var m wsMessage
+		err = all.ReadJSON(&m)
+		require.NoError(t, err)
+		require.Equal(t, "a", m.Group)
+		require.Equal(t, "1", string(m.Value))
+		require.Equal(t, "test", m.Headers["source"])
+		require.NotEmpty(t, m.ID)
+		require.False(t, m.CreatedAt.IsZero())
+		err = all.ReadJSON(&m)
+		require.NoError(t, err)
+		require.Equal(t, "b", m.Group)
+
+		err = group.ReadJSON(&m)
+		require.NoError(t, err)
+		require.Equal(t, "b", m.Group)
+		require.Equal(t, "2", string(m.Value))
+
+		require.Len(t, sent, 2)
+		require.Equal(t, "a", sent[0].Group)
+		require.Equal(t, "1", string(sent[0].Value))
+		require.NotEqual(t, sent[0].ID, sent[1].ID)
+	})
+
+	t.Run("InvalidMessages", func(t *testing.T) {
+		registry := newPubSubRegistry(func(ctx context.Context, m *lobby.Message) error {
+			return nil
+		})
+		srv := httptest.NewServer(lobbyHttp.NewHandler(registry, nil, nil, nil, log.New(log.Output(ioutil.Discard))))
+		defer srv.Close()
+
+		conn := dialWebsocket(t, srv, "/v1/topics/topic/ws")
+		defer conn.Close()
+
+		err := conn.WriteMessage(websocket.TextMessage, []byte(`hello`))
+		require.NoError(t, err)
+		_, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"err": "invalid_json"}`, string(data))
+
+		err = conn.WriteMessage(websocket.TextMessage, []byte(`{"group": "a"}`))
+		require.NoError(t, err)
+		_, data, err = conn.ReadMessage()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"err": "empty_content"}`, string(data))
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		registry := newPubSubRegistry(func(ctx context.Context, m *lobby.Message) error {
+			switch string(m.Value) {
+			case "1":
+				return validation.AddError(nil, "value.id", errors.New("must be of type integer"))
+			case "2":
+				return lobby.ErrMessageTooLarge
+			}
+			return errors.New("unexpected error")
+		})
+		srv := httptest.NewServer(lobbyHttp.NewHandler(registry, nil, nil, nil, log.New(log.Output(ioutil.Discard))))
+		defer srv.Close()
+
+		conn := dialWebsocket(t, srv, "/v1/topics/topic/ws")
+		defer conn.Close()
+
+		err := conn.WriteMessage(websocket.TextMessage, []byte(`{"value": "MQ=="}`))
+		require.NoError(t, err)
+		_, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"err": "validation error", "fields": {"value.id": ["must be of type integer"]}}`, string(data))
+
+		err = conn.WriteMessage(websocket.TextMessage, []byte(`{"value": "Mg=="}`))
+		require.NoError(t, err)
+		_, data, err = conn.ReadMessage()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"err": "message_too_large"}`, string(data))
+
+		err = conn.WriteMessage(websocket.TextMessage, []byte(`{"value": "Mw=="}`))
+		require.NoError(t, err)
+		_, data, err = conn.ReadMessage()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"err": "internal_error"}`, string(data))
+	})
+
+	t.Run("RateLimited", func(t *testing.T) {
+		registry := newPubSubRegistry(func(ctx context.Context, m *lobby.Message) error {
+			return nil
+		})
+		limiter := ratelimit.New(ratelimit.Limit{}, ratelimit.Limit{Rate: 0.001, Burst: 1}, nil)
+		srv := httptest.NewServer(lobbyHttp.NewHandler(registry, nil, nil, limiter, log.New(log.Output(ioutil.Discard))))
+		defer srv.Close()
+
+		conn := dialWebsocket(t, srv, "/v1/topics/topic/ws")
+		defer conn.Close()
+
+		err := conn.WriteMessage(websocket.TextMessage, []byte(`{"value": "MQ=="}`))
+		require.NoError(t, err)
+		var m wsMessage
+		err = conn.ReadJSON(&m)
+		require.NoError(t, err)
+		require.Equal(t, "1", string(m.Value))
+
+		// every message counts towards the limit of the topic.
+		err = conn.WriteMessage(websocket.TextMessage, []byte(`{"value": "Mg=="}`))
+		require.NoError(t, err)
+		_, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"err": "rate_limited"}`, string(data))
+	})
+
+	t.Run("TopicDeleted", func(t *testing.T) {
+		registry := newPubSubRegistry(nil)
+		registry.Registry.(*mock.Registry).DeleteFn = func(string) error {
+			return nil
+		}
+		srv := httptest.NewServer(lobbyHttp.NewHandler(registry, nil, nil, nil, log.New(log.Output(ioutil.Discard))))
+		defer srv.Close()
+
+		conn := dialWebsocket(t, srv, "/v1/topics/topic/ws")
+		defer conn.Close()
+
+		err := registry.Delete("topic")
+		require.NoError(t, err)
+
+		_, _, err = conn.ReadMessage()
+		require.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway))
+	})
+}
@@ -0,0 +1,87 @@
+package log
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+
+	"github.com/asdine/lobby"
+)
+
+type contextKey struct{}
+
+// scope holds the fields of a request. It is shared by all the holders of the context
+// so that fields added deep in the call stack are visible to the caller.
+type scope struct {
+	mu     sync.Mutex
+	fields Fields
+}
+
+// NewContext returns a context holding request-scoped fields, in addition to the ones of ctx.
+func NewContext(ctx context.Context, fields Fields) context.Context {
+	s := scope{
+		fields: FieldsFromContext(ctx),
+	}
+
+	for k, v := range fields {
+		s.fields[k] = v
+	}
+
+	return context.WithValue(ctx, contextKey{}, &s)
+}
+
+// AddFields adds fields to the request-scoped fields of ctx. It does nothing if ctx
+// wasn't returned by NewContext.
+func AddFields(ctx context.Context, fields Fields) {
+	s, ok := ctx.Value(contextKey{}).(*scope)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, v := range fields {
+		s.fields[k] = v
+	}
+}
+
+// FieldsFromContext returns a copy of the request-scoped fields of ctx.
+func FieldsFromContext(ctx context.Context) Fields {
+	fields := make(Fields)
+
+	s, ok := ctx.Value(contextKey{}).(*scope)
+	if !ok {
+		return fields
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, v := range s.fields {
+		fields[k] = v
+	}
+
+	return fields
+}
+
+// WithContext returns a logger adding the request-scoped fields of ctx to every entry.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	return l.WithFields(FieldsFromContext(ctx))
+}
+
+// RequestID returns the id of the request stored in the fields of ctx, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := FieldsFromContext(ctx)[RequestIDKey].(string)
+	return id
+}
+
+// RequestIDKey is the field holding the id of a request.
+const RequestIDKey = "request_id"
+
+// NewRequestID returns a random request id.
+func NewRequestID() string {
+	id := make([]byte, 8)
+	lobby.RandomID(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,47 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	stdlog "log"
+	"testing"
+
+	"github.com/asdine/lobby/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	require.Empty(t, log.FieldsFromContext(ctx))
+	require.Empty(t, log.RequestID(ctx))
+
+	// fields can't be added to a context without scope.
+	log.AddFields(ctx, log.Fields{"topic": "quotes"})
+	require.Empty(t, log.FieldsFromContext(ctx))
+
+	ctx = log.NewContext(ctx, log.Fields{log.RequestIDKey: "abc"})
+	require.Equal(t, "abc", log.RequestID(ctx))
+
+	// fields added by callees are visible to the holders of the context.
+	child, cancel := context.WithCancel(ctx)
+	defer cancel()
+	log.AddFields(child, log.Fields{"topic": "quotes"})
+	require.Equal(t, log.Fields{log.RequestIDKey: "abc", "topic": "quotes"}, log.FieldsFromContext(ctx))
+
+	// nested scopes inherit the fields of their parent without modifying them.
+	nested := log.NewContext(ctx, log.Fields{"backend": "redis"})
+	require.Equal(t, log.Fields{log.RequestIDKey: "abc", "topic": "quotes", "backend": "redis"}, log.FieldsFromContext(nested))
+	require.Len(t, log.FieldsFromContext(ctx), 2)
+
+	var buff bytes.Buffer
+	stdlog.SetFlags(0)
+	logger := log.New(log.Output(&buff))
+	logger.WithContext(ctx).Println("message")
+	require.Equal(t, "i | message request_id=abc topic=quotes\n", buff.String())
+}
+
+func TestNewRequestID(t *testing.T) {
+	id := log.NewRequestID()
+	require.Len(t, id, 16)
+	require.NotEqual(t, id, log.NewRequestID())
+}
@@ -1,15 +1,33 @@
 package log
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"os"
+	"sort"
+	"strings"
+	"time"
 )
 
+// Output formats.
+const (
+	TextFormat = "text"
+	JSONFormat = "json"
+)
+
+// Fields are key-value pairs attached to log entries.
+type Fields map[string]interface{}
+
 type Logger struct {
 	prefix       string
 	logger       *log.Logger
+	out          io.Writer
 	debugEnabled bool
+	json         bool
+	fields       Fields
 }
 
 func New(opts ...func(*Logger)) *Logger {
@@ -20,7 +38,17 @@ func New(opts ...func(*Logger)) *Logger {
 	}
 
 	if l.logger == nil {
-		Output(os.Stderr)(&l)
+		if l.out == nil {
+			l.out = os.Stderr
+		}
+
+		// JSON entries contain their own timestamp.
+		flags := log.Flags()
+		if l.json {
+			flags = 0
+		}
+
+		l.logger = log.New(l.out, "", flags)
 	}
 
 	return &l
@@ -40,7 +68,8 @@ func Debug(debug bool) func(*Logger) {
 
 func Output(out io.Writer) func(*Logger) {
 	return func(l *Logger) {
-		l.logger = log.New(out, "", log.Flags())
+		l.out = out
+		l.logger = nil
 	}
 }
 
@@ -50,32 +79,134 @@ func StdLogger(lg *log.Logger) func(*Logger) {
 	}
 }
 
+// Format selects the output format, TextFormat or JSONFormat. Unknown formats fall back to TextFormat.
+func Format(format string) func(*Logger) {
+	return func(l *Logger) {
+		l.json = format == JSONFormat
+	}
+}
+
+// WithFields returns a logger adding the fields to every entry, in addition to the fields of l.
+func (l *Logger) WithFields(fields Fields) *Logger {
+	child := *l
+	child.fields = make(Fields, len(l.fields)+len(fields))
+
+	for k, v := range l.fields {
+		child.fields[k] = v
+	}
+
+	for k, v := range fields {
+		child.fields[k] = v
+	}
+
+	return &child
+}
+
+// With returns a logger adding the key-value pair to every entry.
+func (l *Logger) With(key string, value interface{}) *Logger {
+	return l.WithFields(Fields{key: value})
+}
+
 func (l *Logger) Println(v ...interface{}) {
 	l.leveledPrintln("i |", v...)
 }
 
 func (l *Logger) leveledPrintln(level string, v ...interface{}) {
+	l.output(level, fmt.Sprintln(v...))
+}
+
+func (l *Logger) leveledPrintf(level string, format string, v ...interface{}) {
+	l.output(level, fmt.Sprintf(format, v...))
+}
+
+// levels names the levels in JSON entries.
+var levels = map[string]string{
+	"d |": "debug",
+	"i |": "info",
+	"w |": "warn",
+	"e |": "error",
+}
+
+func (l *Logger) output(level, msg string) {
+	msg = strings.TrimSuffix(msg, "\n")
+
+	if l.json {
+		l.logger.Print(l.jsonEntry(levels[level], msg))
+		return
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(level)
 	if l.prefix != "" {
-		v = append([]interface{}{level, l.prefix}, v...)
-	} else {
-		v = append([]interface{}{level}, v...)
+		buf.WriteByte(' ')
+		buf.WriteString(l.prefix)
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(msg)
+
+	for _, k := range l.sortedKeys() {
+		fmt.Fprintf(&buf, " %s=%v", k, l.fields[k])
 	}
 
-	l.logger.Println(v...)
+	l.logger.Print(buf.String())
 }
 
-func (l *Logger) leveledPrintf(level string, format string, v ...interface{}) {
+func (l *Logger) jsonEntry(level, msg string) string {
+	var buf bytes.Buffer
+
+	write := func(key string, value interface{}) {
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(value)
+		if err != nil {
+			v, _ = json.Marshal(fmt.Sprint(value))
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+
+	buf.WriteByte('{')
+	write("time", time.Now().UTC().Format(time.RFC3339Nano))
+	write("level", level)
 	if l.prefix != "" {
-		format = level + l.prefix + " " + format
-	} else {
-		format = level + format
+		write("logger", strings.TrimSuffix(l.prefix, ":"))
+	}
+	write("msg", msg)
+
+	for _, k := range l.sortedKeys() {
+		switch k {
+		case "time", "level", "logger", "msg":
+			// reserved keys are never overwritten.
+			continue
+		}
+
+		write(k, l.fields[k])
 	}
+	buf.WriteByte('}')
 
-	l.logger.Printf(format, v...)
+	return buf.String()
+}
+
+func (l *Logger) sortedKeys() []string {
+	keys := make([]string, 0, len(l.fields))
+	for k := range l.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
 }
 
 func (l *Logger) Printf(format string, v ...interface{}) {
-	l.leveledPrintf("i | ", format, v...)
+	l.leveledPrintf("i |", format, v...)
 }
 
 func (l *Logger) Debug(v ...interface{}) {
@@ -91,5 +222,25 @@ func (l *Logger) Debugf(format string, v ...interface{}) {
 		return
 	}
 
-	l.leveledPrintf("d | ", format, v...)
+	l.leveledPrintf("d |", format, v...)
+}
+
+// Warn logs an unexpected event that doesn't prevent Lobby from working.
+func (l *Logger) Warn(v ...interface{}) {
+	l.leveledPrintln("w |", v...)
+}
+
+// Warnf logs an unexpected event that doesn't prevent Lobby from working.
+func (l *Logger) Warnf(format string, v ...interface{}) {
+	l.leveledPrintf("w |", format, v...)
+}
+
+// Error logs an operation that failed.
+func (l *Logger) Error(v ...interface{}) {
+	l.leveledPrintln("e |", v...)
+}
+
+// Errorf logs an operation that failed.
+func (l *Logger) Errorf(format string, v ...interface{}) {
+	l.leveledPrintf("e |", format, v...)
 }
@@ -2,9 +2,13 @@ package log_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"io/ioutil"
 	stdlog "log"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/asdine/lobby/log"
 	"github.com/stretchr/testify/require"
@@ -75,6 +79,70 @@ func TestLoggerDebug(t *testing.T) {
 	}
 }
 
+func TestLoggerLevels(t *testing.T) {
+	var buff bytes.Buffer
+	stdlog.SetFlags(0)
+	logger := log.New(log.Output(&buff), log.Prefix("prefix"))
+
+	logger.Warn("warning")
+	logger.Warnf("warning %d\n", 2)
+	logger.Error("failure")
+	logger.Errorf("failure %d", 2)
+	require.Equal(t, "w | prefix warning\nw | prefix warning 2\ne | prefix failure\ne | prefix failure 2\n", buff.String())
+}
+
+func TestLoggerFields(t *testing.T) {
+	var buff bytes.Buffer
+	stdlog.SetFlags(0)
+	logger := log.New(log.Output(&buff), log.Prefix("prefix"))
+
+	child := logger.WithFields(log.Fields{"topic": "quotes", "backend": "redis"})
+	child.With("request_id", "abc").Printf("message\n")
+	child.Println("message")
+	logger.Println("message")
+
+	require.Equal(t,
+		"i | prefix message backend=redis request_id=abc topic=quotes\n"+
+			"i | prefix message backend=redis topic=quotes\n"+
+			"i | prefix message\n",
+		buff.String(),
+	)
+}
+
+func TestLoggerJSON(t *testing.T) {
+	var buff bytes.Buffer
+	logger := log.New(
+		log.Output(&buff),
+		log.Prefix("http server:"),
+		log.Format(log.JSONFormat),
+		log.Debug(true),
+	)
+
+	logger.With("topic", "quotes").With("msg", "ignored").Errorf("failed: %s\n", errors.New("boom"))
+	logger.With("err", errors.New("boom")).Debug("message", 1)
+
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	_, err := time.Parse(time.RFC3339Nano, entry["time"].(string))
+	require.NoError(t, err)
+	delete(entry, "time")
+	require.Equal(t, map[string]interface{}{
+		"level":  "error",
+		"logger": "http server",
+		"msg":    "failed: boom",
+		"topic":  "quotes",
+	}, entry)
+
+	entry = nil
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	require.Equal(t, "debug", entry["level"])
+	require.Equal(t, "message 1", entry["msg"])
+	require.Equal(t, "boom", entry["err"])
+}
+
 func BenchmarkLog(b *testing.B) {
 	logger := log.New(log.Output(ioutil.Discard), log.Prefix("prefix"))
 	vs := make([]interface{}, 5)
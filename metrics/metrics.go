@@ -0,0 +1,408 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the default upper bounds of histogram buckets, suited to latencies in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// DefaultRegistry is the registry used by the package-level constructors.
+var DefaultRegistry = NewRegistry()
+
+// NewCounter creates a counter and registers it in the DefaultRegistry.
+func NewCounter(name, help string, labels ...string) *Counter {
+	return DefaultRegistry.NewCounter(name, help, labels...)
+}
+
+// NewGauge creates a gauge and registers it in the DefaultRegistry.
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return DefaultRegistry.NewGauge(name, help, labels...)
+}
+
+// NewHistogram creates a histogram and registers it in the DefaultRegistry.
+// If buckets is nil, DefaultBuckets are used.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return DefaultRegistry.NewHistogram(name, help, buckets, labels...)
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: make(map[string]metric),
+	}
+}
+
+// Registry holds a set of metrics and writes them in the Prometheus text format.
+type Registry struct {
+	m       sync.RWMutex
+	metrics map[string]metric
+}
+
+type metric interface {
+	write(w *bufio.Writer)
+}
+
+// NewCounter creates a counter and registers it. It panics if a metric with the same name exists.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := Counter{
+		family: newFamily(name, help, "counter", labels),
+	}
+
+	r.register(name, &c)
+	return &c
+}
+
+// NewGauge creates a gauge and registers it. It panics if a metric with the same name exists.
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := Gauge{
+		family: newFamily(name, help, "gauge", labels),
+	}
+
+	r.register(name, &g)
+	return &g
+}
+
+// NewHistogram creates a histogram and registers it. It panics if a metric with the same name exists.
+// If buckets is nil, DefaultBuckets are used.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+
+	h := Histogram{
+		family:  newFamily(name, help, "histogram", labels),
+		buckets: append([]float64(nil), buckets...),
+	}
+	sort.Float64s(h.buckets)
+
+	r.register(name, &h)
+	return &h
+}
+
+func (r *Registry) register(name string, m metric) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if _, ok := r.metrics[name]; ok {
+		panic(fmt.Sprintf("metric %s registered twice", name))
+	}
+
+	r.metrics[name] = m
+}
+
+// WriteTo writes all the metrics to w in the Prometheus text format, sorted by name.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.m.RLock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	r.m.RUnlock()
+	sort.Strings(names)
+
+	cw := countWriter{w: w}
+	bw := bufio.NewWriter(&cw)
+
+	for _, name := range names {
+		r.m.RLock()
+		m := r.metrics[name]
+		r.m.RUnlock()
+
+		m.write(bw)
+	}
+
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// Handler returns an HTTP handler serving the metrics of the registry.
+func Handler(r *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteTo(w)
+	})
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// family contains what is common to every kind of metric.
+type family struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+
+	m      sync.Mutex
+	series map[string]interface{}
+	// label values of each series, indexed like series.
+	values map[string][]string
+}
+
+func newFamily(name, help, typ string, labels []string) family {
+	return family{
+		name:   name,
+		help:   help,
+		typ:    typ,
+		labels: labels,
+		series: make(map[string]interface{}),
+		values: make(map[string][]string),
+	}
+}
+
+// get returns the series associated with the label values, creating it with fn if it doesn't exist.
+// It must be called with the lock held.
+func (f *family) get(values []string, fn func() interface{}) interface{} {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = fn()
+		f.series[key] = s
+		f.values[key] = append([]string(nil), values...)
+	}
+
+	return s
+}
+
+// keys returns the keys of the series in a stable order. It must be called with the lock held.
+func (f *family) keys() []string {
+	keys := make([]string, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (f *family) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.typ)
+}
+
+// Counter is a set of values, one per combination of label values, that can only increase.
+type Counter struct {
+	family
+}
+
+// Inc increments the counter associated with the label values.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add v to the counter associated with the label values. It panics if v is negative.
+func (c *Counter) Add(v float64, values ...string) {
+	if v < 0 {
+		panic(fmt.Sprintf("counter %s can't decrease", c.name))
+	}
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	s := c.get(values, func() interface{} { return new(float64) }).(*float64)
+	*s += v
+}
+
+// Value returns the value of the counter associated with the label values.
+func (c *Counter) Value(values ...string) float64 {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	s, ok := c.series[strings.Join(values, "\xff")]
+	if !ok {
+		return 0
+	}
+
+	return *s.(*float64)
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.writeHeader(w)
+	for _, k := range c.keys() {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, c.values[k]), formatValue(*c.series[k].(*float64)))
+	}
+}
+
+// Gauge is a set of values, one per combination of label values, that can go up and down.
+type Gauge struct {
+	family
+}
+
+// Set the gauge associated with the label values to v.
+func (g *Gauge) Set(v float64, values ...string) {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	s := g.get(values, func() interface{} { return new(float64) }).(*float64)
+	*s = v
+}
+
+// Add v, which can be negative, to the gauge associated with the label values.
+func (g *Gauge) Add(v float64, values ...string) {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	s := g.get(values, func() interface{} { return new(float64) }).(*float64)
+	*s += v
+}
+
+// Value returns the value of the gauge associated with the label values.
+func (g *Gauge) Value(values ...string) float64 {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	s, ok := g.series[strings.Join(values, "\xff")]
+	if !ok {
+		return 0
+	}
+
+	return *s.(*float64)
+}
+
+func (g *Gauge) write(w *bufio.Writer) {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	g.writeHeader(w)
+	for _, k := range g.keys() {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, formatLabels(g.labels, g.values[k]), formatValue(*g.series[k].(*float64)))
+	}
+}
+
+// Histogram samples observations, like request durations, and counts them in buckets.
+type Histogram struct {
+	family
+
+	buckets []float64
+}
+
+type histogramSeries struct {
+	// number of observations in each bucket, not cumulative.
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Observe adds an observation to the histogram associated with the label values.
+func (h *Histogram) Observe(v float64, values ...string) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	s := h.get(values, func() interface{} {
+		return &histogramSeries{counts: make([]uint64, len(h.buckets))}
+	}).(*histogramSeries)
+
+	i := sort.SearchFloat64s(h.buckets, v)
+	if i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+// Count returns the number of observations of the histogram associated with the label values.
+func (h *Histogram) Count(values ...string) uint64 {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	s, ok := h.series[strings.Join(values, "\xff")]
+	if !ok {
+		return 0
+	}
+
+	return s.(*histogramSeries).count
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.writeHeader(w)
+
+	labels := append(append([]string(nil), h.labels...), "le")
+	for _, k := range h.keys() {
+		s := h.series[k].(*histogramSeries)
+		values := append(append([]string(nil), h.values[k]...), "")
+
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += s.counts[i]
+			values[len(values)-1] = formatValue(upper)
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(labels, values), cumulative)
+		}
+
+		values[len(values)-1] = "+Inf"
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(labels, values), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, h.values[k]), formatValue(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, h.values[k]), s.count)
+	}
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, names[i], escapeLabel(values[i]))
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,108 @@
+package metrics_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/asdine/lobby/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounter(t *testing.T) {
+	r := metrics.NewRegistry()
+	c := r.NewCounter("lobby_messages_total", "Number of messages.", "topic", "backend")
+
+	c.Inc("quotes", "bolt")
+	c.Add(2, "quotes", "bolt")
+	c.Inc("logs", "redis")
+
+	require.Equal(t, float64(3), c.Value("quotes", "bolt"))
+	require.Equal(t, float64(0), c.Value("other", "bolt"))
+
+	require.Panics(t, func() {
+		c.Add(-1, "quotes", "bolt")
+	})
+
+	require.Panics(t, func() {
+		c.Inc("quotes")
+	})
+
+	var buf bytes.Buffer
+	_, err := r.WriteTo(&buf)
+	require.NoError(t, err)
+	require.Equal(t, `# HELP lobby_messages_total Number of messages.
+# TYPE lobby_messages_total counter
+lobby_messages_total{topic="logs",backend="redis"} 1
+lobby_messages_total{topic="quotes",backend="bolt"} 3
+`, buf.String())
+}
+
+func TestGauge(t *testing.T) {
+	r := metrics.NewRegistry()
+	g := r.NewGauge("lobby_depth", "Depth.", "backend")
+
+	g.Set(5, "redis")
+	g.Add(-2, "redis")
+	g.Add(1, "nsq")
+
+	require.Equal(t, float64(3), g.Value("redis"))
+	require.Equal(t, float64(0), g.Value("other"))
+
+	var buf bytes.Buffer
+	_, err := r.WriteTo(&buf)
+	require.NoError(t, err)
+	require.Equal(t, `# HELP lobby_depth Depth.
+# TYPE lobby_depth gauge
+lobby_depth{backend="nsq"} 1
+lobby_depth{backend="redis"} 3
+`, buf.String())
+}
+
+func TestHistogram(t *testing.T) {
+	r := metrics.NewRegistry()
+	h := r.NewHistogram("lobby_duration_seconds", "Duration.", []float64{1, 0.1}, "method")
+
+	h.Observe(0.05, "send")
+	h.Observe(0.1, "send")
+	h.Observe(0.5, "send")
+	h.Observe(3, "send")
+
+	require.Equal(t, uint64(4), h.Count("send"))
+
+	var buf bytes.Buffer
+	_, err := r.WriteTo(&buf)
+	require.NoError(t, err)
+	require.Equal(t, `# HELP lobby_duration_seconds Duration.
+# TYPE lobby_duration_seconds histogram
+lobby_duration_seconds_bucket{method="send",le="0.1"} 2
+lobby_duration_seconds_bucket{method="send",le="1"} 3
+lobby_duration_seconds_bucket{method="send",le="+Inf"} 4
+lobby_duration_seconds_sum{method="send"} 3.65
+lobby_duration_seconds_count{method="send"} 4
+`, buf.String())
+}
+
+func TestRegistry(t *testing.T) {
+	r := metrics.NewRegistry()
+	r.NewCounter("b_total", "B.")
+	c := r.NewCounter("a_total", "Line\nbreak.", "label")
+	c.Inc(`quote"back\slash`)
+
+	require.Panics(t, func() {
+		r.NewCounter("a_total", "Again.")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	metrics.Handler(r).ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Equal(t, `# HELP a_total Line\nbreak.
+# TYPE a_total counter
+a_total{label="quote\"back\\slash"} 1
+# HELP b_total B.
+# TYPE b_total counter
+`, w.Body.String())
+}
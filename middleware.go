@@ -0,0 +1,86 @@
+package lobby
+
+import "context"
+
+// A Middleware wraps a topic to process the messages sent to it.
+// Middlewares are used to add cross-cutting logic, like enrichment or validation, to any topic.
+type Middleware func(Topic) Topic
+
+// Chain returns a middleware applying the given middlewares in order:
+// messages go through the first middleware first.
+func Chain(middlewares ...Middleware) Middleware {
+	return func(t Topic) Topic {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			t = middlewares[i](t)
+		}
+
+		return t
+	}
+}
+
+// MessageFunc creates a middleware calling fn on every message before sending it to the topic.
+// fn can modify the message, or return an error to reject it. The returned topics implement
+// BatchSender, and TopicReader if the wrapped topic does.
+func MessageFunc(fn func(context.Context, *Message) error) Middleware {
+	return func(t Topic) Topic {
+		mt := messageFuncTopic{Topic: t, fn: fn}
+		if tr, ok := t.(TopicReader); ok {
+			return &messageFuncTopicReader{messageFuncTopic: &mt, reader: tr}
+		}
+
+		return &mt
+	}
+}
+
+type messageFuncTopic struct {
+	Topic
+
+	fn func(context.Context, *Message) error
+}
+
+func (t *messageFuncTopic) Send(ctx context.Context, m *Message) error {
+	if err := t.fn(ctx, m); err != nil {
+		return err
+	}
+
+	return t.Topic.Send(ctx, m)
+}
+
+// SendBatch sends the messages accepted by fn in a single batch.
+func (t *messageFuncTopic) SendBatch(ctx context.Context, messages []*Message) ([]error, error) {
+	errs := make([]error, len(messages))
+	accepted := make([]*Message, 0, len(messages))
+	indexes := make([]int, 0, len(messages))
+
+	for i, m := range messages {
+		if errs[i] = t.fn(ctx, m); errs[i] == nil {
+			accepted = append(accepted, m)
+			indexes = append(indexes, i)
+		}
+	}
+
+	if len(accepted) == 0 {
+		return errs, nil
+	}
+
+	sendErrs, err := SendBatch(ctx, t.Topic, accepted)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := range indexes {
+		errs[j] = sendErrs[i]
+	}
+
+	return errs, nil
+}
+
+type messageFuncTopicReader struct {
+	*messageFuncTopic
+
+	reader TopicReader
+}
+
+func (t *messageFuncTopicReader) Read(ctx context.Context, group, cursor string, limit int) ([]Message, string, error) {
+	return t.reader.Read(ctx, group, cursor, limit)
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/asdine/lobby"
+	"github.com/pkg/errors"
+)
+
+// Default headers set by the enrichment middlewares.
+const (
+	DefaultTimestampHeader = "timestamp"
+	DefaultHostHeader      = "host"
+)
+
+// Config of a builtin middleware.
+type Config struct {
+	// Type of the middleware: "timestamp", "host", "max-size" or "rewrite".
+	Type string
+	// Header set by the timestamp and host middlewares.
+	Header string
+	// Maximum size of the values, in bytes, used by the max-size middleware.
+	Size int
+	// Regular expression matched against the values by the rewrite middleware,
+	// and its replacement which can refer to submatches, like $1.
+	Pattern     string
+	Replacement string
+}
+
+// New returns the builtin middleware described by cfg.
+func New(cfg Config) (lobby.Middleware, error) {
+	switch cfg.Type {
+	case "timestamp":
+		return Timestamp(cfg.Header), nil
+	case "host":
+		return Host(cfg.Header)
+	case "max-size":
+		if cfg.Size <= 0 {
+			return nil, errors.New("max-size middleware requires a positive size")
+		}
+
+		return MaxSize(cfg.Size), nil
+	case "rewrite":
+		re, err := regexp.Compile(cfg.Pattern)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid rewrite pattern")
+		}
+
+		return Rewrite(re, cfg.Replacement), nil
+	default:
+		return nil, errors.Errorf("unknown middleware type %q", cfg.Type)
+	}
+}
+
+// Timestamp sets the given header to the time at which the message is sent, in RFC 3339 format.
+// The header defaults to DefaultTimestampHeader.
+func Timestamp(header string) lobby.Middleware {
+	if header == "" {
+		header = DefaultTimestampHeader
+	}
+
+	return lobby.MessageFunc(func(ctx context.Context, m *lobby.Message) error {
+		setHeader(m, header, time.Now().UTC().Format(time.RFC3339Nano))
+		return nil
+	})
+}
+
+// Host sets the given header to the hostname of the machine running Lobby.
+// The header defaults to DefaultHostHeader.
+func Host(header string) (lobby.Middleware, error) {
+	if header == "" {
+		header = DefaultHostHeader
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get hostname")
+	}
+
+	return lobby.MessageFunc(func(ctx context.Context, m *lobby.Message) error {
+		setHeader(m, header, host)
+		return nil
+	}), nil
+}
+
+// MaxSize rejects the messages whose value is larger than size bytes with lobby.ErrMessageTooLarge.
+func MaxSize(size int) lobby.Middleware {
+	return lobby.MessageFunc(func(ctx context.Context, m *lobby.Message) error {
+		if len(m.Value) > size {
+			return lobby.ErrMessageTooLarge
+		}
+
+		return nil
+	})
+}
+
+// Rewrite replaces the matches of re in the values of the messages with replacement.
+// Inside replacement, $ signs are interpreted as in regexp.Regexp.Expand.
+func Rewrite(re *regexp.Regexp, replacement string) lobby.Middleware {
+	repl := []byte(replacement)
+
+	return lobby.MessageFunc(func(ctx context.Context, m *lobby.Message) error {
+		m.Value = re.ReplaceAll(m.Value, repl)
+		return nil
+	})
+}
+
+// setHeader sets a header on a copy of the headers of m, which may be shared.
+func setHeader(m *lobby.Message, key, value string) {
+	headers := make(map[string]string, len(m.Headers)+1)
+	for k, v := range m.Headers {
+		headers[k] = v
+	}
+
+	headers[key] = value
+	m.Headers = headers
+}
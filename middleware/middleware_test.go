@@ -0,0 +1,124 @@
+package middleware_test
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/middleware"
+	"github.com/asdine/lobby/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sink returns a topic saving the last message it received in m.
+func sink(m **lobby.Message) lobby.Topic {
+	return lobby.TopicFunc(func(ctx context.Context, message *lobby.Message) error {
+		*m = message
+		return nil
+	})
+}
+
+func TestTimestamp(t *testing.T) {
+	var m *lobby.Message
+	headers := map[string]string{"a": "b"}
+
+	err := middleware.Timestamp("")(sink(&m)).Send(context.Background(), lobby.NewMessage("", []byte("v"), headers))
+	require.NoError(t, err)
+
+	ts, err := time.Parse(time.RFC3339Nano, m.Headers[middleware.DefaultTimestampHeader])
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), ts, time.Second)
+	require.Equal(t, "b", m.Headers["a"])
+	// the headers of the caller are not modified.
+	require.Len(t, headers, 1)
+}
+
+func TestHost(t *testing.T) {
+	var m *lobby.Message
+
+	mw, err := middleware.Host("source")
+	require.NoError(t, err)
+
+	err = mw(sink(&m)).Send(context.Background(), lobby.NewMessage("", []byte("v"), nil))
+	require.NoError(t, err)
+
+	host, err := os.Hostname()
+	require.NoError(t, err)
+	require.Equal(t, host, m.Headers["source"])
+}
+
+func TestMaxSize(t *testing.T) {
+	var m *lobby.Message
+	tp := middleware.MaxSize(3)(sink(&m))
+
+	err := tp.Send(context.Background(), lobby.NewMessage("", []byte("abc"), nil))
+	require.NoError(t, err)
+
+	err = tp.Send(context.Background(), lobby.NewMessage("", []byte("abcd"), nil))
+	require.Equal(t, lobby.ErrMessageTooLarge, err)
+}
+
+func TestRewrite(t *testing.T) {
+	var m *lobby.Message
+	tp := middleware.Rewrite(regexp.MustCompile(`\d{12}(\d{4})`), "************$1")(sink(&m))
+
+	err := tp.Send(context.Background(), lobby.NewMessage("", []byte(`{"card": "4111111111111111"}`), nil))
+	require.NoError(t, err)
+	require.Equal(t, `{"card": "************1111"}`, string(m.Value))
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		cfg middleware.Config
+		ok  bool
+	}{
+		{middleware.Config{Type: "timestamp"}, true},
+		{middleware.Config{Type: "host", Header: "source"}, true},
+		{middleware.Config{Type: "max-size", Size: 10}, true},
+		{middleware.Config{Type: "max-size"}, false},
+		{middleware.Config{Type: "rewrite", Pattern: "a+", Replacement: "a"}, true},
+		{middleware.Config{Type: "rewrite", Pattern: "a("}, false},
+		{middleware.Config{Type: "compress"}, false},
+	}
+
+	for _, test := range tests {
+		mw, err := middleware.New(test.cfg)
+		if test.ok {
+			require.NoError(t, err, test.cfg.Type)
+			require.NotNil(t, mw)
+		} else {
+			require.Error(t, err, test.cfg.Type)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	var received *lobby.Message
+	var reg mock.Registry
+	reg.TopicFn = func(name string) (lobby.Topic, error) {
+		return sink(&received), nil
+	}
+
+	r := middleware.NewRegistry(&reg, map[string][]lobby.Middleware{
+		middleware.AllTopics: {middleware.Timestamp("")},
+		"quotes":             {middleware.MaxSize(3)},
+	})
+
+	tp, err := r.Topic("quotes")
+	require.NoError(t, err)
+	err = tp.Send(context.Background(), lobby.NewMessage("", []byte("abcd"), nil))
+	require.Equal(t, lobby.ErrMessageTooLarge, err)
+
+	err = tp.Send(context.Background(), lobby.NewMessage("", []byte("abc"), nil))
+	require.NoError(t, err)
+	require.Contains(t, received.Headers, middleware.DefaultTimestampHeader)
+
+	tp, err = r.Topic("other")
+	require.NoError(t, err)
+	err = tp.Send(context.Background(), lobby.NewMessage("", []byte("abcd"), nil))
+	require.NoError(t, err)
+	require.Contains(t, received.Headers, middleware.DefaultTimestampHeader)
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/asdine/lobby"
+)
+
+// AllTopics is the name under which the chain applied to every topic is registered.
+const AllTopics = "*"
+
+var _ lobby.Registry = new(Registry)
+var _ lobby.Migrator = new(Registry)
+
+// NewRegistry returns a registry applying middlewares to the topics of r. Chains are indexed
+// by topic name, the chain indexed by AllTopics applies to every topic before its own chain.
+func NewRegistry(r lobby.Registry, chains map[string][]lobby.Middleware) *Registry {
+	return &Registry{
+		Registry: r,
+		chains:   chains,
+	}
+}
+
+// Registry is a registry processing the messages of its topics with middlewares.
+type Registry struct {
+	lobby.Registry
+
+	chains map[string][]lobby.Middleware
+}
+
+// Topic returns the selected topic from the underlying registry, wrapped by its chain of middlewares.
+func (r *Registry) Topic(name string) (lobby.Topic, error) {
+	t, err := r.Registry.Topic(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var chain []lobby.Middleware
+	chain = append(chain, r.chains[AllTopics]...)
+	chain = append(chain, r.chains[name]...)
+	if len(chain) == 0 {
+		return t, nil
+	}
+
+	return lobby.Chain(chain...)(t), nil
+}
+
+// Migrate a topic to another backend along with its messages,
+// if the underlying registry is a lobby.Migrator.
+func (r *Registry) Migrate(ctx context.Context, topicName, backendName string, options map[string]string) error {
+	m, ok := r.Registry.(lobby.Migrator)
+	if !ok {
+		return lobby.ErrNotSupported
+	}
+
+	return m.Migrate(ctx, topicName, backendName, options)
+}
@@ -0,0 +1,85 @@
+package lobby_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+	mw := func(name string) lobby.Middleware {
+		return lobby.MessageFunc(func(ctx context.Context, m *lobby.Message) error {
+			calls = append(calls, name)
+			return nil
+		})
+	}
+
+	tp := lobby.Chain(mw("a"), mw("b"), mw("c"))(lobby.TopicFunc(func(ctx context.Context, m *lobby.Message) error {
+		calls = append(calls, "topic")
+		return nil
+	}))
+
+	err := tp.Send(context.Background(), &lobby.Message{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c", "topic"}, calls)
+}
+
+func TestMessageFunc(t *testing.T) {
+	errRejected := errors.New("rejected")
+	mw := lobby.MessageFunc(func(ctx context.Context, m *lobby.Message) error {
+		if string(m.Value) == "bad" {
+			return errRejected
+		}
+
+		m.Value = append(m.Value, '!')
+		return nil
+	})
+
+	t.Run("Send", func(t *testing.T) {
+		var tp mock.Topic
+		tp.SendFn = func(ctx context.Context, m *lobby.Message) error {
+			require.Equal(t, "a!", string(m.Value))
+			return nil
+		}
+
+		wrapped := mw(&tp)
+		_, ok := wrapped.(lobby.TopicReader)
+		require.True(t, ok)
+
+		require.NoError(t, wrapped.Send(context.Background(), &lobby.Message{Value: []byte("a")}))
+		require.Equal(t, errRejected, wrapped.Send(context.Background(), &lobby.Message{Value: []byte("bad")}))
+		require.Equal(t, 1, tp.SendInvoked)
+	})
+
+	t.Run("SendBatch", func(t *testing.T) {
+		var tp mock.Topic
+		tp.SendBatchFn = func(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+			require.Len(t, messages, 2)
+			require.Equal(t, "a!", string(messages[0].Value))
+			require.Equal(t, "b!", string(messages[1].Value))
+			return []error{nil, lobby.ErrTopicNotFound}, nil
+		}
+
+		errs, err := lobby.SendBatch(context.Background(), mw(&tp), []*lobby.Message{
+			{Value: []byte("a")},
+			{Value: []byte("bad")},
+			{Value: []byte("b")},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []error{nil, errRejected, lobby.ErrTopicNotFound}, errs)
+	})
+
+	t.Run("NotReader", func(t *testing.T) {
+		wrapped := mw(lobby.TopicFunc(func(ctx context.Context, m *lobby.Message) error {
+			return nil
+		}))
+
+		_, ok := wrapped.(lobby.TopicReader)
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,19 @@
+package mirror
+
+import (
+	"github.com/asdine/lobby/metrics"
+)
+
+var (
+	sendErrors = metrics.NewCounter(
+		"lobby_mirror_send_errors_total",
+		"Number of messages a backend of a mirrored topic failed to store, by topic and backend.",
+		"topic", "backend",
+	)
+
+	migratedMessages = metrics.NewCounter(
+		"lobby_mirror_migrated_messages_total",
+		"Number of messages copied to another backend by topic migrations, by topic.",
+		"topic",
+	)
+)
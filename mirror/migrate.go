@@ -0,0 +1,215 @@
+package mirror
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/validation"
+	"github.com/pkg/errors"
+)
+
+var _ lobby.Migrator = new(Registry)
+
+// migrationBatchSize is the number of messages read and copied at once during a migration.
+const migrationBatchSize = 100
+
+// Migrate rebinds a topic to another backend, after copying the messages stored by its current backend.
+// During the copy, the new backend is added to the mirrors of the topic so that the messages sent in
+// the meantime are stored by both backends, and only the messages stored by the current backend
+// before the new one was added are copied. If the copy fails, or if no message of a non-empty topic
+// could be copied, the topic is bound back to its current backend.
+func (r *Registry) Migrate(ctx context.Context, topicName, backendName string, options map[string]string) error {
+	info, err := r.Registry.Info(topicName)
+	if err != nil {
+		return err
+	}
+
+	if info.Backend == backendName {
+		return validation.AddError(nil, "backend", errors.New("is the backend of the topic"))
+	}
+
+	source, ok := r.backend(info.Backend)
+	if !ok {
+		return lobby.ErrBackendNotFound
+	}
+
+	target, ok := r.backend(backendName)
+	if !ok {
+		return lobby.ErrBackendNotFound
+	}
+
+	current, err := parseOptions(info.Options)
+	if err != nil {
+		return err
+	}
+
+	// the final options are checked before anything is changed.
+	final, err := r.canonical(backendName, options)
+	if err != nil {
+		return err
+	}
+
+	next, err := parseOptions(final)
+	if err != nil {
+		return err
+	}
+
+	src, err := source.Topic(topicName, current.options)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	reader, ok := src.(lobby.TopicReader)
+	if !ok {
+		return lobby.ErrNotSupported
+	}
+
+	dst, err := target.Topic(topicName, next.options)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	mirrors := []string{backendName}
+	for _, name := range current.mirrors {
+		if name != backendName {
+			mirrors = append(mirrors, name)
+		}
+	}
+
+	dual := make(map[string]string, len(info.Options)+3)
+	for k, v := range info.Options {
+		dual[k] = v
+	}
+	dual[MirrorsOption] = strings.Join(mirrors, ",")
+	dual[AckOption] = AckAll
+
+	values := make(url.Values, len(next.options))
+	for k, v := range next.options {
+		values.Set(k, v)
+	}
+	dual[MigrationOption] = backendName + "?" + values.Encode()
+
+	logger := r.logger.WithFields(log.Fields{"topic": topicName, "from": info.Backend, "to": backendName})
+
+	// the end of the topic is recorded before the rebind, the following messages are sent to both backends.
+	end, err := scanMessages(ctx, reader)
+	if err != nil {
+		return err
+	}
+
+	err = r.Registry.Rebind(topicName, info.Backend, dual)
+	if err != nil {
+		return err
+	}
+	logger.Printf("Migrating topic %s from %s to %s\n", topicName, info.Backend, backendName)
+
+	n, err := copyMessages(ctx, reader, dst, end)
+	if err == nil && n == 0 && end.count() > 0 {
+		err = errors.Errorf("none of the %d messages of the topic could be read", end.count())
+	}
+	migratedMessages.Add(float64(n), topicName)
+	if err == nil {
+		err = r.Registry.Rebind(topicName, backendName, final)
+	}
+
+	if err != nil {
+		logger.Errorf("Migration of topic %s failed after copying %d messages: %s\n", topicName, n, err)
+
+		// the messages copied so far are left in the new backend.
+		if rerr := r.Registry.Rebind(topicName, info.Backend, info.Options); rerr != nil {
+			logger.Errorf("Failed to restore topic %s: %s\n", topicName, rerr)
+		}
+
+		return err
+	}
+
+	logger.Printf("Migrated topic %s to %s, %d messages copied\n", topicName, backendName, n)
+	return nil
+}
+
+// position is the end of a topic: the cursors of its pages, and the number of messages of the last one.
+type position struct {
+	cursors []string
+	last    int
+}
+
+// count returns the number of messages up to the position.
+func (p *position) count() int {
+	if len(p.cursors) == 0 {
+		return 0
+	}
+
+	return (len(p.cursors)-1)*migrationBatchSize + p.last
+}
+
+// scanMessages reads src up to its last message and returns the position of the end of the topic.
+func scanMessages(ctx context.Context, src lobby.TopicReader) (*position, error) {
+	var p position
+	var cursor string
+
+	for {
+		list, next, err := src.Read(ctx, "", cursor, migrationBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		p.cursors = append(p.cursors, cursor)
+		p.last = len(list)
+		if next == "" {
+			return &p, nil
+		}
+
+		cursor = next
+	}
+}
+
+// copyMessages copies the messages of src to dst, up to the given position.
+// The pages recorded in the position are read again, so that messages sent after it are not copied.
+// It returns the number of messages copied.
+func copyMessages(ctx context.Context, src lobby.TopicReader, dst lobby.Topic, end *position) (int, error) {
+	var count int
+
+	for i, cursor := range end.cursors {
+		limit := migrationBatchSize
+		if i == len(end.cursors)-1 {
+			limit = end.last
+		}
+
+		// a zero limit would read every remaining message.
+		if limit == 0 {
+			break
+		}
+
+		list, _, err := src.Read(ctx, "", cursor, limit)
+		if err != nil {
+			return count, err
+		}
+
+		batch := make([]*lobby.Message, len(list))
+		for i := range list {
+			batch[i] = &list[i]
+		}
+
+		if len(batch) > 0 {
+			errs, err := lobby.SendBatch(ctx, dst, batch)
+			if err != nil {
+				return count, err
+			}
+
+			for i := range errs {
+				if errs[i] != nil {
+					return count, errors.Wrapf(errs[i], "failed to copy message %d", count+i)
+				}
+			}
+
+			count += len(batch)
+		}
+	}
+
+	return count, nil
+}
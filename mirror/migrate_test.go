@@ -0,0 +1,206 @@
+package mirror_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/mirror"
+	"github.com/asdine/lobby/mock"
+	"github.com/asdine/lobby/validation"
+	"github.com/stretchr/testify/require"
+)
+
+// journal is a backend storing messages in memory and able to read them back.
+// onRead is called before each read.
+type journal struct {
+	messages []lobby.Message
+	onRead   func()
+}
+
+func (l *journal) Topic(name string, options map[string]string) (lobby.Topic, error) {
+	return &mock.Topic{
+		SendFn: func(ctx context.Context, m *lobby.Message) error {
+			l.messages = append(l.messages, *m)
+			return nil
+		},
+		ReadFn: func(ctx context.Context, group, cursor string, limit int) ([]lobby.Message, string, error) {
+			if l.onRead != nil {
+				l.onRead()
+			}
+
+			start, _ := strconv.Atoi(cursor)
+			if start > len(l.messages) {
+				start = len(l.messages)
+			}
+			end := start + limit
+			if end >= len(l.messages) {
+				return l.messages[start:], "", nil
+			}
+
+			return l.messages[start:end], strconv.Itoa(end), nil
+		},
+	}, nil
+}
+
+func (l *journal) Close() error {
+	return nil
+}
+
+func TestRegistryMigrate(t *testing.T) {
+	setup := func(t *testing.T) (*mirror.Registry, *journal, *store) {
+		src, dst := new(journal), new(store)
+
+		r, _ := newRegistry(map[string]*store{"redis": dst})
+		r.RegisterBackend("bolt", src)
+
+		err := r.Create("bolt", "a", map[string]string{"max-age": "1h"})
+		require.NoError(t, err)
+
+		for i := 0; i < 250; i++ {
+			m := lobby.NewMessage("", []byte(strconv.Itoa(i)), nil)
+			m.CreatedAt = time.Now().Add(-time.Minute)
+			src.messages = append(src.messages, *m)
+		}
+
+		return r, src, dst
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		r, src, dst := setup(t)
+
+		// stored before the migration with the clock of another host, it must be copied.
+		skewed := lobby.NewMessage("", []byte("skewed"), nil)
+		skewed.CreatedAt = time.Now().Add(time.Hour)
+		src.messages = append(src.messages, *skewed)
+
+		// stored by a version of Lobby that didn't assign ids, it must be copied.
+		legacy := lobby.Message{Value: []byte("legacy")}
+		src.messages = append(src.messages, legacy)
+
+		// the end of the topic is read before the rebind, then the messages are copied.
+		src.onRead = func() {
+			info, err := r.Info("a")
+			require.NoError(t, err)
+			if info.Options[mirror.MigrationOption] == "" {
+				return
+			}
+			src.onRead = nil
+
+			require.Equal(t, map[string]string{
+				"max-age":              "1h",
+				mirror.MirrorsOption:   "redis",
+				mirror.AckOption:       mirror.AckAll,
+				mirror.MigrationOption: "redis?key=a",
+			}, info.Options)
+
+			// messages sent during the migration are stored by both backends,
+			// the new one receives the options of the topic once migrated.
+			require.NoError(t, send(t, r, "a", "live"))
+			require.Equal(t, map[string]string{"key": "a"}, dst.options)
+		}
+
+		err := r.Migrate(context.Background(), "a", "redis", map[string]string{"key": "a"})
+		require.NoError(t, err)
+
+		info, err := r.Info("a")
+		require.NoError(t, err)
+		require.Equal(t, "redis", info.Backend)
+		require.Equal(t, map[string]string{"key": "a"}, info.Options)
+		require.Equal(t, map[string]string{"key": "a"}, dst.options)
+
+		// the message sent during the copy is not copied again.
+		received := dst.messages()
+		require.Len(t, received, 253)
+		require.Equal(t, "live", received[0])
+		require.Equal(t, "0", received[1])
+		require.Equal(t, "249", received[250])
+		require.Equal(t, "skewed", received[251])
+		require.Equal(t, "legacy", received[252])
+		require.Equal(t, "live", string(src.messages[len(src.messages)-1].Value))
+	})
+
+	t.Run("CopyFailed", func(t *testing.T) {
+		r, _, dst := setup(t)
+		dst.err = errors.New("unavailable")
+
+		err := r.Migrate(context.Background(), "a", "redis", nil)
+		require.Error(t, err)
+
+		// the topic is bound back to its backend.
+		info, err := r.Info("a")
+		require.NoError(t, err)
+		require.Equal(t, "bolt", info.Backend)
+		require.Equal(t, map[string]string{"max-age": "1h"}, info.Options)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		r, src, dst := setup(t)
+		src.messages = nil
+
+		err := r.Migrate(context.Background(), "a", "redis", nil)
+		require.NoError(t, err)
+
+		info, err := r.Info("a")
+		require.NoError(t, err)
+		require.Equal(t, "redis", info.Backend)
+		require.Empty(t, dst.messages())
+	})
+
+	t.Run("NothingCopied", func(t *testing.T) {
+		r, src, _ := setup(t)
+
+		// the messages disappear once the end of the topic is recorded.
+		src.onRead = func() {
+			info, err := r.Info("a")
+			require.NoError(t, err)
+			if info.Options[mirror.MigrationOption] != "" {
+				src.messages = nil
+			}
+		}
+
+		err := r.Migrate(context.Background(), "a", "redis", nil)
+		require.Error(t, err)
+
+		info, err := r.Info("a")
+		require.NoError(t, err)
+		require.Equal(t, "bolt", info.Backend)
+	})
+
+	t.Run("NotReader", func(t *testing.T) {
+		r, _, _ := setup(t)
+		r.RegisterBackend("nsq", &mock.Backend{
+			TopicFn: func(name string, options map[string]string) (lobby.Topic, error) {
+				return lobby.TopicFunc(func(ctx context.Context, m *lobby.Message) error {
+					return nil
+				}), nil
+			},
+		})
+
+		err := r.Rebind("a", "nsq", nil)
+		require.NoError(t, err)
+
+		err = r.Migrate(context.Background(), "a", "redis", nil)
+		require.Equal(t, lobby.ErrNotSupported, err)
+
+		info, err := r.Info("a")
+		require.NoError(t, err)
+		require.Equal(t, "nsq", info.Backend)
+	})
+
+	t.Run("InvalidBackend", func(t *testing.T) {
+		r, _, _ := setup(t)
+
+		err := r.Migrate(context.Background(), "a", "bolt", nil)
+		require.True(t, validation.IsError(err))
+
+		err = r.Migrate(context.Background(), "a", "mongo", nil)
+		require.Equal(t, lobby.ErrBackendNotFound, err)
+
+		err = r.Migrate(context.Background(), "b", "redis", nil)
+		require.Equal(t, lobby.ErrTopicNotFound, err)
+	})
+}
@@ -0,0 +1,286 @@
+package mirror
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/validation"
+	"github.com/pkg/errors"
+)
+
+// Topic options configuring the mirrors of a topic. They are never given to the backends.
+const (
+	// MirrorsOption is the comma separated list of backends the messages are sent to,
+	// in addition to the backend of the topic.
+	MirrorsOption = "mirrors"
+	// AckOption is the acknowledgement policy of the topic. Defaults to AckAll.
+	AckOption = "mirror-ack"
+	// MigrationOption is set while the topic is migrated to one of its mirrors, to the name of
+	// that backend followed by the options of the topic on that backend, in URL query format,
+	// like "redis?key=quotes".
+	MigrationOption = "migration"
+)
+
+// Acknowledgement policies, deciding whether a message is sent depending on which backends stored it.
+const (
+	// AckAll requires every backend to store the message.
+	AckAll = "all"
+	// AckAny requires at least one backend to store the message.
+	AckAny = "any"
+	// AckPrimary only requires the backend of the topic to store the message.
+	// Failures of the mirrors are reported but ignored.
+	AckPrimary = "primary-only"
+)
+
+var _ lobby.Registry = new(Registry)
+
+// NewRegistry returns a Registry whose topics can send their messages to several backends.
+// The mirrors of a topic are stored in its options, so any registry can be used.
+func NewRegistry(r lobby.Registry, logger *log.Logger) *Registry {
+	return &Registry{
+		Registry: r,
+		logger:   logger,
+		backends: make(map[string]lobby.Backend),
+	}
+}
+
+// Registry is a registry able to mirror the messages of its topics to several backends.
+type Registry struct {
+	lobby.Registry
+
+	logger   *log.Logger
+	mu       sync.RWMutex
+	backends map[string]lobby.Backend
+}
+
+// RegisterBackend registers the backend in the underlying registry. Topics opened by the
+// backend are mirrored to the backends listed in their options.
+func (r *Registry) RegisterBackend(name string, backend lobby.Backend) {
+	r.mu.Lock()
+	r.backends[name] = backend
+	r.mu.Unlock()
+
+	r.Registry.RegisterBackend(name, &mirroredBackend{Backend: backend, name: name, registry: r})
+}
+
+// Create a topic in the underlying registry, after making sure its mirrors exist.
+func (r *Registry) Create(backendName, topicName string, options map[string]string) error {
+	options, err := r.canonical(backendName, options)
+	if err != nil {
+		return err
+	}
+
+	return r.Registry.Create(backendName, topicName, options)
+}
+
+// Rebind a topic to another backend in the underlying registry, after making sure its mirrors exist.
+func (r *Registry) Rebind(topicName, backendName string, options map[string]string) error {
+	options, err := r.canonical(backendName, options)
+	if err != nil {
+		return err
+	}
+
+	return r.Registry.Rebind(topicName, backendName, options)
+}
+
+// canonical checks the mirrors of a topic bound to the given backend and returns
+// its options in the form they are stored.
+func (r *Registry) canonical(backendName string, options map[string]string) (map[string]string, error) {
+	cfg, err := parseOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.mirrors) == 0 {
+		return options, nil
+	}
+
+	for _, name := range cfg.mirrors {
+		if name == backendName {
+			return nil, validation.AddError(nil, MirrorsOption, errors.Errorf("%s is the backend of the topic", name))
+		}
+
+		if _, ok := r.backend(name); !ok {
+			return nil, lobby.ErrBackendNotFound
+		}
+	}
+
+	stored := make(map[string]string, len(options))
+	for k, v := range options {
+		stored[k] = v
+	}
+	stored[MirrorsOption] = strings.Join(cfg.mirrors, ",")
+	stored[AckOption] = cfg.ack
+
+	return stored, nil
+}
+
+func (r *Registry) backend(name string) (lobby.Backend, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	b, ok := r.backends[name]
+	return b, ok
+}
+
+// config of a mirrored topic.
+type config struct {
+	mirrors []string
+	ack     string
+	// options given to the backends.
+	options map[string]string
+	// backend the topic is migrated to, and the options given to it.
+	migration        string
+	migrationOptions map[string]string
+}
+
+// targetOptions returns the options given to the selected backend.
+func (c *config) targetOptions(backend string) map[string]string {
+	if backend == c.migration {
+		return c.migrationOptions
+	}
+
+	return c.options
+}
+
+// parseOptions reads the mirrors of a topic from its options.
+func parseOptions(options map[string]string) (*config, error) {
+	cfg := config{
+		ack:     AckAll,
+		options: options,
+	}
+
+	mirrors, hasMirrors := options[MirrorsOption]
+	ack, hasAck := options[AckOption]
+	migration, hasMigration := options[MigrationOption]
+	if !hasMirrors && !hasAck && !hasMigration {
+		return &cfg, nil
+	}
+
+	cfg.options = make(map[string]string, len(options))
+	for k, v := range options {
+		if k != MirrorsOption && k != AckOption && k != MigrationOption {
+			cfg.options[k] = v
+		}
+	}
+
+	if hasMigration {
+		parts := strings.SplitN(migration, "?", 2)
+		cfg.migration = strings.TrimSpace(parts[0])
+		cfg.migrationOptions = make(map[string]string)
+
+		if len(parts) == 2 {
+			values, err := url.ParseQuery(parts[1])
+			if err != nil {
+				return nil, validation.AddError(nil, MigrationOption, errors.New("invalid options"))
+			}
+
+			for k := range values {
+				cfg.migrationOptions[k] = values.Get(k)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(mirrors, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		cfg.mirrors = append(cfg.mirrors, name)
+	}
+
+	if hasAck {
+		cfg.ack = strings.TrimSpace(ack)
+	}
+
+	switch cfg.ack {
+	case AckAll, AckAny, AckPrimary:
+	default:
+		return nil, validation.AddError(nil, AckOption, errors.Errorf("must be one of %s, %s or %s", AckAll, AckAny, AckPrimary))
+	}
+
+	return &cfg, nil
+}
+
+// mirroredBackend opens the topics of a backend along with their mirrors.
+type mirroredBackend struct {
+	lobby.Backend
+
+	name     string
+	registry *Registry
+}
+
+// Topic returns the topic of the backend, sending its messages to its mirrors too.
+func (b *mirroredBackend) Topic(name string, options map[string]string) (lobby.Topic, error) {
+	cfg, err := parseOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.mirrors) == 0 {
+		return b.Backend.Topic(name, cfg.options)
+	}
+
+	primary, err := b.Backend.Topic(name, cfg.options)
+	if err != nil {
+		return nil, err
+	}
+
+	t := topic{
+		name:    name,
+		ack:     cfg.ack,
+		logger:  b.registry.logger.With("topic", name),
+		targets: []target{{backend: b.name, topic: primary}},
+	}
+
+	for _, m := range cfg.mirrors {
+		tg := target{backend: m}
+
+		// a missing mirror fails every message, the acknowledgement policy decides
+		// whether the topic can still be used.
+		bck, ok := b.registry.backend(m)
+		if !ok {
+			tg.err = lobby.ErrBackendNotFound
+		} else {
+			tg.topic, tg.err = bck.Topic(name, cfg.targetOptions(m))
+		}
+
+		t.targets = append(t.targets, tg)
+	}
+
+	for _, tg := range t.targets {
+		if tr, ok := tg.topic.(lobby.TopicReader); ok {
+			return &topicReader{topic: &t, reader: tr}, nil
+		}
+	}
+
+	return &t, nil
+}
+
+// Error reports the backends that failed to store a message.
+type Error struct {
+	// Errors indexed by backend name.
+	Errors map[string]error
+}
+
+func (e *Error) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := make([]string, len(names))
+	for i, name := range names {
+		list[i] = name + ": " + e.Errors[name].Error()
+	}
+
+	return "mirrored send failed: " + strings.Join(list, "; ")
+}
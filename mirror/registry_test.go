@@ -0,0 +1,290 @@
+package mirror_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/metrics"
+	"github.com/asdine/lobby/mirror"
+	"github.com/asdine/lobby/mock"
+	"github.com/asdine/lobby/validation"
+	"github.com/stretchr/testify/require"
+)
+
+// store is a backend recording the messages it receives, or failing with err.
+type store struct {
+	mu       sync.Mutex
+	err      error
+	options  map[string]string
+	received []string
+}
+
+func (s *store) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.received
+}
+
+func (s *store) Topic(name string, options map[string]string) (lobby.Topic, error) {
+	s.mu.Lock()
+	s.options = options
+	s.mu.Unlock()
+
+	return &mock.Topic{
+		SendFn: func(ctx context.Context, m *lobby.Message) error {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			if s.err != nil {
+				return s.err
+			}
+
+			s.received = append(s.received, string(m.Value))
+			return nil
+		},
+		SendBatchFn: func(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			errs := make([]error, len(messages))
+			for i, m := range messages {
+				if s.err != nil {
+					errs[i] = s.err
+					continue
+				}
+
+				s.received = append(s.received, string(m.Value))
+			}
+
+			return errs, nil
+		},
+	}, nil
+}
+
+func (s *store) Close() error {
+	return nil
+}
+
+// newRegistry returns a mirror registry on top of a mock registry storing the topics in memory.
+func newRegistry(backends map[string]*store) (*mirror.Registry, *mock.Registry) {
+	var m mock.Registry
+	topics := make(map[string]lobby.TopicInfo)
+
+	m.CreateFn = func(backend, name string, options map[string]string) error {
+		if _, ok := m.Backends[backend]; !ok {
+			return lobby.ErrBackendNotFound
+		}
+
+		topics[name] = lobby.TopicInfo{Name: name, Backend: backend, Options: options}
+		return nil
+	}
+
+	m.RebindFn = func(name, backend string, options map[string]string) error {
+		info, ok := topics[name]
+		if !ok {
+			return lobby.ErrTopicNotFound
+		}
+
+		info.Backend = backend
+		info.Options = options
+		topics[name] = info
+		return nil
+	}
+
+	m.InfoFn = func(name string) (*lobby.TopicInfo, error) {
+		info, ok := topics[name]
+		if !ok {
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		return &info, nil
+	}
+
+	m.TopicFn = func(name string) (lobby.Topic, error) {
+		info, ok := topics[name]
+		if !ok {
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		return m.Backends[info.Backend].Topic(name, info.Options)
+	}
+
+	r := mirror.NewRegistry(&m, log.New(log.Output(ioutil.Discard)))
+	for name, s := range backends {
+		r.RegisterBackend(name, s)
+	}
+
+	return r, &m
+}
+
+func send(t *testing.T, r lobby.Registry, topic, value string) error {
+	tp, err := r.Topic(topic)
+	require.NoError(t, err)
+	defer tp.Close()
+
+	return tp.Send(context.Background(), lobby.NewMessage("", []byte(value), nil))
+}
+
+func TestRegistryCreate(t *testing.T) {
+	bolt, nsq := new(store), new(store)
+	r, _ := newRegistry(map[string]*store{"bolt": bolt, "nsq": nsq})
+
+	err := r.Create("bolt", "a", map[string]string{mirror.MirrorsOption: " nsq, nsq", "max-age": "1h"})
+	require.NoError(t, err)
+
+	info, err := r.Info("a")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		mirror.MirrorsOption: "nsq",
+		mirror.AckOption:     mirror.AckAll,
+		"max-age":            "1h",
+	}, info.Options)
+
+	err = r.Create("bolt", "b", map[string]string{mirror.MirrorsOption: "redis"})
+	require.Equal(t, lobby.ErrBackendNotFound, err)
+
+	err = r.Create("bolt", "b", map[string]string{mirror.MirrorsOption: "bolt"})
+	require.True(t, validation.IsError(err))
+
+	err = r.Create("bolt", "b", map[string]string{mirror.MirrorsOption: "nsq", mirror.AckOption: "some"})
+	require.True(t, validation.IsError(err))
+
+	err = r.Create("bolt", "b", nil)
+	require.NoError(t, err)
+
+	// the mirror options are never given to the backends.
+	tp, err := r.Topic("a")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"max-age": "1h"}, bolt.options)
+	require.Equal(t, map[string]string{"max-age": "1h"}, nsq.options)
+
+	_, ok := tp.(lobby.TopicReader)
+	require.True(t, ok)
+}
+
+func TestRegistryAck(t *testing.T) {
+	errDown := errors.New("connection refused")
+
+	tests := []struct {
+		ack       string
+		boltErr   error
+		nsqErr    error
+		expectErr bool
+	}{
+		{mirror.AckAll, nil, nil, false},
+		{mirror.AckAll, nil, errDown, true},
+		{mirror.AckAny, nil, errDown, false},
+		{mirror.AckAny, errDown, nil, false},
+		{mirror.AckAny, errDown, errDown, true},
+		{mirror.AckPrimary, nil, errDown, false},
+		{mirror.AckPrimary, errDown, nil, true},
+	}
+
+	for _, test := range tests {
+		bolt := store{err: test.boltErr}
+		nsq := store{err: test.nsqErr}
+		r, _ := newRegistry(map[string]*store{"bolt": &bolt, "nsq": &nsq})
+
+		err := r.Create("bolt", "a", map[string]string{mirror.MirrorsOption: "nsq", mirror.AckOption: test.ack})
+		require.NoError(t, err)
+
+		err = send(t, r, "a", "1")
+		if test.expectErr {
+			require.Error(t, err, "ack=%s bolt=%v nsq=%v", test.ack, test.boltErr, test.nsqErr)
+		} else {
+			require.NoError(t, err, "ack=%s bolt=%v nsq=%v", test.ack, test.boltErr, test.nsqErr)
+		}
+
+		if test.boltErr == nil {
+			require.Equal(t, []string{"1"}, bolt.messages())
+		}
+
+		if test.nsqErr == nil {
+			require.Equal(t, []string{"1"}, nsq.messages())
+		}
+	}
+}
+
+func TestRegistryErrors(t *testing.T) {
+	t.Run("PerBackend", func(t *testing.T) {
+		bolt := store{err: errors.New("disk full")}
+		nsq := store{err: errors.New("connection refused")}
+		r, _ := newRegistry(map[string]*store{"bolt": &bolt, "nsq": &nsq})
+
+		err := r.Create("bolt", "errors", map[string]string{mirror.MirrorsOption: "nsq"})
+		require.NoError(t, err)
+
+		err = send(t, r, "errors", "1")
+		merr, ok := err.(*mirror.Error)
+		require.True(t, ok)
+		require.Len(t, merr.Errors, 2)
+		require.EqualError(t, err, "mirrored send failed: bolt: disk full; nsq: connection refused")
+
+		var buf bytes.Buffer
+		_, err = metrics.DefaultRegistry.WriteTo(&buf)
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), `lobby_mirror_send_errors_total{topic="errors",backend="nsq"} 1`)
+	})
+
+	t.Run("SameError", func(t *testing.T) {
+		bolt := store{err: context.DeadlineExceeded}
+		nsq := store{err: context.DeadlineExceeded}
+		r, _ := newRegistry(map[string]*store{"bolt": &bolt, "nsq": &nsq})
+
+		err := r.Create("bolt", "a", map[string]string{mirror.MirrorsOption: "nsq"})
+		require.NoError(t, err)
+		require.Equal(t, context.DeadlineExceeded, send(t, r, "a", "1"))
+	})
+
+	t.Run("MissingMirror", func(t *testing.T) {
+		bolt := new(store)
+		r, m := newRegistry(map[string]*store{"bolt": bolt})
+
+		// the topic was created when the nsq plugin was still in the config.
+		err := m.Create("bolt", "a", map[string]string{mirror.MirrorsOption: "nsq", mirror.AckOption: mirror.AckPrimary})
+		require.NoError(t, err)
+		require.NoError(t, send(t, r, "a", "1"))
+		require.Equal(t, []string{"1"}, bolt.messages())
+
+		err = m.Create("bolt", "b", map[string]string{mirror.MirrorsOption: "nsq"})
+		require.NoError(t, err)
+		require.Equal(t, lobby.ErrBackendNotFound, send(t, r, "b", "1"))
+	})
+}
+
+func TestRegistrySendBatch(t *testing.T) {
+	bolt := new(store)
+	nsq := store{err: errors.New("connection refused")}
+	r, _ := newRegistry(map[string]*store{"bolt": bolt, "nsq": &nsq})
+
+	err := r.Create("bolt", "all", map[string]string{mirror.MirrorsOption: "nsq"})
+	require.NoError(t, err)
+	err = r.Create("bolt", "any", map[string]string{mirror.MirrorsOption: "nsq", mirror.AckOption: mirror.AckAny})
+	require.NoError(t, err)
+
+	messages := []*lobby.Message{
+		lobby.NewMessage("", []byte("1"), nil),
+		lobby.NewMessage("", []byte("2"), nil),
+	}
+
+	tp, err := r.Topic("all")
+	require.NoError(t, err)
+	errs, err := lobby.SendBatch(context.Background(), tp, messages)
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+	require.Equal(t, nsq.err, errs[0])
+	require.Equal(t, nsq.err, errs[1])
+
+	tp, err = r.Topic("any")
+	require.NoError(t, err)
+	errs, err = lobby.SendBatch(context.Background(), tp, messages)
+	require.NoError(t, err)
+	require.Equal(t, []error{nil, nil}, errs)
+	require.Equal(t, []string{"1", "2", "1", "2"}, bolt.messages())
+}
@@ -0,0 +1,197 @@
+package mirror
+
+import (
+	"context"
+	"sync"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
+)
+
+var _ lobby.BatchSender = new(topic)
+
+// target is a backend a mirrored topic sends its messages to.
+type target struct {
+	backend string
+	topic   lobby.Topic
+	// err is set if the topic couldn't be opened.
+	err error
+}
+
+// topic sends its messages to the topic of each of its backends. The first target is the
+// backend of the topic, the others are its mirrors.
+type topic struct {
+	name    string
+	ack     string
+	logger  *log.Logger
+	targets []target
+}
+
+// Send the message to all the backends concurrently and wait for them to respond.
+// The acknowledgement policy decides which failures are returned.
+func (t *topic) Send(ctx context.Context, m *lobby.Message) error {
+	errs := make([]error, len(t.targets))
+
+	var wg sync.WaitGroup
+	for i := range t.targets {
+		if t.targets[i].err != nil {
+			errs[i] = t.targets[i].err
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = t.targets[i].topic.Send(ctx, m)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.report(i, 1, err)
+		}
+	}
+
+	return t.result(errs)
+}
+
+// SendBatch sends the messages to all the backends concurrently and wait for them to respond.
+// The acknowledgement policy is applied to each message.
+func (t *topic) SendBatch(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+	batchErrs := make([]error, len(t.targets))
+	msgErrs := make([][]error, len(t.targets))
+
+	var wg sync.WaitGroup
+	for i := range t.targets {
+		if t.targets[i].err != nil {
+			batchErrs[i] = t.targets[i].err
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			msgErrs[i], batchErrs[i] = lobby.SendBatch(ctx, t.targets[i].topic, messages)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range batchErrs {
+		if err != nil {
+			t.report(i, len(messages), err)
+			continue
+		}
+
+		var failed int
+		var last error
+		for _, err := range msgErrs[i] {
+			if err != nil {
+				failed++
+				last = err
+			}
+		}
+
+		if failed > 0 {
+			t.report(i, failed, last)
+		}
+	}
+
+	if err := t.result(batchErrs); err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(messages))
+	targetErrs := make([]error, len(t.targets))
+	for j := range messages {
+		for i := range t.targets {
+			targetErrs[i] = batchErrs[i]
+			if targetErrs[i] == nil {
+				targetErrs[i] = msgErrs[i][j]
+			}
+		}
+
+		errs[j] = t.result(targetErrs)
+	}
+
+	return errs, nil
+}
+
+// report the failure of n messages sent to the given target.
+func (t *topic) report(i, n int, err error) {
+	backend := t.targets[i].backend
+
+	sendErrors.Add(float64(n), t.name, backend)
+	t.logger.With("backend", backend).Warnf("Failed to send %d messages to %s backend: %s\n", n, backend, err)
+}
+
+// result applies the acknowledgement policy to the errors returned by each target.
+func (t *topic) result(errs []error) error {
+	var failed int
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+
+	switch {
+	case failed == 0:
+		return nil
+	case t.ack == AckPrimary:
+		return errs[0]
+	case t.ack == AckAny && failed < len(errs):
+		return nil
+	}
+
+	// errors shared by all the failed backends, like a timeout, are returned as is
+	// so that callers can handle them.
+	e := Error{Errors: make(map[string]error)}
+	var same error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		if len(e.Errors) == 0 {
+			same = err
+		} else if same != err {
+			same = nil
+		}
+
+		e.Errors[t.targets[i].backend] = err
+	}
+
+	if same != nil {
+		return same
+	}
+
+	return &e
+}
+
+// Close the topics of all the backends.
+func (t *topic) Close() error {
+	var first error
+	for _, tg := range t.targets {
+		if tg.topic == nil {
+			continue
+		}
+
+		if err := tg.topic.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// topicReader is a mirrored topic whose messages can be read back from one of its backends.
+type topicReader struct {
+	*topic
+
+	reader lobby.TopicReader
+}
+
+// Read the messages stored by the first backend able to return them.
+func (t *topicReader) Read(ctx context.Context, group, cursor string, limit int) ([]lobby.Message, string, error) {
+	return t.reader.Read(ctx, group, cursor, limit)
+}
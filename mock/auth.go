@@ -0,0 +1,42 @@
+package mock
+
+import (
+	"context"
+	"crypto/x509"
+
+	"github.com/asdine/lobby/auth"
+)
+
+var _ auth.Authenticator = new(Authenticator)
+var _ auth.CertificateAuthenticator = new(Authenticator)
+
+// Authenticator is a mock service that runs provided functions. Useful for testing.
+type Authenticator struct {
+	AuthenticateFn      func(ctx context.Context, token string) (*auth.Identity, error)
+	AuthenticateInvoked int
+
+	AuthenticateCertificateFn      func(ctx context.Context, cert *x509.Certificate) (*auth.Identity, error)
+	AuthenticateCertificateInvoked int
+}
+
+// Authenticate runs AuthenticateFn and increments AuthenticateInvoked when invoked.
+func (s *Authenticator) Authenticate(ctx context.Context, token string) (*auth.Identity, error) {
+	s.AuthenticateInvoked++
+
+	if s.AuthenticateFn != nil {
+		return s.AuthenticateFn(ctx, token)
+	}
+
+	return nil, nil
+}
+
+// AuthenticateCertificate runs AuthenticateCertificateFn and increments AuthenticateCertificateInvoked when invoked.
+func (s *Authenticator) AuthenticateCertificate(ctx context.Context, cert *x509.Certificate) (*auth.Identity, error) {
+	s.AuthenticateCertificateInvoked++
+
+	if s.AuthenticateCertificateFn != nil {
+		return s.AuthenticateCertificateFn(ctx, cert)
+	}
+
+	return nil, nil
+}
@@ -6,7 +6,7 @@ var _ lobby.Backend = new(Backend)
 
 // Backend is a mock service that runs provided functions. Useful for testing.
 type Backend struct {
-	TopicFn      func(name string) (lobby.Topic, error)
+	TopicFn      func(name string, options map[string]string) (lobby.Topic, error)
 	TopicInvoked int
 
 	CloseFn      func() error
@@ -14,11 +14,11 @@ type Backend struct {
 }
 
 // Topic runs TopicFn and increments TopicInvoked when invoked.
-func (s *Backend) Topic(name string) (lobby.Topic, error) {
+func (s *Backend) Topic(name string, options map[string]string) (lobby.Topic, error) {
 	s.TopicInvoked++
 
 	if s.TopicFn != nil {
-		return s.TopicFn(name)
+		return s.TopicFn(name, options)
 	}
 
 	return nil, nil
@@ -0,0 +1,26 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/asdine/lobby"
+)
+
+var _ lobby.HealthChecker = new(HealthChecker)
+
+// HealthChecker is a mock service that runs provided functions. Useful for testing.
+type HealthChecker struct {
+	HealthFn      func(ctx context.Context) error
+	HealthInvoked int
+}
+
+// Health runs HealthFn and increments HealthInvoked when invoked.
+func (s *HealthChecker) Health(ctx context.Context) error {
+	s.HealthInvoked++
+
+	if s.HealthFn != nil {
+		return s.HealthFn(ctx)
+	}
+
+	return nil
+}
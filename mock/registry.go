@@ -6,9 +6,21 @@ var _ lobby.Registry = new(Registry)
 
 // Registry is a mock service that runs provided functions. Useful for testing.
 type Registry struct {
-	CreateFn      func(string, string) error
+	CreateFn      func(string, string, map[string]string) error
 	CreateInvoked int
 
+	DeleteFn      func(string) error
+	DeleteInvoked int
+
+	RebindFn      func(string, string, map[string]string) error
+	RebindInvoked int
+
+	InfoFn      func(string) (*lobby.TopicInfo, error)
+	InfoInvoked int
+
+	ListFn      func(int, int) ([]lobby.TopicInfo, error)
+	ListInvoked int
+
 	TopicFn      func(string) (lobby.Topic, error)
 	TopicInvoked int
 
@@ -28,16 +40,60 @@ func (r *Registry) RegisterBackend(name string, backend lobby.Backend) {
 }
 
 // Create runs CreateFn and increments CreateInvoked when invoked.
-func (r *Registry) Create(backendName, topicName string) error {
+func (r *Registry) Create(backendName, topicName string, options map[string]string) error {
 	r.CreateInvoked++
 
 	if r.CreateFn != nil {
-		return r.CreateFn(backendName, topicName)
+		return r.CreateFn(backendName, topicName, options)
+	}
+
+	return nil
+}
+
+// Delete runs DeleteFn and increments DeleteInvoked when invoked.
+func (r *Registry) Delete(topicName string) error {
+	r.DeleteInvoked++
+
+	if r.DeleteFn != nil {
+		return r.DeleteFn(topicName)
+	}
+
+	return nil
+}
+
+// Rebind runs RebindFn and increments RebindInvoked when invoked.
+func (r *Registry) Rebind(topicName, backendName string, options map[string]string) error {
+	r.RebindInvoked++
+
+	if r.RebindFn != nil {
+		return r.RebindFn(topicName, backendName, options)
 	}
 
 	return nil
 }
 
+// Info runs InfoFn and increments InfoInvoked when invoked.
+func (r *Registry) Info(topicName string) (*lobby.TopicInfo, error) {
+	r.InfoInvoked++
+
+	if r.InfoFn != nil {
+		return r.InfoFn(topicName)
+	}
+
+	return nil, nil
+}
+
+// List runs ListFn and increments ListInvoked when invoked.
+func (r *Registry) List(offset, limit int) ([]lobby.TopicInfo, error) {
+	r.ListInvoked++
+
+	if r.ListFn != nil {
+		return r.ListFn(offset, limit)
+	}
+
+	return nil, nil
+}
+
 // Topic runs TopicFn and increments TopicInvoked when invoked.
 func (r *Registry) Topic(name string) (lobby.Topic, error) {
 	r.TopicInvoked++
@@ -1,29 +1,62 @@
 package mock
 
-import "github.com/asdine/lobby"
+import (
+	"context"
 
-var _ lobby.Topic = new(Topic)
+	"github.com/asdine/lobby"
+)
+
+var _ lobby.TopicReader = new(Topic)
+var _ lobby.BatchSender = new(Topic)
 
 // Topic is a mock service that runs provided functions. Useful for testing.
 type Topic struct {
-	SendFn      func(*lobby.Message) error
+	SendFn      func(context.Context, *lobby.Message) error
 	SendInvoked int
 
+	SendBatchFn      func(context.Context, []*lobby.Message) ([]error, error)
+	SendBatchInvoked int
+
+	ReadFn      func(ctx context.Context, group, cursor string, limit int) ([]lobby.Message, string, error)
+	ReadInvoked int
+
 	CloseFn      func() error
 	CloseInvoked int
 }
 
 // Send runs SendFn and increments SendInvoked when invoked.
-func (b *Topic) Send(message *lobby.Message) error {
+func (b *Topic) Send(ctx context.Context, message *lobby.Message) error {
 	b.SendInvoked++
 
 	if b.SendFn != nil {
-		return b.SendFn(message)
+		return b.SendFn(ctx, message)
 	}
 
 	return nil
 }
 
+// SendBatch runs SendBatchFn and increments SendBatchInvoked when invoked.
+func (b *Topic) SendBatch(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+	b.SendBatchInvoked++
+
+	if b.SendBatchFn != nil {
+		return b.SendBatchFn(ctx, messages)
+	}
+
+	return make([]error, len(messages)), nil
+}
+
+// Read runs ReadFn and increments ReadInvoked when invoked.
+func (b *Topic) Read(ctx context.Context, group, cursor string, limit int) ([]lobby.Message, string, error) {
+	b.ReadInvoked++
+
+	if b.ReadFn != nil {
+		return b.ReadFn(ctx, group, cursor, limit)
+	}
+
+	return nil, "", nil
+}
+
 // Close runs CloseFn and increments CloseInvoked when invoked.
 func (b *Topic) Close() error {
 	b.CloseInvoked++
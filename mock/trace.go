@@ -0,0 +1,47 @@
+package mock
+
+import (
+	"sync"
+
+	"github.com/asdine/lobby/trace"
+)
+
+var _ trace.Exporter = new(Exporter)
+
+// Exporter is a mock exporter that runs provided functions. Useful for testing.
+// Spans can be exported concurrently, the provided functions are called one at a time.
+type Exporter struct {
+	mu sync.Mutex
+
+	ExportFn      func(*trace.Span)
+	ExportInvoked int
+
+	CloseFn      func() error
+	CloseInvoked int
+}
+
+// Export runs ExportFn and increments ExportInvoked when invoked.
+func (e *Exporter) Export(s *trace.Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.ExportInvoked++
+
+	if e.ExportFn != nil {
+		e.ExportFn(s)
+	}
+}
+
+// Close runs CloseFn and increments CloseInvoked when invoked.
+func (e *Exporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.CloseInvoked++
+
+	if e.CloseFn != nil {
+		return e.CloseFn()
+	}
+
+	return nil
+}
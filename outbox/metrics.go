@@ -0,0 +1,18 @@
+package outbox
+
+import (
+	"github.com/asdine/lobby/metrics"
+)
+
+var (
+	outboxDepth = metrics.NewGauge(
+		"lobby_outbox_depth",
+		"Number of messages waiting in the outbox to be sent to their backend, by backend.",
+		"backend",
+	)
+	outboxMessages = metrics.NewCounter(
+		"lobby_outbox_messages_total",
+		"Number of messages that went through the outbox, by backend and result (stored, sent, dropped, rejected).",
+		"backend", "result",
+	)
+)
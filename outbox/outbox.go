@@ -0,0 +1,558 @@
+package outbox
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/validation"
+	"github.com/coreos/bbolt"
+	"github.com/pkg/errors"
+)
+
+// Default settings of an Outbox.
+const (
+	DefaultMaxMessages      = 100000
+	DefaultRetryInterval    = time.Second
+	DefaultMaxRetryInterval = 30 * time.Second
+)
+
+// ErrFull is returned when a message can't be stored because the outbox of the backend is full.
+const ErrFull = lobby.Error("outbox full")
+
+// Policy limits the size of the outbox of each backend. A zero limit is disabled.
+type Policy struct {
+	// Maximum number of messages.
+	MaxMessages int64
+	// Maximum size of the stored messages, in bytes.
+	MaxBytes int64
+	// DropOldest evicts the oldest messages when the outbox is full, instead of rejecting new ones.
+	DropOldest bool
+}
+
+// WithPolicy sets the size policy of the outbox of each backend. Defaults to DefaultMaxMessages messages,
+// rejecting new messages when full.
+func WithPolicy(p Policy) func(*Outbox) {
+	return func(o *Outbox) {
+		o.policy = p
+	}
+}
+
+// RetryInterval sets the period at which stored messages are sent again while a backend is unavailable.
+// It doubles after each failure, up to DefaultMaxRetryInterval.
+func RetryInterval(d time.Duration) func(*Outbox) {
+	return func(o *Outbox) {
+		o.retryInterval = d
+	}
+}
+
+// Transient sets the function deciding whether a failed message must be stored and sent again later.
+// By default, all errors are transient except lobby errors and validation errors.
+func Transient(fn func(error) bool) func(*Outbox) {
+	return func(o *Outbox) {
+		o.transient = fn
+	}
+}
+
+// Logger used to report the state of the outboxes.
+func Logger(logger *log.Logger) func(*Outbox) {
+	return func(o *Outbox) {
+		o.logger = logger
+	}
+}
+
+// Open the outbox stored in the BoltDB file at path.
+func Open(path string, opts ...func(*Outbox)) (*Outbox, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{
+		Timeout: time.Duration(50) * time.Millisecond,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open outbox %s", path)
+	}
+
+	o := Outbox{
+		db:            db,
+		policy:        Policy{MaxMessages: DefaultMaxMessages},
+		retryInterval: DefaultRetryInterval,
+		transient:     isTransient,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.logger == nil {
+		o.logger = log.New(log.Prefix("outbox:"))
+	}
+
+	return &o, nil
+}
+
+// Outbox stores the messages that couldn't be sent to their backend and sends them again, in order,
+// once the backend recovers. Messages are stored in a BoltDB file, they survive restarts.
+type Outbox struct {
+	db            *bolt.DB
+	policy        Policy
+	retryInterval time.Duration
+	transient     func(error) bool
+	logger        *log.Logger
+
+	mu       sync.Mutex
+	backends []*Backend
+}
+
+// Wrap returns a backend storing in the outbox the messages that fail to be sent to bck.
+// Messages stored during a previous run are sent again right away.
+func (o *Outbox) Wrap(name string, bck lobby.Backend) (*Backend, error) {
+	b := Backend{
+		Backend: bck,
+		name:    name,
+		outbox:  o,
+		logger:  o.logger.With("backend", name),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	err := o.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(name))
+		if err != nil {
+			return err
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			b.depth++
+			b.size += int64(len(v))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open outbox of backend %s", name)
+	}
+
+	outboxDepth.Set(float64(b.depth), name)
+	if b.depth > 0 {
+		b.logger.Printf("%d messages waiting to be sent\n", b.depth)
+	}
+
+	o.mu.Lock()
+	o.backends = append(o.backends, &b)
+	o.mu.Unlock()
+
+	go b.run()
+
+	return &b, nil
+}
+
+// Close stops sending the stored messages and closes the file.
+func (o *Outbox) Close() error {
+	o.mu.Lock()
+	backends := o.backends
+	o.backends = nil
+	o.mu.Unlock()
+
+	for _, b := range backends {
+		b.stop()
+	}
+
+	return o.db.Close()
+}
+
+// isTransient returns false for the errors caused by the message itself, which would happen again.
+func isTransient(err error) bool {
+	if _, ok := err.(lobby.Error); ok {
+		return false
+	}
+
+	return !validation.IsError(err)
+}
+
+var _ lobby.Backend = new(Backend)
+
+// Backend wraps a backend and stores in the outbox the messages it fails to send.
+type Backend struct {
+	lobby.Backend
+
+	name   string
+	outbox *Outbox
+	logger *log.Logger
+
+	mu    sync.Mutex
+	depth int64
+	size  int64
+
+	stopOnce sync.Once
+	quit     chan struct{}
+	done     chan struct{}
+}
+
+// Topic returns a topic of the underlying backend whose failed messages are stored in the outbox.
+func (b *Backend) Topic(name string, options map[string]string) (lobby.Topic, error) {
+	t, err := b.Backend.Topic(name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	ot := topic{Topic: t, name: name, options: options, backend: b}
+	if tr, ok := t.(lobby.TopicReader); ok {
+		return &topicReader{topic: &ot, reader: tr}, nil
+	}
+
+	return &ot, nil
+}
+
+// Depth returns the number of messages waiting to be sent.
+func (b *Backend) Depth() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.depth
+}
+
+// Close stops sending the stored messages and closes the underlying backend.
+// The messages remain in the outbox.
+func (b *Backend) Close() error {
+	b.stop()
+	return b.Backend.Close()
+}
+
+func (b *Backend) stop() {
+	b.stopOnce.Do(func() {
+		close(b.quit)
+	})
+	<-b.done
+}
+
+// entry is a message stored in the outbox, with the topic it must be sent to.
+type entry struct {
+	Topic   string            `json:"topic"`
+	Options map[string]string `json:"options,omitempty"`
+	Message *lobby.Message    `json:"message"`
+}
+
+// store appends the messages to the outbox. If the policy doesn't allow to store all of them,
+// none is stored and ErrFull is returned.
+func (b *Backend) store(t *topic, messages []*lobby.Message) error {
+	values := make([][]byte, len(messages))
+	var size int64
+	for i, m := range messages {
+		data, err := json.Marshal(&entry{Topic: t.name, Options: t.options, Message: m})
+		if err != nil {
+			return err
+		}
+
+		values[i] = data
+		size += int64(len(data))
+	}
+
+	policy := b.outbox.policy
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var evicted, evictedSize int64
+	full := func() bool {
+		depth := b.depth - evicted + int64(len(values))
+		total := b.size - evictedSize + size
+		return (policy.MaxMessages > 0 && depth > policy.MaxMessages) || (policy.MaxBytes > 0 && total > policy.MaxBytes)
+	}
+
+	if full() && !policy.DropOldest {
+		return ErrFull
+	}
+
+	err := b.outbox.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(b.name))
+
+		// the cursor is moved back to the first message after each deletion,
+		// Next can skip messages after a Delete.
+		c := bucket.Cursor()
+		for k, v := c.First(); k != nil && full(); k, v = c.First() {
+			evicted++
+			evictedSize += int64(len(v))
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+
+		if full() {
+			// the messages are bigger than the outbox.
+			return ErrFull
+		}
+
+		for _, v := range values {
+			seq, err := bucket.NextSequence()
+			if err != nil {
+				return err
+			}
+
+			if err = bucket.Put(key(seq), v); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if evicted > 0 {
+		b.logger.Warnf("Outbox full, dropped %d oldest messages\n", evicted)
+		outboxMessages.Add(float64(evicted), b.name, "dropped")
+	}
+
+	if b.depth == 0 {
+		b.logger.Warn("Backend unavailable, storing messages in the outbox")
+	}
+
+	b.depth += int64(len(values)) - evicted
+	b.size += size - evictedSize
+	outboxDepth.Set(float64(b.depth), b.name)
+	outboxMessages.Add(float64(len(values)), b.name, "stored")
+
+	return nil
+}
+
+func key(seq uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, seq)
+	return k
+}
+
+// run sends the stored messages again until the outbox is empty, then waits for new ones.
+func (b *Backend) run() {
+	defer close(b.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-b.quit
+		cancel()
+	}()
+
+	delay := b.outbox.retryInterval
+	for {
+		select {
+		case <-b.quit:
+			return
+		case <-time.After(delay):
+		}
+
+		if b.Depth() == 0 {
+			delay = b.outbox.retryInterval
+			continue
+		}
+
+		err := b.flush(ctx)
+		if err == nil {
+			b.logger.Println("Backend recovered, all the messages of the outbox were sent")
+			delay = b.outbox.retryInterval
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		b.logger.Debugf("Failed to send the messages of the outbox: %s\n", err)
+		delay *= 2
+		if delay > DefaultMaxRetryInterval {
+			delay = DefaultMaxRetryInterval
+		}
+	}
+}
+
+// flush sends the stored messages, oldest first, and removes them from the outbox.
+// It stops at the first message that fails with a transient error.
+// Messages failing with other errors are dropped.
+func (b *Backend) flush(ctx context.Context) error {
+	for {
+		k, e, size, err := b.first()
+		if err != nil || k == nil {
+			return err
+		}
+
+		t, err := b.Backend.Topic(e.Topic, e.Options)
+		if err == nil {
+			err = t.Send(ctx, e.Message)
+			t.Close()
+		}
+
+		if err != nil && b.outbox.transient(err) {
+			return err
+		}
+
+		result := "sent"
+		if err != nil {
+			b.logger.With("topic", e.Topic).Errorf("Dropping message %s from the outbox: %s\n", e.Message.ID, err)
+			result = "dropped"
+		}
+
+		if err = b.remove(k, size); err != nil {
+			return err
+		}
+		outboxMessages.Inc(b.name, result)
+	}
+}
+
+// first returns the oldest message of the outbox, or a nil key if it is empty.
+// Messages that can't be decoded are removed.
+func (b *Backend) first() ([]byte, *entry, int64, error) {
+	for {
+		var k, v []byte
+
+		err := b.outbox.db.View(func(tx *bolt.Tx) error {
+			ck, cv := tx.Bucket([]byte(b.name)).Cursor().First()
+			// data returned by bolt is only valid during the transaction.
+			k = append([]byte(nil), ck...)
+			v = append([]byte(nil), cv...)
+			return nil
+		})
+		if err != nil || len(k) == 0 {
+			return nil, nil, 0, err
+		}
+
+		var e entry
+		err = json.Unmarshal(v, &e)
+		if err == nil && e.Message != nil {
+			return k, &e, int64(len(v)), nil
+		}
+
+		b.logger.Errorf("Dropping invalid message from the outbox: %v\n", err)
+		if err = b.remove(k, int64(len(v))); err != nil {
+			return nil, nil, 0, err
+		}
+		outboxMessages.Inc(b.name, "dropped")
+	}
+}
+
+func (b *Backend) remove(k []byte, size int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var found bool
+	err := b.outbox.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(b.name))
+		// the message may have been evicted in the meantime.
+		found = bucket.Get(k) != nil
+		if !found {
+			return nil
+		}
+
+		return bucket.Delete(k)
+	})
+	if err != nil || !found {
+		return err
+	}
+
+	b.depth--
+	b.size -= size
+	outboxDepth.Set(float64(b.depth), b.name)
+	return nil
+}
+
+// topic sends the messages to the underlying topic, or stores them in the outbox if it fails.
+type topic struct {
+	lobby.Topic
+
+	name    string
+	options map[string]string
+	backend *Backend
+}
+
+// Send the message to the underlying topic. If the outbox already contains messages, the message
+// is stored after them to preserve the order. If the topic fails with a transient error,
+// the message is stored and Send returns nil.
+func (t *topic) Send(ctx context.Context, m *lobby.Message) error {
+	if t.backend.Depth() > 0 {
+		return t.store(nil, m)
+	}
+
+	err := t.Topic.Send(ctx, m)
+	if err == nil || !t.backend.outbox.transient(err) {
+		return err
+	}
+
+	return t.store(err, m)
+}
+
+// SendBatch sends the messages to the underlying topic and stores the ones failing with a transient error.
+func (t *topic) SendBatch(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+	if t.backend.Depth() > 0 {
+		if err := t.store(nil, messages...); err != nil {
+			return nil, err
+		}
+
+		return make([]error, len(messages)), nil
+	}
+
+	errs, err := lobby.SendBatch(ctx, t.Topic, messages)
+	if err != nil {
+		if !t.backend.outbox.transient(err) {
+			return nil, err
+		}
+
+		if err = t.store(err, messages...); err != nil {
+			return nil, err
+		}
+
+		return make([]error, len(messages)), nil
+	}
+
+	var failed []*lobby.Message
+	var indexes []int
+	for i := range errs {
+		if errs[i] != nil && t.backend.outbox.transient(errs[i]) {
+			failed = append(failed, messages[i])
+			indexes = append(indexes, i)
+		}
+	}
+
+	// if the messages can't be stored, the original errors are returned.
+	if len(failed) == 0 || t.store(nil, failed...) != nil {
+		return errs, nil
+	}
+
+	for _, i := range indexes {
+		errs[i] = nil
+	}
+
+	return errs, nil
+}
+
+// store the messages in the outbox. If they can't be stored for another reason than
+// the outbox being full, the original error is returned if there is one.
+func (t *topic) store(cause error, messages ...*lobby.Message) error {
+	err := t.backend.store(t, messages)
+	switch {
+	case err == nil:
+		return nil
+	case err == ErrFull:
+		outboxMessages.Add(float64(len(messages)), t.backend.name, "rejected")
+		return err
+	}
+
+	t.backend.logger.Errorf("Failed to store messages in the outbox: %s\n", err)
+	if cause != nil {
+		return cause
+	}
+
+	return err
+}
+
+// topicReader is a topic whose underlying topic is a lobby.TopicReader.
+type topicReader struct {
+	*topic
+
+	reader lobby.TopicReader
+}
+
+// Read the messages stored by the underlying topic. Messages in the outbox are not returned.
+func (t *topicReader) Read(ctx context.Context, group, cursor string, limit int) ([]lobby.Message, string, error) {
+	return t.reader.Read(ctx, group, cursor, limit)
+}
@@ -0,0 +1,234 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/mock"
+	"github.com/asdine/lobby/outbox"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackend is a backend that can be made unavailable.
+type fakeBackend struct {
+	mu       sync.Mutex
+	down     bool
+	received []string
+}
+
+func (f *fakeBackend) setDown(down bool) {
+	f.mu.Lock()
+	f.down = down
+	f.mu.Unlock()
+}
+
+func (f *fakeBackend) messages() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.received...)
+}
+
+func (f *fakeBackend) backend() *mock.Backend {
+	return &mock.Backend{
+		TopicFn: func(name string, options map[string]string) (lobby.Topic, error) {
+			return &mock.Topic{
+				SendFn: func(ctx context.Context, m *lobby.Message) error {
+					f.mu.Lock()
+					defer f.mu.Unlock()
+
+					if f.down {
+						return errors.New("connection refused")
+					}
+
+					if string(m.Value) == "invalid" {
+						return lobby.ErrTopicNotFound
+					}
+
+					f.received = append(f.received, name+":"+string(m.Value))
+					return nil
+				},
+			}, nil
+		},
+	}
+}
+
+func openOutbox(t *testing.T, opts ...func(*outbox.Outbox)) (*outbox.Outbox, string, func()) {
+	dir, err := ioutil.TempDir(os.TempDir(), "lobby")
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "outbox.db")
+	opts = append([]func(*outbox.Outbox){
+		outbox.RetryInterval(5 * time.Millisecond),
+		outbox.Logger(log.New(log.Output(ioutil.Discard))),
+	}, opts...)
+
+	o, err := outbox.Open(path, opts...)
+	require.NoError(t, err)
+
+	return o, path, func() {
+		o.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// waitForDepth blocks until the outbox of the backend contains depth messages.
+func waitForDepth(t *testing.T, b *outbox.Backend, depth int64) {
+	deadline := time.Now().Add(time.Second)
+	for b.Depth() != depth {
+		require.True(t, time.Now().Before(deadline), "outbox wasn't flushed")
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func send(t *testing.T, b lobby.Backend, topic, value string) error {
+	tp, err := b.Topic(topic, nil)
+	require.NoError(t, err)
+	defer tp.Close()
+
+	return tp.Send(context.Background(), lobby.NewMessage("", []byte(value), nil))
+}
+
+func TestOutbox(t *testing.T) {
+	t.Run("Available", func(t *testing.T) {
+		o, _, cleanup := openOutbox(t)
+		defer cleanup()
+
+		var f fakeBackend
+		b, err := o.Wrap("redis", f.backend())
+		require.NoError(t, err)
+
+		require.NoError(t, send(t, b, "a", "1"))
+		require.Equal(t, int64(0), b.Depth())
+		require.Equal(t, []string{"a:1"}, f.messages())
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		o, _, cleanup := openOutbox(t)
+		defer cleanup()
+
+		var f fakeBackend
+		f.setDown(true)
+		b, err := o.Wrap("redis", f.backend())
+		require.NoError(t, err)
+
+		require.NoError(t, send(t, b, "a", "1"))
+		require.NoError(t, send(t, b, "b", "2"))
+		require.Equal(t, int64(2), b.Depth())
+
+		f.setDown(false)
+		// sent after the stored messages to preserve the order.
+		require.NoError(t, send(t, b, "a", "3"))
+
+		waitForDepth(t, b, 0)
+		require.Equal(t, []string{"a:1", "b:2", "a:3"}, f.messages())
+	})
+
+	t.Run("NotTransient", func(t *testing.T) {
+		o, _, cleanup := openOutbox(t)
+		defer cleanup()
+
+		var f fakeBackend
+		b, err := o.Wrap("redis", f.backend())
+		require.NoError(t, err)
+
+		require.Equal(t, lobby.ErrTopicNotFound, send(t, b, "a", "invalid"))
+		require.Equal(t, int64(0), b.Depth())
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		o, _, cleanup := openOutbox(t, outbox.WithPolicy(outbox.Policy{MaxMessages: 2}), outbox.RetryInterval(time.Hour))
+		defer cleanup()
+
+		var f fakeBackend
+		f.setDown(true)
+		b, err := o.Wrap("redis", f.backend())
+		require.NoError(t, err)
+
+		require.NoError(t, send(t, b, "a", "1"))
+		require.NoError(t, send(t, b, "a", "2"))
+		require.Equal(t, outbox.ErrFull, send(t, b, "a", "3"))
+		require.Equal(t, int64(2), b.Depth())
+	})
+
+	t.Run("DropOldest", func(t *testing.T) {
+		o, _, cleanup := openOutbox(t, outbox.WithPolicy(outbox.Policy{MaxMessages: 2, DropOldest: true}))
+		defer cleanup()
+
+		var f fakeBackend
+		f.setDown(true)
+		b, err := o.Wrap("redis", f.backend())
+		require.NoError(t, err)
+
+		for _, v := range []string{"1", "2", "3"} {
+			require.NoError(t, send(t, b, "a", v))
+		}
+		require.Equal(t, int64(2), b.Depth())
+
+		f.setDown(false)
+		waitForDepth(t, b, 0)
+		require.Equal(t, []string{"a:2", "a:3"}, f.messages())
+	})
+
+	t.Run("SendBatch", func(t *testing.T) {
+		o, _, cleanup := openOutbox(t, outbox.RetryInterval(time.Hour))
+		defer cleanup()
+
+		b, err := o.Wrap("redis", &mock.Backend{
+			TopicFn: func(name string, options map[string]string) (lobby.Topic, error) {
+				return &mock.Topic{
+					SendBatchFn: func(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+						return []error{nil, errors.New("timeout"), lobby.ErrTopicNotFound}, nil
+					},
+				}, nil
+			},
+		})
+		require.NoError(t, err)
+
+		tp, err := b.Topic("a", nil)
+		require.NoError(t, err)
+		_, ok := tp.(lobby.TopicReader)
+		require.True(t, ok)
+
+		errs, err := lobby.SendBatch(context.Background(), tp, []*lobby.Message{
+			lobby.NewMessage("", []byte("1"), nil),
+			lobby.NewMessage("", []byte("2"), nil),
+			lobby.NewMessage("", []byte("3"), nil),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []error{nil, nil, lobby.ErrTopicNotFound}, errs)
+		require.Equal(t, int64(1), b.Depth())
+	})
+
+	t.Run("Persistence", func(t *testing.T) {
+		o, path, cleanup := openOutbox(t, outbox.RetryInterval(time.Hour))
+		defer cleanup()
+
+		var f fakeBackend
+		f.setDown(true)
+		b, err := o.Wrap("redis", f.backend())
+		require.NoError(t, err)
+		require.NoError(t, send(t, b, "a", "1"))
+		require.NoError(t, o.Close())
+
+		o, err = outbox.Open(path, outbox.RetryInterval(5*time.Millisecond), outbox.Logger(log.New(log.Output(ioutil.Discard))))
+		require.NoError(t, err)
+		defer o.Close()
+
+		b, err = o.Wrap("redis", f.backend())
+		require.NoError(t, err)
+		require.Equal(t, int64(1), b.Depth())
+
+		f.setDown(false)
+
+		waitForDepth(t, b, 0)
+		require.Equal(t, []string{"a:1"}, f.messages())
+	})
+}
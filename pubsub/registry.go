@@ -0,0 +1,277 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
+)
+
+// DefaultBufferSize is the default number of messages a subscription can hold
+// before its subscriber is considered too slow.
+const DefaultBufferSize = 100
+
+var _ lobby.Registry = new(Registry)
+var _ lobby.Subscriber = new(Registry)
+var _ lobby.Migrator = new(Registry)
+var _ lobby.BatchSender = new(topic)
+
+// NewRegistry returns a Registry that publishes every message successfully sent to the topics of r
+// to their subscribers. Subscribers that have more than bufferSize messages waiting to be consumed
+// are disconnected so they never block producers.
+func NewRegistry(r lobby.Registry, bufferSize int, logger *log.Logger) *Registry {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+
+	return &Registry{
+		Registry:   r,
+		bufferSize: bufferSize,
+		logger:     logger,
+		subs:       make(map[string]map[*subscription]struct{}),
+	}
+}
+
+// Registry is a registry able to deliver the messages sent to its topics in real time.
+type Registry struct {
+	lobby.Registry
+
+	bufferSize int
+	logger     *log.Logger
+	mu         sync.RWMutex
+	subs       map[string]map[*subscription]struct{}
+}
+
+// Topic returns the selected topic from the underlying registry.
+func (r *Registry) Topic(name string) (lobby.Topic, error) {
+	t, err := r.Registry.Topic(name)
+	if err != nil {
+		return nil, err
+	}
+
+	pt := topic{Topic: t, name: name, registry: r}
+	if tr, ok := t.(lobby.TopicReader); ok {
+		return &topicReader{topic: &pt, reader: tr}, nil
+	}
+
+	return &pt, nil
+}
+
+// Delete a topic from the underlying registry and ends all of its subscriptions.
+func (r *Registry) Delete(topicName string) error {
+	err := r.Registry.Delete(topicName)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	subs := r.subs[topicName]
+	delete(r.subs, topicName)
+	r.mu.Unlock()
+
+	for s := range subs {
+		s.end(lobby.ErrTopicNotFound)
+	}
+
+	return nil
+}
+
+// Migrate a topic to another backend along with its messages,
+// if the underlying registry is a lobby.Migrator.
+func (r *Registry) Migrate(ctx context.Context, topicName, backendName string, options map[string]string) error {
+	m, ok := r.Registry.(lobby.Migrator)
+	if !ok {
+		return lobby.ErrNotSupported
+	}
+
+	return m.Migrate(ctx, topicName, backendName, options)
+}
+
+// Subscribe to the messages sent to the given topic.
+func (r *Registry) Subscribe(topicName, group string) (lobby.Subscription, error) {
+	_, err := r.Registry.Info(topicName)
+	if err != nil {
+		return nil, err
+	}
+
+	s := subscription{
+		registry: r,
+		topic:    topicName,
+		group:    group,
+		c:        make(chan *lobby.Message, r.bufferSize),
+	}
+
+	r.mu.Lock()
+	if _, ok := r.subs[topicName]; !ok {
+		r.subs[topicName] = make(map[*subscription]struct{})
+	}
+	r.subs[topicName][&s] = struct{}{}
+	r.mu.Unlock()
+
+	return &s, nil
+}
+
+// Close ends all the subscriptions and closes the underlying registry.
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	subs := r.subs
+	r.subs = make(map[string]map[*subscription]struct{})
+	r.mu.Unlock()
+
+	for _, list := range subs {
+		for s := range list {
+			s.end(nil)
+		}
+	}
+
+	return r.Registry.Close()
+}
+
+func (r *Registry) publish(topicName string, m *lobby.Message) {
+	var slow []*subscription
+
+	r.mu.RLock()
+	for s := range r.subs[topicName] {
+		if s.group != "" && s.group != m.Group {
+			continue
+		}
+
+		if !s.deliver(m) {
+			slow = append(slow, s)
+		}
+	}
+	r.mu.RUnlock()
+
+	for _, s := range slow {
+		r.logger.Warnf("Subscriber of topic %s is too slow, ending subscription\n", topicName)
+		r.remove(s)
+	}
+}
+
+func (r *Registry) remove(s *subscription) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subs, ok := r.subs[s.topic]
+	if !ok {
+		return
+	}
+
+	delete(subs, s)
+	if len(subs) == 0 {
+		delete(r.subs, s.topic)
+	}
+}
+
+type topic struct {
+	lobby.Topic
+
+	name     string
+	registry *Registry
+}
+
+// Send a message to the underlying topic and publishes it to the subscribers.
+func (t *topic) Send(ctx context.Context, m *lobby.Message) error {
+	err := t.Topic.Send(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	published := *m
+	t.registry.publish(t.name, &published)
+
+	return nil
+}
+
+// SendBatch sends the messages to the underlying topic and publishes the ones that were sent.
+func (t *topic) SendBatch(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+	errs, err := lobby.SendBatch(ctx, t.Topic, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range messages {
+		if errs[i] != nil {
+			continue
+		}
+
+		published := *messages[i]
+		t.registry.publish(t.name, &published)
+	}
+
+	return errs, nil
+}
+
+type topicReader struct {
+	*topic
+
+	reader lobby.TopicReader
+}
+
+// Read messages stored in the underlying topic.
+func (t *topicReader) Read(ctx context.Context, group, cursor string, limit int) ([]lobby.Message, string, error) {
+	return t.reader.Read(ctx, group, cursor, limit)
+}
+
+type subscription struct {
+	registry *Registry
+	topic    string
+	group    string
+	c        chan *lobby.Message
+
+	mu     sync.Mutex
+	closed bool
+	err    error
+}
+
+func (s *subscription) Messages() <-chan *lobby.Message {
+	return s.c
+}
+
+func (s *subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+func (s *subscription) Close() error {
+	s.registry.remove(s)
+	s.end(nil)
+	return nil
+}
+
+// deliver the message without blocking. If the buffer is full, the subscription
+// is ended and deliver returns false.
+func (s *subscription) deliver(m *lobby.Message) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return true
+	}
+
+	select {
+	case s.c <- m:
+		return true
+	default:
+		s.closed = true
+		s.err = lobby.ErrSlowConsumer
+		close(s.c)
+		return false
+	}
+}
+
+func (s *subscription) end(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+	s.err = err
+	close(s.c)
+}
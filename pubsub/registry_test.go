@@ -0,0 +1,222 @@
+package pubsub_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/mock"
+	"github.com/asdine/lobby/pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+func newRegistry(bufferSize int) (*pubsub.Registry, *mock.Registry) {
+	var m mock.Registry
+
+	m.InfoFn = func(name string) (*lobby.TopicInfo, error) {
+		if name != "topic" {
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		return &lobby.TopicInfo{Name: name}, nil
+	}
+
+	m.TopicFn = func(name string) (lobby.Topic, error) {
+		return lobby.TopicFunc(func(context.Context, *lobby.Message) error {
+			return nil
+		}), nil
+	}
+
+	m.DeleteFn = func(name string) error {
+		return nil
+	}
+
+	m.CloseFn = func() error {
+		return nil
+	}
+
+	return pubsub.NewRegistry(&m, bufferSize, log.New(log.Output(ioutil.Discard))), &m
+}
+
+func send(t *testing.T, r lobby.Registry, group, value string) {
+	topic, err := r.Topic("topic")
+	require.NoError(t, err)
+
+	err = topic.Send(context.Background(), &lobby.Message{Group: group, Value: []byte(value)})
+	require.NoError(t, err)
+}
+
+func TestRegistrySubscribe(t *testing.T) {
+	t.Run("TopicNotFound", func(t *testing.T) {
+		r, _ := newRegistry(10)
+
+		_, err := r.Subscribe("unknown", "")
+		require.Equal(t, lobby.ErrTopicNotFound, err)
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		r, _ := newRegistry(10)
+
+		all, err := r.Subscribe("topic", "")
+		require.NoError(t, err)
+		defer all.Close()
+
+		group, err := r.Subscribe("topic", "b")
+		require.NoError(t, err)
+		defer group.Close()
+
+		send(t, r, "a", "1")
+		send(t, r, "b", "2")
+
+		m := <-all.Messages()
+		require.Equal(t, "a", m.Group)
+		require.Equal(t, "1", string(m.Value))
+		m = <-all.Messages()
+		require.Equal(t, "b", m.Group)
+		require.Equal(t, "2", string(m.Value))
+
+		m = <-group.Messages()
+		require.Equal(t, "b", m.Group)
+		require.Len(t, group.Messages(), 0)
+	})
+
+	t.Run("SendError", func(t *testing.T) {
+		r, m := newRegistry(10)
+		m.TopicFn = func(name string) (lobby.Topic, error) {
+			return lobby.TopicFunc(func(context.Context, *lobby.Message) error {
+				return lobby.ErrNotSupported
+			}), nil
+		}
+
+		sub, err := r.Subscribe("topic", "")
+		require.NoError(t, err)
+		defer sub.Close()
+
+		topic, err := r.Topic("topic")
+		require.NoError(t, err)
+
+		err = topic.Send(context.Background(), &lobby.Message{Value: []byte("value")})
+		require.Equal(t, lobby.ErrNotSupported, err)
+		require.Len(t, sub.Messages(), 0)
+	})
+
+	t.Run("SendBatch", func(t *testing.T) {
+		r, m := newRegistry(10)
+		m.TopicFn = func(name string) (lobby.Topic, error) {
+			return lobby.TopicFunc(func(ctx context.Context, m *lobby.Message) error {
+				if m.Group == "bad" {
+					return lobby.ErrNotSupported
+				}
+				return nil
+			}), nil
+		}
+
+		sub, err := r.Subscribe("topic", "")
+		require.NoError(t, err)
+		defer sub.Close()
+
+		topic, err := r.Topic("topic")
+		require.NoError(t, err)
+
+		errs, err := lobby.SendBatch(context.Background(), topic, []*lobby.Message{
+			{Group: "a", Value: []byte("1")},
+			{Group: "bad", Value: []byte("2")},
+			{Group: "b", Value: []byte("3")},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []error{nil, lobby.ErrNotSupported, nil}, errs)
+
+		require.Len(t, sub.Messages(), 2)
+		msg := <-sub.Messages()
+		require.Equal(t, "1", string(msg.Value))
+		msg = <-sub.Messages()
+		require.Equal(t, "3", string(msg.Value))
+	})
+
+	t.Run("TopicReader", func(t *testing.T) {
+		r, m := newRegistry(10)
+		m.TopicFn = func(name string) (lobby.Topic, error) {
+			return new(mock.Topic), nil
+		}
+
+		topic, err := r.Topic("topic")
+		require.NoError(t, err)
+		_, ok := topic.(lobby.TopicReader)
+		require.True(t, ok)
+	})
+
+	t.Run("SlowConsumer", func(t *testing.T) {
+		r, _ := newRegistry(2)
+
+		slow, err := r.Subscribe("topic", "")
+		require.NoError(t, err)
+		defer slow.Close()
+
+		fast, err := r.Subscribe("topic", "")
+		require.NoError(t, err)
+		defer fast.Close()
+
+		for i := 0; i < 3; i++ {
+			send(t, r, "", "value")
+			<-fast.Messages()
+		}
+
+		var count int
+		for range slow.Messages() {
+			count++
+		}
+		require.Equal(t, 2, count)
+		require.Equal(t, lobby.ErrSlowConsumer, slow.Err())
+
+		send(t, r, "", "value")
+		<-fast.Messages()
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		r, _ := newRegistry(10)
+
+		sub, err := r.Subscribe("topic", "")
+		require.NoError(t, err)
+
+		err = sub.Close()
+		require.NoError(t, err)
+
+		_, ok := <-sub.Messages()
+		require.False(t, ok)
+		require.NoError(t, sub.Err())
+
+		send(t, r, "", "value")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		r, _ := newRegistry(10)
+
+		sub, err := r.Subscribe("topic", "")
+		require.NoError(t, err)
+		defer sub.Close()
+
+		err = r.Delete("topic")
+		require.NoError(t, err)
+
+		_, ok := <-sub.Messages()
+		require.False(t, ok)
+		require.Equal(t, lobby.ErrTopicNotFound, sub.Err())
+	})
+
+	t.Run("RegistryClose", func(t *testing.T) {
+		r, m := newRegistry(10)
+
+		sub, err := r.Subscribe("topic", "")
+		require.NoError(t, err)
+
+		err = r.Close()
+		require.NoError(t, err)
+		require.Equal(t, 1, m.CloseInvoked)
+
+		_, ok := <-sub.Messages()
+		require.False(t, ok)
+		require.NoError(t, sub.Err())
+	})
+}
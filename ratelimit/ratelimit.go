@@ -0,0 +1,181 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the period at which the buckets of inactive clients and topics are removed.
+const sweepInterval = time.Minute
+
+// Limit of a token bucket. Rate tokens are added every second, up to Burst tokens.
+// A zero Rate means no limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// burst returns the size of the bucket. It defaults to the rate, rounded up.
+func (l Limit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+
+	return math.Max(1, math.Ceil(l.Rate))
+}
+
+// New returns a Limiter. Every client is limited by client, and every topic by topic
+// unless it has its own limit in topics.
+func New(client, topic Limit, topics map[string]Limit) *Limiter {
+	return &Limiter{
+		client:  client,
+		topic:   topic,
+		topics:  topics,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Limiter enforces token bucket limits on the requests of each client and on the messages
+// sent to each topic. A request costs one token to its client and one token per message
+// to its topic, so that a batch counts as many messages as it holds.
+type Limiter struct {
+	client Limit
+	topic  Limit
+	topics map[string]Limit
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// Allow takes a token from the bucket of the client and, if topic is not empty, from
+// the bucket of the topic. If either is empty, no token is taken and Allow returns false
+// with the duration after which the request can be retried.
+func (l *Limiter) Allow(client, topic string) (bool, time.Duration) {
+	return l.AllowN(client, topic, 1)
+}
+
+// AllowN is like Allow for a request sending n messages: it takes a token from the bucket
+// of the client, if not empty, and n tokens from the bucket of the topic. Batches larger
+// than the burst of the topic are allowed once its bucket is full and leave it in debt,
+// which delays the following requests.
+func (l *Limiter) AllowN(client, topic string, n int) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	type charge struct {
+		b *bucket
+		n float64
+	}
+
+	var list []charge
+	if client != "" && l.client.Rate > 0 {
+		list = append(list, charge{l.bucket("client:"+client, l.client, now), 1})
+	}
+
+	if topic != "" {
+		limit, ok := l.topics[topic]
+		if !ok {
+			limit = l.topic
+		}
+
+		if limit.Rate > 0 {
+			list = append(list, charge{l.bucket("topic:"+topic, limit, now), float64(n)})
+		}
+	}
+
+	var wait time.Duration
+	for _, c := range list {
+		if d := c.b.wait(c.n); d > wait {
+			wait = d
+		}
+	}
+
+	if wait > 0 {
+		return false, wait
+	}
+
+	for _, c := range list {
+		c.b.tokens -= c.n
+	}
+
+	return true, 0
+}
+
+func (l *Limiter) bucket(key string, limit Limit, now time.Time) *bucket {
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{
+			rate:   limit.Rate,
+			burst:  limit.burst(),
+			tokens: limit.burst(),
+			last:   now,
+		}
+		l.buckets[key] = b
+	}
+
+	b.refill(now)
+	return b
+}
+
+// sweep removes the buckets that are full, they behave like new ones.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= b.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// wait returns the duration after which n tokens are available, zero if they are available now.
+// At most burst tokens are waited for, more would never be available.
+func (b *bucket) wait(n float64) time.Duration {
+	n = math.Min(n, b.burst)
+	if b.tokens >= n {
+		return 0
+	}
+
+	return time.Duration((n - b.tokens) / b.rate * float64(time.Second))
+}
+
+// ClientKey identifies a client by the name of the identity it was authenticated with, if any,
+// otherwise by its address. Credentials that weren't verified must not be used, clients could
+// get a new bucket for each request by sending random ones.
+func ClientKey(identity, addr string) string {
+	if identity == "" {
+		return "addr:" + addr
+	}
+
+	return "identity:" + identity
+}
+
+// RetryAfter returns the value of a Retry-After header, in whole seconds.
+func RetryAfter(d time.Duration) int {
+	return int(math.Max(1, math.Ceil(d.Seconds())))
+}
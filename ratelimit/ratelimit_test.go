@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	newLimiter := func(client, topic Limit, topics map[string]Limit) *Limiter {
+		l := New(client, topic, topics)
+		l.now = func() time.Time { return now }
+		return l
+	}
+
+	t.Run("Client", func(t *testing.T) {
+		l := newLimiter(Limit{Rate: 2, Burst: 3}, Limit{}, nil)
+
+		for i := 0; i < 3; i++ {
+			ok, _ := l.Allow("a", "topic")
+			require.True(t, ok)
+		}
+
+		ok, wait := l.Allow("a", "topic")
+		require.False(t, ok)
+		require.Equal(t, 500*time.Millisecond, wait)
+
+		// other clients have their own bucket.
+		ok, _ = l.Allow("b", "topic")
+		require.True(t, ok)
+
+		now = now.Add(500 * time.Millisecond)
+		ok, _ = l.Allow("a", "topic")
+		require.True(t, ok)
+		ok, _ = l.Allow("a", "topic")
+		require.False(t, ok)
+	})
+
+	t.Run("Topic", func(t *testing.T) {
+		l := newLimiter(Limit{Rate: 100}, Limit{Rate: 1}, map[string]Limit{"quotes": {Rate: 10, Burst: 2}})
+
+		ok, _ := l.Allow("a", "logs")
+		require.True(t, ok)
+		ok, wait := l.Allow("b", "logs")
+		require.False(t, ok)
+		require.Equal(t, time.Second, wait)
+
+		ok, _ = l.Allow("a", "quotes")
+		require.True(t, ok)
+		ok, _ = l.Allow("b", "quotes")
+		require.True(t, ok)
+		ok, wait = l.Allow("c", "quotes")
+		require.False(t, ok)
+		require.Equal(t, 100*time.Millisecond, wait)
+
+		// requests without topic are only limited by client.
+		ok, _ = l.Allow("a", "")
+		require.True(t, ok)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		l := newLimiter(Limit{Rate: 1, Burst: 3}, Limit{Rate: 10, Burst: 10}, nil)
+
+		// the client is charged once, the topic once per message.
+		ok, _ := l.AllowN("a", "topic", 8)
+		require.True(t, ok)
+		ok, wait := l.AllowN("a", "topic", 3)
+		require.False(t, ok)
+		require.Equal(t, 100*time.Millisecond, wait)
+		ok, _ = l.AllowN("a", "topic", 2)
+		require.True(t, ok)
+
+		// batches larger than the burst wait for a full bucket and leave it in debt.
+		now = now.Add(time.Second)
+		ok, _ = l.AllowN("b", "topic", 25)
+		require.True(t, ok)
+		ok, wait = l.AllowN("c", "topic", 1)
+		require.False(t, ok)
+		require.Equal(t, 1600*time.Millisecond, wait)
+	})
+
+	t.Run("NoTokenTakenOnRejection", func(t *testing.T) {
+		l := newLimiter(Limit{Rate: 1, Burst: 2}, Limit{Rate: 1}, nil)
+
+		ok, _ := l.Allow("a", "topic")
+		require.True(t, ok)
+		ok, _ = l.Allow("a", "topic")
+		require.False(t, ok)
+
+		// the client bucket wasn't consumed by the rejected request.
+		ok, _ = l.Allow("a", "other")
+		require.True(t, ok)
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		l := newLimiter(Limit{}, Limit{}, nil)
+
+		for i := 0; i < 100; i++ {
+			ok, _ := l.Allow("a", "topic")
+			require.True(t, ok)
+		}
+		require.Empty(t, l.buckets)
+	})
+
+	t.Run("Sweep", func(t *testing.T) {
+		l := newLimiter(Limit{Rate: 1}, Limit{}, nil)
+
+		ok, _ := l.Allow("a", "")
+		require.True(t, ok)
+		require.Len(t, l.buckets, 1)
+
+		now = now.Add(2 * sweepInterval)
+		ok, _ = l.Allow("b", "")
+		require.True(t, ok)
+		require.Len(t, l.buckets, 1)
+	})
+}
+
+func TestClientKey(t *testing.T) {
+	require.Equal(t, "addr:10.0.0.1", ClientKey("", "10.0.0.1"))
+	require.Equal(t, ClientKey("alice", "10.0.0.1"), ClientKey("alice", "10.0.0.2"))
+	require.NotEqual(t, ClientKey("alice", "10.0.0.1"), ClientKey("bob", "10.0.0.1"))
+	require.NotEqual(t, ClientKey("10.0.0.1", ""), ClientKey("", "10.0.0.1"))
+}
+
+func TestRetryAfter(t *testing.T) {
+	require.Equal(t, 1, RetryAfter(100*time.Millisecond))
+	require.Equal(t, 2, RetryAfter(1500*time.Millisecond))
+	require.Equal(t, 1, RetryAfter(0))
+}
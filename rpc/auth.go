@@ -0,0 +1,66 @@
+package rpc
+
+import (
+	"context"
+	"crypto/x509"
+
+	"github.com/asdine/lobby/auth"
+	"github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// token returns the token sent by the client in the authorization metadata, using the Bearer scheme.
+func token(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md["authorization"]) == 0 {
+		return ""
+	}
+
+	return auth.BearerToken(md["authorization"][0])
+}
+
+// authorize checks that the client is granted the permissions on the topic.
+// Every request is authorized if a is nil.
+func authorize(ctx context.Context, a auth.Authenticator, topic string, permissions ...auth.Permission) error {
+	return auth.Authorize(ctx, a, token(ctx), topic, permissions...)
+}
+
+// peerCertificate returns the verified certificate of the client, if any.
+func peerCertificate(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return info.State.VerifiedChains[0][0]
+}
+
+// certificateUnaryInterceptor stores the verified certificate of the client in the context of the request.
+func certificateUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if cert := peerCertificate(ctx); cert != nil {
+		ctx = auth.NewCertificateContext(ctx, cert)
+	}
+
+	return handler(ctx, req)
+}
+
+// certificateStreamInterceptor stores the verified certificate of the client in the context of the stream.
+func certificateStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	cert := peerCertificate(ss.Context())
+	if cert == nil {
+		return handler(srv, ss)
+	}
+
+	return handler(srv, &grpc_middleware.WrappedServerStream{
+		ServerStream:   ss,
+		WrappedContext: auth.NewCertificateContext(ss.Context(), cert),
+	})
+}
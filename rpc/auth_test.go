@@ -0,0 +1,236 @@
+package rpc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/auth"
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/mock"
+	"github.com/asdine/lobby/rpc"
+	"github.com/asdine/lobby/rpc/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+// newAuthenticator returns an authenticator granting the "key" token the permission to send to "quotes"
+// and to read the topics prefixed with "logs-".
+func newAuthenticator() *mock.Authenticator {
+	return &mock.Authenticator{
+		AuthenticateFn: func(ctx context.Context, token string) (*auth.Identity, error) {
+			if token != "key" {
+				return nil, lobby.ErrUnauthenticated
+			}
+
+			return &auth.Identity{
+				Name: "producer",
+				Rules: []auth.Rule{
+					{Topic: "quotes", Permissions: []auth.Permission{auth.Send}},
+					{Topic: "logs-*", Permissions: []auth.Permission{auth.Read}},
+				},
+			}, nil
+		},
+	}
+}
+
+func withToken(token string) context.Context {
+	return metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestAuth(t *testing.T) {
+	var r mock.Registry
+
+	r.TopicFn = func(name string) (lobby.Topic, error) {
+		return new(mock.Topic), nil
+	}
+
+	r.ListFn = func(offset, limit int) ([]lobby.TopicInfo, error) {
+		// the client can't read the topic in the middle of the list.
+		all := []lobby.TopicInfo{
+			{Name: "logs-app", Backend: "bolt"},
+			{Name: "quotes", Backend: "bolt"},
+			{Name: "logs-db", Backend: "bolt"},
+		}
+
+		if offset > len(all) {
+			offset = len(all)
+		}
+		end := len(all)
+		if limit > 0 && offset+limit < end {
+			end = offset + limit
+		}
+		return all[offset:end], nil
+	}
+
+	conn, cleanup := newServerWithAuth(t, &r, newAuthenticator())
+	defer cleanup()
+
+	topics := proto.NewTopicServiceClient(conn)
+	registry := proto.NewRegistryServiceClient(conn)
+	message := proto.NewMessage{
+		Topic:   "quotes",
+		Message: &proto.Message{Value: []byte("value")},
+	}
+
+	t.Run("NoToken", func(t *testing.T) {
+		_, err := topics.Send(context.Background(), &message)
+		require.Equal(t, codes.Unauthenticated, grpc.Code(err))
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		_, err := topics.Send(withToken("other"), &message)
+		require.Equal(t, codes.Unauthenticated, grpc.Code(err))
+	})
+
+	t.Run("PermissionDenied", func(t *testing.T) {
+		_, err := topics.Read(withToken("key"), &proto.ReadMessages{Topic: "quotes"})
+		require.Equal(t, codes.PermissionDenied, grpc.Code(err))
+
+		_, err = registry.Create(withToken("key"), &proto.NewTopic{Name: "quotes", Backend: "bolt"})
+		require.Equal(t, codes.PermissionDenied, grpc.Code(err))
+		require.Zero(t, r.CreateInvoked)
+
+		_, err = registry.Delete(withToken("key"), &proto.Topic{Name: "quotes"})
+		require.Equal(t, codes.PermissionDenied, grpc.Code(err))
+		require.Zero(t, r.DeleteInvoked)
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		id, err := topics.Send(withToken("key"), &message)
+		require.NoError(t, err)
+		require.NotEmpty(t, id.Id)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		_, err := registry.List(context.Background(), new(proto.ListTopics))
+		require.Equal(t, codes.Unauthenticated, grpc.Code(err))
+
+		list, err := registry.List(withToken("key"), new(proto.ListTopics))
+		require.NoError(t, err)
+		require.Len(t, list.Topics, 2)
+		require.Equal(t, "logs-app", list.Topics[0].Name)
+		require.Equal(t, "logs-db", list.Topics[1].Name)
+
+		// pages only count the topics the client can read.
+		list, err = registry.List(withToken("key"), &proto.ListTopics{Offset: 0, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, list.Topics, 1)
+		require.Equal(t, "logs-app", list.Topics[0].Name)
+
+		list, err = registry.List(withToken("key"), &proto.ListTopics{Offset: 1, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, list.Topics, 1)
+		require.Equal(t, "logs-db", list.Topics[0].Name)
+	})
+}
+
+// newCertificate generates a self-signed certificate valid for localhost.
+func newCertificate(t *testing.T, cn string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+}
+
+func TestTLSServer(t *testing.T) {
+	var r mock.Registry
+	r.TopicFn = func(name string) (lobby.Topic, error) {
+		return new(mock.Topic), nil
+	}
+
+	a := mock.Authenticator{
+		AuthenticateCertificateFn: func(ctx context.Context, cert *x509.Certificate) (*auth.Identity, error) {
+			require.Equal(t, "producer", cert.Subject.CommonName)
+
+			return &auth.Identity{
+				Name: "producer",
+				Rules: []auth.Rule{
+					{Topic: "quotes", Permissions: []auth.Permission{auth.Send}},
+				},
+			}, nil
+		},
+	}
+
+	server := newCertificate(t, "lobby")
+	client := newCertificate(t, "producer")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(client.Leaf)
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Leaf)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := rpc.NewTLSServer(log.New(log.Output(ioutil.Discard)), &tls.Config{
+		Certificates: []tls.Certificate{server},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}, rpc.WithRegistryTopicService(&r, &a))
+
+	go func() {
+		srv.Serve(l)
+	}()
+	defer srv.Stop()
+
+	conn, err := grpc.Dial(l.Addr().String(),
+		grpc.WithBlock(),
+		grpc.WithTimeout(time.Second),
+		grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			RootCAs:      rootCAs,
+			Certificates: []tls.Certificate{client},
+		})),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	topics := proto.NewTopicServiceClient(conn)
+
+	_, err = topics.Send(context.Background(), &proto.NewMessage{
+		Topic:   "quotes",
+		Message: &proto.Message{Value: []byte("value")},
+	})
+	require.NoError(t, err)
+
+	_, err = topics.Send(context.Background(), &proto.NewMessage{
+		Topic:   "other",
+		Message: &proto.Message{Value: []byte("value")},
+	})
+	require.Equal(t, codes.PermissionDenied, grpc.Code(err))
+	require.Equal(t, 2, a.AuthenticateCertificateInvoked)
+}
@@ -2,33 +2,48 @@ package rpc
 
 import (
 	"context"
+	"time"
 
 	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
 	"github.com/asdine/lobby/rpc/proto"
+	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 )
 
+// DefaultTimeout is the default maximum duration of the operations of a backend plugin.
+const DefaultTimeout = 10 * time.Second
+
 var _ lobby.Backend = new(Backend)
 
 // NewBackend returns a gRPC backend. It is used to communicate with external backends.
-func NewBackend(conn *grpc.ClientConn) (*Backend, error) {
+// Operations on its topics are aborted after the given timeout. If timeout is zero,
+// operations are only bound to the context they receive.
+func NewBackend(conn *grpc.ClientConn, timeout time.Duration) (*Backend, error) {
 	client := proto.NewTopicServiceClient(conn)
 
 	return &Backend{
-		conn:   conn,
-		client: client,
+		conn:    conn,
+		client:  client,
+		timeout: timeout,
 	}, nil
 }
 
 // Backend is a gRPC backend.
 type Backend struct {
-	conn   *grpc.ClientConn
-	client proto.TopicServiceClient
+	conn    *grpc.ClientConn
+	client  proto.TopicServiceClient
+	timeout time.Duration
+	// name of the plugin, used to label metrics.
+	name string
 }
 
 // Topic returns the topic associated with the given name.
-func (s *Backend) Topic(name string) (lobby.Topic, error) {
-	return NewTopic(name, s.client), nil
+// The options are sent to the plugin with every request.
+func (s *Backend) Topic(name string, options map[string]string) (lobby.Topic, error) {
+	t := NewTopic(name, options, s.client, s.timeout)
+	t.backend = s.name
+	return t, nil
 }
 
 // Close does nothing.
@@ -36,36 +51,154 @@ func (s *Backend) Close() error {
 	return nil
 }
 
-var _ lobby.Topic = new(Topic)
+var _ lobby.TopicReader = new(Topic)
+var _ lobby.BatchSender = new(Topic)
 
-// NewTopic returns a Topic.
-func NewTopic(name string, client proto.TopicServiceClient) *Topic {
+// NewTopic returns a Topic. Operations are aborted after the given timeout, if not zero.
+func NewTopic(name string, options map[string]string, client proto.TopicServiceClient, timeout time.Duration) *Topic {
 	return &Topic{
-		name:   name,
-		client: client,
+		name:    name,
+		options: options,
+		client:  client,
+		timeout: timeout,
 	}
 }
 
 // Topic is a gRPC implementation of a topic.
 type Topic struct {
-	name   string
-	client proto.TopicServiceClient
+	name    string
+	options map[string]string
+	client  proto.TopicServiceClient
+	timeout time.Duration
+	// name of the backend, used to label metrics.
+	backend string
 }
 
 // Send a message to the topic.
-func (t *Topic) Send(message *lobby.Message) error {
-	_, err := t.client.Send(context.Background(), &proto.NewMessage{
-		Topic: t.name,
-		Message: &proto.Message{
-			Group: message.Group,
-			Value: message.Value,
-		},
+func (t *Topic) Send(ctx context.Context, message *lobby.Message) error {
+	ctx, cancel := t.context(ctx)
+	defer cancel()
+
+	start := time.Now()
+	_, err := t.client.Send(ctx, &proto.NewMessage{
+		Topic:   t.name,
+		Message: newMessage(message),
+		Options: t.options,
 	})
+	err = errFromGRPC(err)
+
+	t.recordSend(start, []*lobby.Message{message}, []error{err})
+	return err
+}
+
+// SendBatch sends the messages to the topic. Large batches are split
+// in several calls.
+func (t *Topic) SendBatch(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+	ctx, cancel := t.context(ctx)
+	defer cancel()
+
+	start := time.Now()
+	all := messages
+	errs := make([]error, 0, len(messages))
+
+	// fail reports err for the messages of the failed call and of the following ones, which are not sent.
+	fail := func(err error) ([]error, error) {
+		for len(errs) < len(all) {
+			errs = append(errs, err)
+		}
+		t.recordSend(start, all, errs)
+		return nil, err
+	}
+
+	for len(messages) > 0 {
+		size := maxBatchSize
+		if len(messages) < size {
+			size = len(messages)
+		}
+
+		req := proto.NewMessages{
+			Topic:    t.name,
+			Messages: make([]*proto.Message, size),
+			Options:  t.options,
+		}
+
+		for i := range req.Messages {
+			req.Messages[i] = newMessage(messages[i])
+		}
+
+		resp, err := t.client.SendBatch(ctx, &req)
+		if err != nil {
+			return fail(errFromGRPC(err))
+		}
+
+		if len(resp.Results) != size {
+			return fail(errors.New("unexpected number of batch results"))
+		}
+
+		for _, r := range resp.Results {
+			errs = append(errs, batchResultError(r))
+		}
+
+		messages = messages[size:]
+	}
 
-	return errFromGRPC(err)
+	t.recordSend(start, all, errs)
+	return errs, nil
+}
+
+// Read messages stored in the topic. Messages are fetched by pages
+// until the limit is reached or there are no more messages to read.
+func (t *Topic) Read(ctx context.Context, group, cursor string, limit int) ([]lobby.Message, string, error) {
+	ctx, cancel := t.context(ctx)
+	defer cancel()
+
+	messages := []lobby.Message{}
+
+	for {
+		size := maxPageLimit
+		if limit > 0 && limit-len(messages) < size {
+			size = limit - len(messages)
+		}
+
+		page, err := t.client.Read(ctx, &proto.ReadMessages{
+			Topic:   t.name,
+			Group:   group,
+			Cursor:  cursor,
+			Limit:   int32(size),
+			Options: t.options,
+		})
+		if err != nil {
+			return nil, "", errFromGRPC(err)
+		}
+
+		for _, m := range page.Messages {
+			messages = append(messages, lobbyMessage(m))
+		}
+
+		cursor = page.Next
+		if cursor == "" || (limit > 0 && len(messages) >= limit) {
+			break
+		}
+	}
+
+	return messages, cursor, nil
 }
 
 // Close the topic session.
 func (t *Topic) Close() error {
 	return nil
 }
+
+// context returns a context canceled after the timeout of the topic, if any.
+// The backend is added to the log fields of the request.
+func (t *Topic) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if t.backend != "" {
+		log.AddFields(ctx, log.Fields{"backend": t.backend})
+	}
+
+	if t.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, t.timeout)
+}
@@ -1,11 +1,14 @@
 package rpc_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"io/ioutil"
 	"net"
 	"os"
 	"path"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -14,13 +17,14 @@ import (
 
 	"github.com/asdine/lobby"
 	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/metrics"
 	"github.com/asdine/lobby/mock"
 	"github.com/asdine/lobby/rpc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func newBackend(t *testing.T, b lobby.Backend) (*rpc.Backend, func()) {
+func newBackend(t *testing.T, b lobby.Backend, services ...func(*grpc.Server, *log.Logger)) (*rpc.Backend, func()) {
 	dir, err := ioutil.TempDir("", "lobby")
 	require.NoError(t, err)
 
@@ -28,7 +32,8 @@ func newBackend(t *testing.T, b lobby.Backend) (*rpc.Backend, func()) {
 	l, err := net.Listen("unix", socketPath)
 	require.NoError(t, err)
 
-	srv := rpc.NewServer(log.New(log.Output(ioutil.Discard)), rpc.WithTopicService(b))
+	services = append(services, rpc.WithTopicService(b))
+	srv := rpc.NewServer(log.New(log.Output(ioutil.Discard)), services...)
 
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -37,16 +42,18 @@ func newBackend(t *testing.T, b lobby.Backend) (*rpc.Backend, func()) {
 		srv.Serve(l)
 	}()
 
-	conn, err := grpc.Dial("",
+	opts := append([]grpc.DialOption{
 		grpc.WithInsecure(),
 		grpc.WithBlock(),
 		grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
 			return net.DialTimeout("unix", socketPath, timeout)
 		}),
-	)
+	}, rpc.DialOptions()...)
+
+	conn, err := grpc.Dial("", opts...)
 	require.NoError(t, err)
 
-	backend, err := rpc.NewBackend(conn)
+	backend, err := rpc.NewBackend(conn, rpc.DefaultTimeout)
 	require.NoError(t, err)
 
 	return backend, func() {
@@ -63,13 +70,16 @@ func TestTopicSend(t *testing.T) {
 	t.Run("OK", func(t *testing.T) {
 		var b mock.Backend
 
-		b.TopicFn = func(name string) (lobby.Topic, error) {
+		b.TopicFn = func(name string, options map[string]string) (lobby.Topic, error) {
 			require.Equal(t, "topic", name)
+			require.Equal(t, map[string]string{"key": "value"}, options)
 
 			return &mock.Topic{
-				SendFn: func(message *lobby.Message) error {
+				SendFn: func(ctx context.Context, message *lobby.Message) error {
 					assert.Equal(t, "group", message.Group)
 					assert.Equal(t, []byte(`Value`), message.Value)
+					assert.Equal(t, "id", message.ID)
+					assert.Equal(t, "test", message.Headers["source"])
 					return nil
 				},
 			}, nil
@@ -78,19 +88,27 @@ func TestTopicSend(t *testing.T) {
 		backend, cleanup := newBackend(t, &b)
 		defer cleanup()
 
-		topic, err := backend.Topic("topic")
+		topic, err := backend.Topic("topic", map[string]string{"key": "value"})
 		require.NoError(t, err)
 
-		err = topic.Send(&lobby.Message{
-			Group: "group",
-			Value: []byte("Value"),
+		err = topic.Send(context.Background(), &lobby.Message{
+			ID:      "id",
+			Group:   "group",
+			Value:   []byte("Value"),
+			Headers: map[string]string{"source": "test"},
 		})
 		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		_, err = metrics.DefaultRegistry.WriteTo(&buf)
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), `lobby_backend_messages_total{backend="",topic="topic"}`)
+		require.Contains(t, buf.String(), `lobby_grpc_requests_total{method="/proto.TopicService/Send",code="OK"}`)
 	})
 
 	t.Run("TopicNotFound", func(t *testing.T) {
 		var b mock.Backend
-		b.TopicFn = func(name string) (lobby.Topic, error) {
+		b.TopicFn = func(name string, options map[string]string) (lobby.Topic, error) {
 			assert.Equal(t, "unknown", name)
 			return nil, lobby.ErrTopicNotFound
 		}
@@ -98,25 +116,26 @@ func TestTopicSend(t *testing.T) {
 		backend, cleanup := newBackend(t, &b)
 		defer cleanup()
 
-		topic, err := backend.Topic("unknown")
+		topic, err := backend.Topic("unknown", nil)
 		require.NoError(t, err)
 
-		err = topic.Send(&lobby.Message{
+		err = topic.Send(context.Background(), &lobby.Message{
 			Group: "group",
 			Value: []byte("Value"),
 		})
 		require.Error(t, err)
 		require.Equal(t, lobby.ErrTopicNotFound, err)
+		require.False(t, rpc.IsTransient(err))
 	})
 
 	t.Run("InternalError", func(t *testing.T) {
 		var b mock.Backend
 
-		b.TopicFn = func(name string) (lobby.Topic, error) {
+		b.TopicFn = func(name string, options map[string]string) (lobby.Topic, error) {
 			require.Equal(t, "topic", name)
 
 			return &mock.Topic{
-				SendFn: func(message *lobby.Message) error {
+				SendFn: func(ctx context.Context, message *lobby.Message) error {
 					assert.Equal(t, "group", message.Group)
 					assert.Equal(t, []byte(`Value`), message.Value)
 					return errors.New("something unexpected happened !")
@@ -127,13 +146,205 @@ func TestTopicSend(t *testing.T) {
 		backend, cleanup := newBackend(t, &b)
 		defer cleanup()
 
-		topic, err := backend.Topic("topic")
+		topic, err := backend.Topic("topic", nil)
 		require.NoError(t, err)
 
-		err = topic.Send(&lobby.Message{
+		err = topic.Send(context.Background(), &lobby.Message{
 			Group: "group",
 			Value: []byte("Value"),
 		})
 		require.Error(t, err)
+		require.True(t, rpc.IsTransient(err))
+	})
+}
+
+func TestTopicSendDeadline(t *testing.T) {
+	var b mock.Backend
+
+	b.TopicFn = func(name string, options map[string]string) (lobby.Topic, error) {
+		return &mock.Topic{
+			SendFn: func(ctx context.Context, message *lobby.Message) error {
+				_, ok := ctx.Deadline()
+				assert.True(t, ok)
+
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}, nil
+	}
+
+	backend, cleanup := newBackend(t, &b)
+	defer cleanup()
+
+	topic, err := backend.Topic("topic", nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = topic.Send(ctx, &lobby.Message{
+		Group: "group",
+		Value: []byte("Value"),
+	})
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.True(t, rpc.IsTransient(err))
+}
+
+func TestTopicSendBatch(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		var b mock.Backend
+
+		b.TopicFn = func(name string, options map[string]string) (lobby.Topic, error) {
+			require.Equal(t, "topic", name)
+
+			return &mock.Topic{
+				SendBatchFn: func(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+					require.Len(t, messages, 2)
+					assert.Equal(t, "id", messages[0].ID)
+					return []error{nil, lobby.ErrNotSupported}, nil
+				},
+			}, nil
+		}
+
+		backend, cleanup := newBackend(t, &b)
+		defer cleanup()
+
+		topic, err := backend.Topic("topic", nil)
+		require.NoError(t, err)
+
+		errs, err := topic.(lobby.BatchSender).SendBatch(context.Background(), []*lobby.Message{
+			{ID: "id", Value: []byte("Value1")},
+			{ID: "id2", Value: []byte("Value2")},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []error{nil, lobby.ErrNotSupported}, errs)
+	})
+
+	t.Run("Transient", func(t *testing.T) {
+		var b mock.Backend
+
+		b.TopicFn = func(name string, options map[string]string) (lobby.Topic, error) {
+			return &mock.Topic{
+				SendBatchFn: func(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+					return []error{errors.New("connection refused"), lobby.ErrTopicNotFound}, nil
+				},
+			}, nil
+		}
+
+		backend, cleanup := newBackend(t, &b)
+		defer cleanup()
+
+		topic, err := backend.Topic("topic", nil)
+		require.NoError(t, err)
+
+		errs, err := topic.(lobby.BatchSender).SendBatch(context.Background(), []*lobby.Message{
+			{ID: "id", Value: []byte("Value1")},
+			{ID: "id2", Value: []byte("Value2")},
+		})
+		require.NoError(t, err)
+		require.Len(t, errs, 2)
+		require.True(t, rpc.IsTransient(errs[0]))
+		require.Equal(t, lobby.ErrTopicNotFound, errs[1])
+		require.False(t, rpc.IsTransient(errs[1]))
+	})
+
+	t.Run("TopicNotFound", func(t *testing.T) {
+		var b mock.Backend
+		b.TopicFn = func(name string, options map[string]string) (lobby.Topic, error) {
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		backend, cleanup := newBackend(t, &b)
+		defer cleanup()
+
+		topic, err := backend.Topic("unknown", nil)
+		require.NoError(t, err)
+
+		_, err = topic.(lobby.BatchSender).SendBatch(context.Background(), []*lobby.Message{{Value: []byte("Value")}})
+		require.Equal(t, lobby.ErrTopicNotFound, err)
+	})
+}
+
+func TestTopicRead(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		messages := make([]lobby.Message, 250)
+		for i := range messages {
+			messages[i] = lobby.Message{Group: "group", Value: []byte(strconv.Itoa(i))}
+		}
+
+		var b mock.Backend
+		b.TopicFn = func(name string, options map[string]string) (lobby.Topic, error) {
+			return &mock.Topic{
+				ReadFn: func(ctx context.Context, group, cursor string, limit int) ([]lobby.Message, string, error) {
+					assert.Equal(t, "group", group)
+
+					var offset int
+					if cursor != "" {
+						offset, _ = strconv.Atoi(cursor)
+					}
+
+					list := messages[offset:]
+					if limit < len(list) {
+						return list[:limit], strconv.Itoa(offset + limit), nil
+					}
+					return list, "", nil
+				},
+			}, nil
+		}
+
+		backend, cleanup := newBackend(t, &b)
+		defer cleanup()
+
+		topic, err := backend.Topic("topic", nil)
+		require.NoError(t, err)
+
+		list, next, err := topic.(lobby.TopicReader).Read(context.Background(), "group", "", 0)
+		require.NoError(t, err)
+		require.Equal(t, messages, list)
+		require.Empty(t, next)
+
+		list, next, err = topic.(lobby.TopicReader).Read(context.Background(), "group", "10", 150)
+		require.NoError(t, err)
+		require.Len(t, list, 150)
+		require.Equal(t, "10", string(list[0].Value))
+		require.Equal(t, "160", next)
+	})
+
+	t.Run("NotSupported", func(t *testing.T) {
+		var b mock.Backend
+		b.TopicFn = func(name string, options map[string]string) (lobby.Topic, error) {
+			return lobby.TopicFunc(func(context.Context, *lobby.Message) error {
+				return nil
+			}), nil
+		}
+
+		backend, cleanup := newBackend(t, &b)
+		defer cleanup()
+
+		topic, err := backend.Topic("topic", nil)
+		require.NoError(t, err)
+
+		_, _, err = topic.(lobby.TopicReader).Read(context.Background(), "", "", 10)
+		require.Equal(t, lobby.ErrNotSupported, err)
+	})
+
+	t.Run("InvalidCursor", func(t *testing.T) {
+		var b mock.Backend
+		b.TopicFn = func(name string, options map[string]string) (lobby.Topic, error) {
+			return &mock.Topic{
+				ReadFn: func(ctx context.Context, group, cursor string, limit int) ([]lobby.Message, string, error) {
+					return nil, "", lobby.ErrInvalidCursor
+				},
+			}, nil
+		}
+
+		backend, cleanup := newBackend(t, &b)
+		defer cleanup()
+
+		topic, err := backend.Topic("topic", nil)
+		require.NoError(t, err)
+
+		_, _, err = topic.(lobby.TopicReader).Read(context.Background(), "", "cursor", 10)
+		require.Equal(t, lobby.ErrInvalidCursor, err)
 	})
 }
@@ -0,0 +1,34 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DialOptions returns the options of the client connections between Lobby and its plugins.
+// They propagate the trace context and the id of the requests, and measure unary requests
+// with a client span.
+func DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithUnaryInterceptor(grpc_middleware.ChainUnaryClient(
+			traceUnaryClientInterceptor,
+			requestIDUnaryClientInterceptor,
+		)),
+		grpc.WithStreamInterceptor(grpc_middleware.ChainStreamClient(
+			traceStreamClientInterceptor,
+			requestIDStreamClientInterceptor,
+		)),
+	}
+}
+
+// withMetadata returns a context sending the key-value pair in the metadata of the requests,
+// in addition to the metadata already set.
+func withMetadata(ctx context.Context, key, value string) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md[key] = []string{value}
+	return metadata.NewOutgoingContext(ctx, md)
+}
@@ -1,10 +1,12 @@
 package rpc
 
 import (
+	"context"
 	"strings"
 
 	"github.com/asdine/lobby"
 	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/rpc/proto"
 	"github.com/asdine/lobby/validation"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -18,23 +20,58 @@ const (
 	ErrEmptyContent = lobby.Error("empty_content")
 )
 
-// Error writes an API error message to the response and logger.
-func newError(err error, logger *log.Logger) error {
-	var code codes.Code
+// Validation errors.
+const (
+	errInvalidOffset = lobby.Error("must be a positive integer")
+	errInvalidLimit  = lobby.Error("must be between 0 and 100")
+	errInvalidBatch  = lobby.Error("must contain between 1 and 1000 messages")
+)
+
+// Pagination limits.
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// maxBatchSize is the maximum number of messages that can be sent in a batch.
+const maxBatchSize = 1000
 
+// errorCode returns the gRPC code of an error.
+func errorCode(err error) codes.Code {
 	switch {
-	case validation.IsError(err):
-		code = codes.InvalidArgument
+	case validation.IsError(err) || err == lobby.ErrInvalidCursor || err == lobby.ErrMessageTooLarge:
+		return codes.InvalidArgument
 	case err == lobby.ErrTopicNotFound || err == lobby.ErrBackendNotFound:
-		code = codes.NotFound
+		return codes.NotFound
 	case err == lobby.ErrTopicAlreadyExists:
-		code = codes.AlreadyExists
+		return codes.AlreadyExists
+	case err == lobby.ErrNotSupported:
+		return codes.Unimplemented
+	case err == lobby.ErrSlowConsumer || err == lobby.ErrRateLimited:
+		return codes.ResourceExhausted
+	case err == lobby.ErrUnauthenticated:
+		return codes.Unauthenticated
+	case err == lobby.ErrPermissionDenied:
+		return codes.PermissionDenied
+	case err == context.DeadlineExceeded:
+		return codes.DeadlineExceeded
+	case err == context.Canceled:
+		return codes.Canceled
 	default:
-		code = codes.Unknown
+		return codes.Unknown
 	}
+}
 
-	// Log error.
-	logger.Debugf("grpc error: %s (code=%s)", err, code.String())
+// Error writes an API error message to the response and logger.
+func newError(err error, logger *log.Logger) error {
+	code := errorCode(err)
+
+	// Log error. Unknown errors are unexpected, the others are caused by the client.
+	if code == codes.Unknown {
+		logger.Errorf("grpc error: %s (code=%s)", err, code.String())
+	} else {
+		logger.Debugf("grpc error: %s (code=%s)", err, code.String())
+	}
 
 	// Hide error from client if it is internal.
 	if code == codes.Unknown {
@@ -44,12 +81,83 @@ func newError(err error, logger *log.Logger) error {
 	return status.Error(code, err.Error())
 }
 
+// batchError returns the error reported to the client for a message of a batch
+// and its gRPC code, the same as if the message was sent alone.
+// Internal errors are hidden from the client.
+func batchError(err error, logger *log.Logger) (string, codes.Code) {
+	code := errorCode(err)
+
+	if _, ok := err.(lobby.Error); ok || validation.IsError(err) {
+		return err.Error(), code
+	}
+
+	logger.Debugf("grpc batch error: %s", err)
+	return ErrInternal.Error(), code
+}
+
+// batchResultError returns the error of a message of a batch, converted
+// the same way as the errors returned by Send.
+func batchResultError(r *proto.BatchResult) error {
+	if r.Error == "" {
+		return nil
+	}
+
+	// plugins built before the codes were reported.
+	if r.Code == 0 {
+		return lobby.Error(r.Error)
+	}
+
+	return errFromGRPC(status.Error(codes.Code(r.Code), r.Error))
+}
+
+// IsTransient returns true if err, returned by a topic of a gRPC backend, may not happen again
+// if the operation is retried later, e.g. if the plugin or its datastore is unavailable.
+func IsTransient(err error) bool {
+	if _, ok := err.(lobby.Error); ok || err == nil || validation.IsError(err) {
+		return false
+	}
+
+	switch grpc.Code(err) {
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied,
+		codes.Unauthenticated, codes.Unimplemented, codes.FailedPrecondition, codes.OutOfRange:
+		return false
+	}
+
+	return true
+}
+
 func errFromGRPC(err error) error {
 	code := grpc.Code(err)
 
 	switch code {
 	case codes.AlreadyExists:
 		return lobby.ErrTopicAlreadyExists
+	case codes.Unimplemented:
+		return lobby.ErrNotSupported
+	case codes.ResourceExhausted:
+		if strings.Contains(err.Error(), lobby.ErrRateLimited.Error()) {
+			return lobby.ErrRateLimited
+		}
+
+		return lobby.ErrSlowConsumer
+	case codes.Unauthenticated:
+		return lobby.ErrUnauthenticated
+	case codes.PermissionDenied:
+		return lobby.ErrPermissionDenied
+	case codes.DeadlineExceeded:
+		return context.DeadlineExceeded
+	case codes.Canceled:
+		return context.Canceled
+	case codes.InvalidArgument:
+		if strings.Contains(err.Error(), lobby.ErrInvalidCursor.Error()) {
+			return lobby.ErrInvalidCursor
+		}
+
+		if strings.Contains(err.Error(), lobby.ErrMessageTooLarge.Error()) {
+			return lobby.ErrMessageTooLarge
+		}
+
+		return err
 	case codes.NotFound:
 		if strings.Contains(err.Error(), lobby.ErrBackendNotFound.Error()) {
 			return lobby.ErrBackendNotFound
@@ -0,0 +1,95 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// WithHealthService enables the standard gRPC health service. The server is reported as serving
+// as long as the checker is healthy. If checker is nil, the server is always reported as serving.
+func WithHealthService(checker lobby.HealthChecker) func(*grpc.Server, *log.Logger) {
+	return func(g *grpc.Server, logger *log.Logger) {
+		healthpb.RegisterHealthServer(g, newHealthService(checker, logger))
+	}
+}
+
+func newHealthService(checker lobby.HealthChecker, logger *log.Logger) *healthService {
+	s := healthService{
+		Server:  health.NewServer(),
+		checker: checker,
+		logger:  logger,
+	}
+
+	s.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	return &s
+}
+
+// healthService checks the health of the server on every call to Check.
+// The other methods of the health protocol report the result of the last check.
+type healthService struct {
+	*health.Server
+
+	checker lobby.HealthChecker
+	logger  *log.Logger
+}
+
+// Check the health of the server. Only the overall health, named "", is supported.
+func (s *healthService) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if req.Service != "" {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+
+	st := healthpb.HealthCheckResponse_SERVING
+	if s.checker != nil {
+		err := s.checker.Health(ctx)
+		if err != nil {
+			s.logger.Debugf("health check failed: %s", err)
+			st = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+
+	s.SetServingStatus("", st)
+	return &healthpb.HealthCheckResponse{Status: st}, nil
+}
+
+// Health checks the health of the plugin using the gRPC health protocol.
+// Plugins that don't implement it are considered healthy.
+func (s *Backend) Health(ctx context.Context) error {
+	if s.timeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	resp, err := healthpb.NewHealthClient(s.conn).Check(ctx, new(healthpb.HealthCheckRequest))
+	if err != nil {
+		if grpc.Code(err) == codes.Unimplemented {
+			return nil
+		}
+
+		return errFromGRPC(err)
+	}
+
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return errors.Errorf("plugin is %s", resp.Status)
+	}
+
+	return nil
+}
+
+// Health checks the health of the running instance of the plugin.
+func (b *supervisedBackend) Health(ctx context.Context) error {
+	b.s.m.RLock()
+	bck := b.s.backend
+	b.s.m.RUnlock()
+
+	return bck.Health(ctx)
+}
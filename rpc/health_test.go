@@ -0,0 +1,54 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/asdine/lobby/mock"
+	"github.com/asdine/lobby/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackendHealth(t *testing.T) {
+	t.Run("Healthy", func(t *testing.T) {
+		var c mock.HealthChecker
+
+		bck, cleanup := newBackend(t, new(mock.Backend), rpc.WithHealthService(&c))
+		defer cleanup()
+
+		err := bck.Health(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 1, c.HealthInvoked)
+	})
+
+	t.Run("Unhealthy", func(t *testing.T) {
+		c := mock.HealthChecker{
+			HealthFn: func(ctx context.Context) error {
+				return errors.New("connection refused")
+			},
+		}
+
+		bck, cleanup := newBackend(t, new(mock.Backend), rpc.WithHealthService(&c))
+		defer cleanup()
+
+		err := bck.Health(context.Background())
+		require.EqualError(t, err, "plugin is NOT_SERVING")
+	})
+
+	t.Run("NoChecker", func(t *testing.T) {
+		bck, cleanup := newBackend(t, new(mock.Backend), rpc.WithHealthService(nil))
+		defer cleanup()
+
+		err := bck.Health(context.Background())
+		require.NoError(t, err)
+	})
+
+	t.Run("Unimplemented", func(t *testing.T) {
+		bck, cleanup := newBackend(t, new(mock.Backend))
+		defer cleanup()
+
+		err := bck.Health(context.Background())
+		require.NoError(t, err)
+	})
+}
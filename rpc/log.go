@@ -0,0 +1,66 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/trace"
+	"github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDKey is the metadata holding the id of a request.
+const requestIDKey = "x-request-id"
+
+// requestContext returns a context holding the log fields of the request.
+// The request id sent by the client is reused, if any, so that the logs of Lobby
+// and of its plugins can be correlated.
+func requestContext(ctx context.Context, method string) context.Context {
+	id := log.NewRequestID()
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md[requestIDKey]) > 0 && md[requestIDKey][0] != "" {
+		id = md[requestIDKey][0]
+	}
+
+	fields := log.Fields{
+		log.RequestIDKey: id,
+		"method":         method,
+	}
+
+	if sc, ok := trace.FromContext(ctx); ok {
+		fields["trace_id"] = sc.TraceID.String()
+	}
+
+	return log.NewContext(ctx, fields)
+}
+
+// logUnaryInterceptor attaches the log fields of the request to its context.
+func logUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(requestContext(ctx, info.FullMethod), req)
+}
+
+// logStreamInterceptor attaches the log fields of the stream to its context.
+func logStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &grpc_middleware.WrappedServerStream{
+		ServerStream:   ss,
+		WrappedContext: requestContext(ss.Context(), info.FullMethod),
+	})
+}
+
+// withRequestID adds the request id stored in ctx, if any, to the outgoing metadata.
+func withRequestID(ctx context.Context) context.Context {
+	id := log.RequestID(ctx)
+	if id == "" {
+		return ctx
+	}
+
+	return withMetadata(ctx, requestIDKey, id)
+}
+
+func requestIDUnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(withRequestID(ctx), method, req, reply, cc, opts...)
+}
+
+func requestIDStreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(withRequestID(ctx), desc, cc, method, opts...)
+}
@@ -0,0 +1,48 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLogFields(t *testing.T) {
+	var b mock.Backend
+	var fields log.Fields
+	b.TopicFn = func(name string, options map[string]string) (lobby.Topic, error) {
+		return &mock.Topic{
+			SendFn: func(ctx context.Context, message *lobby.Message) error {
+				fields = log.FieldsFromContext(ctx)
+				return nil
+			},
+		}, nil
+	}
+
+	backend, cleanup := newBackend(t, &b)
+	defer cleanup()
+
+	topic, err := backend.Topic("topic", nil)
+	require.NoError(t, err)
+
+	t.Run("Propagated", func(t *testing.T) {
+		ctx := log.NewContext(context.Background(), log.Fields{log.RequestIDKey: "abc"})
+		err = topic.Send(ctx, &lobby.Message{Value: []byte("Value")})
+		require.NoError(t, err)
+
+		require.Equal(t, "abc", fields[log.RequestIDKey])
+		require.Equal(t, "topic", fields["topic"])
+		require.Equal(t, "/proto.TopicService/Send", fields["method"])
+		require.Len(t, fields["trace_id"], 32)
+	})
+
+	t.Run("Generated", func(t *testing.T) {
+		err = topic.Send(context.Background(), &lobby.Message{Value: []byte("Value")})
+		require.NoError(t, err)
+
+		require.Len(t, fields[log.RequestIDKey], 16)
+	})
+}
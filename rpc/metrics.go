@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/metrics"
+	"google.golang.org/grpc"
+)
+
+var (
+	grpcRequests = metrics.NewCounter(
+		"lobby_grpc_requests_total",
+		"Number of gRPC requests handled, by method and status code.",
+		"method", "code",
+	)
+	grpcRequestDuration = metrics.NewHistogram(
+		"lobby_grpc_request_duration_seconds",
+		"Duration of the gRPC requests, by method.",
+		nil,
+		"method",
+	)
+	backendMessages = metrics.NewCounter(
+		"lobby_backend_messages_total",
+		"Number of messages sent to the topics of backend plugins, by backend and topic.",
+		"backend", "topic",
+	)
+	backendMessageBytes = metrics.NewCounter(
+		"lobby_backend_message_bytes_total",
+		"Size of the values of the messages sent to the topics of backend plugins, by backend and topic.",
+		"backend", "topic",
+	)
+	backendSendErrors = metrics.NewCounter(
+		"lobby_backend_send_errors_total",
+		"Number of messages that couldn't be sent to the topics of backend plugins, by backend and topic.",
+		"backend", "topic",
+	)
+	backendSendDuration = metrics.NewHistogram(
+		"lobby_backend_send_duration_seconds",
+		"Duration of the calls sending messages to backend plugins, by backend and topic.",
+		nil,
+		"backend", "topic",
+	)
+	pluginRestarts = metrics.NewCounter(
+		"lobby_plugin_restarts_total",
+		"Number of times a crashed plugin was restarted, by plugin.",
+		"plugin",
+	)
+)
+
+// metricsUnaryInterceptor records the number and the duration of the requests.
+func metricsUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	grpcRequests.Inc(info.FullMethod, grpc.Code(err).String())
+	grpcRequestDuration.Observe(time.Since(start).Seconds(), info.FullMethod)
+	return resp, err
+}
+
+// metricsStreamInterceptor records the number and the duration of the streams.
+func metricsStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+
+	grpcRequests.Inc(info.FullMethod, grpc.Code(err).String())
+	grpcRequestDuration.Observe(time.Since(start).Seconds(), info.FullMethod)
+	return err
+}
+
+// recordSend records the messages sent to a topic since start. errs contains the error of each message, if any.
+func (t *Topic) recordSend(start time.Time, messages []*lobby.Message, errs []error) {
+	backendSendDuration.Observe(time.Since(start).Seconds(), t.backend, t.name)
+
+	for i, m := range messages {
+		if errs[i] != nil {
+			backendSendErrors.Inc(t.backend, t.name)
+			continue
+		}
+
+		backendMessages.Inc(t.backend, t.name)
+		backendMessageBytes.Add(float64(len(m.Value)), t.backend, t.name)
+	}
+}
@@ -114,51 +114,115 @@ func LoadPlugin(ctx context.Context, name, cmdPath, dataDir, configFile string)
 	}, nil
 }
 
-// LoadBackendPlugin loads a backend plugin.
-func LoadBackendPlugin(ctx context.Context, name, cmdPath, dataDir, configFile string) (lobby.Backend, lobby.Plugin, error) {
-	plugin, err := LoadPlugin(ctx, name, cmdPath, dataDir, configFile)
+// LoadBackendPlugin loads a backend plugin. The operations on the topics of the plugin
+// are aborted after the given timeout.
+func LoadBackendPlugin(ctx context.Context, name, cmdPath, dataDir, configFile string, timeout time.Duration) (lobby.Backend, lobby.Plugin, error) {
+	bck, plugin, err := loadBackendPlugin(ctx, name, cmdPath, dataDir, configFile, timeout)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	return bck, plugin, nil
+}
+
+func loadBackendPlugin(ctx context.Context, name, cmdPath, dataDir, configFile string, timeout time.Duration) (*Backend, lobby.Plugin, error) {
 	socketPath := path.Join(dataDir, "sockets", fmt.Sprintf("%s.sock", name))
-	ticker := time.NewTicker(10 * time.Millisecond)
-	defer ticker.Stop()
 
-Loop:
-	for {
-		select {
-		case <-ticker.C:
-			if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
-				break Loop
-			}
-		case <-ctx.Done():
-			err := plugin.Close()
-			if err != nil {
-				return nil, nil, errors.Wrapf(err, "failed to kill process %s", name)
-			}
+	err := removeSocket(name, socketPath)
+	if err != nil {
+		return nil, nil, err
+	}
 
-			return nil, nil, ctx.Err()
-		}
+	plugin, err := LoadPlugin(ctx, name, cmdPath, dataDir, configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = waitForSocket(ctx, plugin, socketPath)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	conn, err := grpc.Dial("",
+	opts := append([]grpc.DialOption{
 		grpc.WithInsecure(),
 		grpc.WithBlock(),
-		grpc.WithTimeout(1*time.Second),
+		grpc.WithTimeout(1 * time.Second),
 		grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
 			return net.DialTimeout("unix", socketPath, timeout)
 		}),
-	)
+	}, DialOptions()...)
+
+	conn, err := grpc.Dial("", opts...)
 	if err != nil {
+		plugin.Close()
 		return nil, nil, err
 	}
 
 	plugin.(*process).conn = conn
-	bck, err := NewBackend(conn)
+	bck, err := NewBackend(conn, timeout)
 	if err != nil {
 		return nil, nil, err
 	}
+	bck.name = name
 
 	return bck, plugin, nil
 }
+
+// LoadServerPlugin loads a server plugin. Server plugins connect to Lobby by themselves,
+// the plugin is considered started once its socket is created.
+// The socket of a server plugin is suffixed by "-server" so that a plugin can provide
+// both a backend and a server under the same name.
+func LoadServerPlugin(ctx context.Context, name, cmdPath, dataDir, configFile string) (lobby.Plugin, error) {
+	socketPath := path.Join(dataDir, "sockets", fmt.Sprintf("%s-server.sock", name))
+
+	err := removeSocket(name, socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	plugin, err := LoadPlugin(ctx, name, cmdPath, dataDir, configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	err = waitForSocket(ctx, plugin, socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return plugin, nil
+}
+
+// removeSocket removes the socket left behind by a plugin that crashed, otherwise
+// it would be mistaken for the socket of the new process.
+func removeSocket(name, socketPath string) error {
+	err := os.Remove(socketPath)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove socket of plugin %s", name)
+	}
+
+	return nil
+}
+
+// waitForSocket blocks until the plugin creates its socket.
+// The plugin is killed if the context is canceled before.
+func waitForSocket(ctx context.Context, plugin lobby.Plugin, socketPath string) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+				return nil
+			}
+		case <-ctx.Done():
+			err := plugin.Close()
+			if err != nil {
+				return errors.Wrapf(err, "failed to kill process %s", plugin.Name())
+			}
+
+			return ctx.Err()
+		}
+	}
+}
@@ -11,17 +11,18 @@ import (
 	"path"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
 
-func setFakeCommand(t *testing.T, additionalArgs ...string) func() {
+func setFakeCommand(t *testing.T, socketName string, additionalArgs ...string) func() {
 	execCommand = func(command string, args ...string) *exec.Cmd {
 		cs := []string{"-test.run=TestHelperProcess", "--", command}
 		cs = append(cs, args...)
 		cs = append(cs, additionalArgs...)
 		cmd := exec.Command(os.Args[0], cs...)
-		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1", "GO_HELPER_SOCKET=" + socketName}
 		return cmd
 	}
 
@@ -52,7 +53,7 @@ func TestHelperProcess(t *testing.T) {
 	require.Equal(t, "/fake/command", cmd)
 	require.Len(t, args, 2)
 	require.Equal(t, "--data-dir", args[0])
-	l, err := net.Listen("unix", path.Join(args[1], "sockets", "backend.sock"))
+	l, err := net.Listen("unix", path.Join(args[1], "sockets", os.Getenv("GO_HELPER_SOCKET")))
 	require.NoError(t, err)
 	defer l.Close()
 
@@ -65,7 +66,7 @@ func TestHelperProcess(t *testing.T) {
 }
 
 func TestLoadBackend(t *testing.T) {
-	cleanup := setFakeCommand(t)
+	cleanup := setFakeCommand(t, "backend.sock")
 	defer cleanup()
 
 	dir, err := ioutil.TempDir("", "lobby")
@@ -74,7 +75,7 @@ func TestLoadBackend(t *testing.T) {
 	err = os.Mkdir(path.Join(dir, "sockets"), 0755)
 	require.NoError(t, err)
 
-	bck, plg, err := LoadBackendPlugin(context.Background(), "backend", "/fake/command", dir, "")
+	bck, plg, err := LoadBackendPlugin(context.Background(), "backend", "/fake/command", dir, "", DefaultTimeout)
 	require.NoError(t, err)
 	require.Equal(t, "backend", plg.Name())
 	err = bck.Close()
@@ -83,8 +84,8 @@ func TestLoadBackend(t *testing.T) {
 	require.NoError(t, err)
 }
 
-func TestLoadServer(t *testing.T) {
-	cleanup := setFakeCommand(t)
+func TestLoadPlugin(t *testing.T) {
+	cleanup := setFakeCommand(t, "plugin.sock")
 	defer cleanup()
 
 	dir, err := ioutil.TempDir("", "lobby")
@@ -93,16 +94,68 @@ func TestLoadServer(t *testing.T) {
 	err = os.Mkdir(path.Join(dir, "sockets"), 0755)
 	require.NoError(t, err)
 
-	plg, err := LoadPlugin(context.Background(), "server", "/fake/command", dir, "")
+	plg, err := LoadPlugin(context.Background(), "plugin", "/fake/command", dir, "")
 	require.NoError(t, err)
-	require.Equal(t, "server", plg.Name())
+	require.Equal(t, "plugin", plg.Name())
 	err = plg.Close()
 	require.NoError(t, err)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	_, err = LoadPlugin(ctx, "server", "/fake/command", dir, "")
+	_, err = LoadPlugin(ctx, "plugin", "/fake/command", dir, "")
 	require.Error(t, err)
 	require.Equal(t, context.Canceled, err)
 }
+
+func TestLoadServer(t *testing.T) {
+	cleanup := setFakeCommand(t, "nsq-server.sock")
+	defer cleanup()
+
+	dir, err := ioutil.TempDir("", "lobby")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	err = os.Mkdir(path.Join(dir, "sockets"), 0755)
+	require.NoError(t, err)
+
+	plg, err := LoadServerPlugin(context.Background(), "nsq", "/fake/command", dir, "")
+	require.NoError(t, err)
+	require.Equal(t, "nsq", plg.Name())
+	_, err = os.Stat(path.Join(dir, "sockets", "nsq-server.sock"))
+	require.NoError(t, err)
+	err = plg.Close()
+	require.NoError(t, err)
+
+	// the plugin never creates the expected socket.
+	setFakeCommand(t, "other.sock")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = LoadServerPlugin(ctx, "nsq2", "/fake/command", dir, "")
+	require.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestLoadServerStaleSocket(t *testing.T) {
+	cleanup := setFakeCommand(t, "nsq-server.sock")
+	defer cleanup()
+
+	dir, err := ioutil.TempDir("", "lobby")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	err = os.Mkdir(path.Join(dir, "sockets"), 0755)
+	require.NoError(t, err)
+
+	// left behind by a plugin that crashed.
+	socketPath := path.Join(dir, "sockets", "nsq-server.sock")
+	err = ioutil.WriteFile(socketPath, nil, 0600)
+	require.NoError(t, err)
+
+	plg, err := LoadServerPlugin(context.Background(), "nsq", "/fake/command", dir, "")
+	require.NoError(t, err)
+	defer plg.Close()
+
+	// the socket is the one created by the new process.
+	conn, err := net.Dial("unix", socketPath)
+	require.NoError(t, err)
+	conn.Close()
+}
@@ -25,6 +25,8 @@ type NewTopic struct {
 	// Backend used by this topic.
 	// @inject_tag: valid:"required"
 	Backend string `protobuf:"bytes,2,opt,name=backend" json:"backend,omitempty" valid:"required"`
+	// Options passed to the backend when the topic is used.
+	Options map[string]string `protobuf:"bytes,3,rep,name=options" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *NewTopic) Reset()                    { *m = NewTopic{} }
@@ -32,12 +34,23 @@ func (m *NewTopic) String() string            { return proto1.CompactTextString(
 func (*NewTopic) ProtoMessage()               {}
 func (*NewTopic) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
 
+func (m *NewTopic) GetOptions() map[string]string {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
 type Topic struct {
 	// Topic name.
 	// @inject_tag: valid:"required"
 	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty" valid:"required"`
 	// Backend used by this topic.
 	Backend string `protobuf:"bytes,2,opt,name=backend" json:"backend,omitempty"`
+	// Creation date of the topic, in nanoseconds since the Unix epoch.
+	CreatedAt int64 `protobuf:"varint,3,opt,name=created_at,json=createdAt" json:"created_at,omitempty"`
+	// Options passed to the backend when the topic is used.
+	Options map[string]string `protobuf:"bytes,4,rep,name=options" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *Topic) Reset()                    { *m = Topic{} }
@@ -45,6 +58,13 @@ func (m *Topic) String() string            { return proto1.CompactTextString(m)
 func (*Topic) ProtoMessage()               {}
 func (*Topic) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
 
+func (m *Topic) GetOptions() map[string]string {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
 type TopicStatus struct {
 	Exists bool `protobuf:"varint,1,opt,name=exists" json:"exists,omitempty"`
 }
@@ -54,10 +74,68 @@ func (m *TopicStatus) String() string            { return proto1.CompactTextStri
 func (*TopicStatus) ProtoMessage()               {}
 func (*TopicStatus) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
 
+// ListTopics is used to paginate the list of topics.
+type ListTopics struct {
+	// Number of topics to skip.
+	Offset int32 `protobuf:"varint,1,opt,name=offset" json:"offset,omitempty"`
+	// Maximum number of topics to return.
+	Limit int32 `protobuf:"varint,2,opt,name=limit" json:"limit,omitempty"`
+}
+
+func (m *ListTopics) Reset()                    { *m = ListTopics{} }
+func (m *ListTopics) String() string            { return proto1.CompactTextString(m) }
+func (*ListTopics) ProtoMessage()               {}
+func (*ListTopics) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+
+type TopicList struct {
+	Topics []*Topic `protobuf:"bytes,1,rep,name=topics" json:"topics,omitempty"`
+}
+
+func (m *TopicList) Reset()                    { *m = TopicList{} }
+func (m *TopicList) String() string            { return proto1.CompactTextString(m) }
+func (*TopicList) ProtoMessage()               {}
+func (*TopicList) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
+
+func (m *TopicList) GetTopics() []*Topic {
+	if m != nil {
+		return m.Topics
+	}
+	return nil
+}
+
+// MoveTopic binds a topic to another backend.
+type MoveTopic struct {
+	// Topic name.
+	// @inject_tag: valid:"required"
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty" valid:"required"`
+	// Backend the topic is moved to.
+	// @inject_tag: valid:"required"
+	Backend string `protobuf:"bytes,2,opt,name=backend" json:"backend,omitempty" valid:"required"`
+	// Options passed to the new backend when the topic is used.
+	Options map[string]string `protobuf:"bytes,3,rep,name=options" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Copy the messages stored by the current backend to the new one.
+	Copy bool `protobuf:"varint,4,opt,name=copy" json:"copy,omitempty"`
+}
+
+func (m *MoveTopic) Reset()                    { *m = MoveTopic{} }
+func (m *MoveTopic) String() string            { return proto1.CompactTextString(m) }
+func (*MoveTopic) ProtoMessage()               {}
+func (*MoveTopic) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{5} }
+
+func (m *MoveTopic) GetOptions() map[string]string {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
 func init() {
 	proto1.RegisterType((*NewTopic)(nil), "proto.NewTopic")
 	proto1.RegisterType((*Topic)(nil), "proto.Topic")
 	proto1.RegisterType((*TopicStatus)(nil), "proto.TopicStatus")
+	proto1.RegisterType((*ListTopics)(nil), "proto.ListTopics")
+	proto1.RegisterType((*TopicList)(nil), "proto.TopicList")
+	proto1.RegisterType((*MoveTopic)(nil), "proto.MoveTopic")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -73,6 +151,10 @@ const _ = grpc.SupportPackageIsVersion3
 type RegistryServiceClient interface {
 	Create(ctx context.Context, in *NewTopic, opts ...grpc.CallOption) (*Empty, error)
 	Status(ctx context.Context, in *Topic, opts ...grpc.CallOption) (*TopicStatus, error)
+	Delete(ctx context.Context, in *Topic, opts ...grpc.CallOption) (*Empty, error)
+	Get(ctx context.Context, in *Topic, opts ...grpc.CallOption) (*Topic, error)
+	List(ctx context.Context, in *ListTopics, opts ...grpc.CallOption) (*TopicList, error)
+	Move(ctx context.Context, in *MoveTopic, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type registryServiceClient struct {
@@ -101,11 +183,51 @@ func (c *registryServiceClient) Status(ctx context.Context, in *Topic, opts ...g
 	return out, nil
 }
 
+func (c *registryServiceClient) Delete(ctx context.Context, in *Topic, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := grpc.Invoke(ctx, "/proto.RegistryService/Delete", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registryServiceClient) Get(ctx context.Context, in *Topic, opts ...grpc.CallOption) (*Topic, error) {
+	out := new(Topic)
+	err := grpc.Invoke(ctx, "/proto.RegistryService/Get", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registryServiceClient) List(ctx context.Context, in *ListTopics, opts ...grpc.CallOption) (*TopicList, error) {
+	out := new(TopicList)
+	err := grpc.Invoke(ctx, "/proto.RegistryService/List", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registryServiceClient) Move(ctx context.Context, in *MoveTopic, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := grpc.Invoke(ctx, "/proto.RegistryService/Move", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for RegistryService service
 
 type RegistryServiceServer interface {
 	Create(context.Context, *NewTopic) (*Empty, error)
 	Status(context.Context, *Topic) (*TopicStatus, error)
+	Delete(context.Context, *Topic) (*Empty, error)
+	Get(context.Context, *Topic) (*Topic, error)
+	List(context.Context, *ListTopics) (*TopicList, error)
+	Move(context.Context, *MoveTopic) (*Empty, error)
 }
 
 func RegisterRegistryServiceServer(s *grpc.Server, srv RegistryServiceServer) {
@@ -148,6 +270,78 @@ func _RegistryService_Status_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RegistryService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Topic)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.RegistryService/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServiceServer).Delete(ctx, req.(*Topic))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegistryService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Topic)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.RegistryService/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServiceServer).Get(ctx, req.(*Topic))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegistryService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTopics)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.RegistryService/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServiceServer).List(ctx, req.(*ListTopics))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegistryService_Move_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveTopic)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServiceServer).Move(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.RegistryService/Move",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServiceServer).Move(ctx, req.(*MoveTopic))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _RegistryService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.RegistryService",
 	HandlerType: (*RegistryServiceServer)(nil),
@@ -160,6 +354,22 @@ var _RegistryService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Status",
 			Handler:    _RegistryService_Status_Handler,
 		},
+		{
+			MethodName: "Delete",
+			Handler:    _RegistryService_Delete_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _RegistryService_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _RegistryService_List_Handler,
+		},
+		{
+			MethodName: "Move",
+			Handler:    _RegistryService_Move_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: fileDescriptor1,
@@ -168,18 +378,32 @@ var _RegistryService_serviceDesc = grpc.ServiceDesc{
 func init() { proto1.RegisterFile("registry.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 193 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x2b, 0x4a, 0x4d, 0xcf,
-	0x2c, 0x2e, 0x29, 0xaa, 0xd4, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x05, 0x53, 0x52, 0xdc,
-	0x25, 0xf9, 0x05, 0x99, 0xc9, 0x10, 0x31, 0x25, 0x0b, 0x2e, 0x0e, 0xbf, 0xd4, 0xf2, 0x10, 0x90,
-	0x88, 0x90, 0x10, 0x17, 0x4b, 0x5e, 0x62, 0x6e, 0xaa, 0x04, 0xa3, 0x02, 0xa3, 0x06, 0x67, 0x10,
-	0x98, 0x2d, 0x24, 0xc1, 0xc5, 0x9e, 0x94, 0x98, 0x9c, 0x9d, 0x9a, 0x97, 0x22, 0xc1, 0x04, 0x16,
-	0x86, 0x71, 0x95, 0x4c, 0xb9, 0x58, 0xc9, 0xd1, 0xa6, 0xca, 0xc5, 0x0d, 0xd6, 0x16, 0x5c, 0x92,
-	0x58, 0x52, 0x5a, 0x2c, 0x24, 0xc6, 0xc5, 0x96, 0x5a, 0x91, 0x59, 0x5c, 0x52, 0x0c, 0xd6, 0xce,
-	0x11, 0x04, 0xe5, 0x19, 0x65, 0x71, 0xf1, 0x07, 0x41, 0x5d, 0x1f, 0x9c, 0x5a, 0x54, 0x96, 0x99,
-	0x9c, 0x2a, 0xa4, 0xc9, 0xc5, 0xe6, 0x5c, 0x94, 0x9a, 0x58, 0x92, 0x2a, 0xc4, 0x0f, 0x71, 0xbc,
-	0x1e, 0xcc, 0xe5, 0x52, 0x3c, 0x50, 0x01, 0xd7, 0xdc, 0x82, 0x92, 0x4a, 0x25, 0x06, 0x21, 0x1d,
-	0x2e, 0x36, 0xa8, 0xf9, 0x30, 0x19, 0x88, 0x3a, 0x21, 0x64, 0x1e, 0x44, 0x85, 0x12, 0x43, 0x12,
-	0x1b, 0x58, 0xd0, 0x18, 0x10, 0x00, 0x00, 0xff, 0xff, 0x19, 0x75, 0xfd, 0xd9, 0x30, 0x01, 0x00,
-	0x00,
+	// 431 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x52, 0xdb, 0x6e, 0xd3, 0x40,
+	0x10, 0x8d, 0xe3, 0x4b, 0x9b, 0x49, 0x45, 0xc3, 0x08, 0x21, 0x63, 0x51, 0x29, 0x5a, 0x2e, 0x0a,
+	0x12, 0x44, 0xa2, 0x95, 0x00, 0xe5, 0x0d, 0x41, 0xc5, 0x0b, 0x17, 0x69, 0xcb, 0x3b, 0x72, 0xdd,
+	0x29, 0x5a, 0x35, 0xc9, 0x5a, 0xf6, 0x34, 0xe0, 0xbf, 0xe0, 0x43, 0xf8, 0x0a, 0x24, 0xfe, 0x0b,
+	0xed, 0x78, 0x43, 0x83, 0x05, 0x2f, 0x41, 0x7d, 0xf2, 0x9e, 0xd9, 0xb3, 0x73, 0x7c, 0xce, 0x0c,
+	0xdc, 0xa8, 0xe8, 0xb3, 0xa9, 0xb9, 0x6a, 0xa6, 0x65, 0x65, 0xd9, 0x62, 0x2c, 0x9f, 0x6c, 0xc8,
+	0xb6, 0x34, 0x45, 0x5b, 0x53, 0xdf, 0x03, 0xd8, 0x7d, 0x4f, 0x5f, 0x3e, 0xba, 0x12, 0x22, 0x44,
+	0xcb, 0x7c, 0x41, 0x69, 0x30, 0x0e, 0x26, 0x03, 0x2d, 0x67, 0x4c, 0x61, 0xe7, 0x34, 0x2f, 0x2e,
+	0x68, 0x79, 0x96, 0xf6, 0xa5, 0xbc, 0x86, 0xf8, 0x0c, 0x76, 0x6c, 0xc9, 0xc6, 0x2e, 0xeb, 0x34,
+	0x1c, 0x87, 0x93, 0xe1, 0xe1, 0xdd, 0xb6, 0xe7, 0x74, 0xdd, 0x6f, 0xfa, 0xa1, 0xbd, 0x3e, 0x5e,
+	0x72, 0xd5, 0xe8, 0x35, 0x39, 0x9b, 0xc1, 0xde, 0xe6, 0x05, 0x8e, 0x20, 0xbc, 0xa0, 0xc6, 0x8b,
+	0xba, 0x23, 0xde, 0x82, 0x78, 0x95, 0xcf, 0x2f, 0xc9, 0x2b, 0xb6, 0x60, 0xd6, 0x7f, 0x11, 0xa8,
+	0x9f, 0x01, 0xc4, 0xdb, 0xfc, 0xeb, 0x01, 0x40, 0x51, 0x51, 0xce, 0x74, 0xf6, 0x29, 0xe7, 0x34,
+	0x1c, 0x07, 0x93, 0x50, 0x0f, 0x7c, 0xe5, 0x25, 0xe3, 0xd1, 0x95, 0x95, 0x48, 0xac, 0xdc, 0xf1,
+	0x56, 0xae, 0xcb, 0xc7, 0x03, 0x18, 0x4a, 0xeb, 0x13, 0xce, 0xf9, 0xb2, 0xc6, 0xdb, 0x90, 0xd0,
+	0x57, 0x53, 0x73, 0x2d, 0xaf, 0x77, 0xb5, 0x47, 0x6a, 0x06, 0xf0, 0xd6, 0xd4, 0x2c, 0x54, 0x61,
+	0xd9, 0xf3, 0xf3, 0x9a, 0x58, 0x58, 0xb1, 0xf6, 0xc8, 0xc9, 0xcc, 0xcd, 0xc2, 0xb0, 0xc8, 0xc4,
+	0xba, 0x05, 0xea, 0x29, 0x0c, 0xe4, 0x9d, 0x6b, 0x80, 0xf7, 0x21, 0x91, 0xa9, 0x3b, 0x01, 0xe7,
+	0x6f, 0x6f, 0xd3, 0x9f, 0xf6, 0x77, 0xea, 0x47, 0x00, 0x83, 0x77, 0x76, 0x45, 0xdb, 0x24, 0xfc,
+	0xbc, 0xbb, 0x0d, 0x07, 0x5e, 0xe2, 0x77, 0xc3, 0xbf, 0xc7, 0xe8, 0x64, 0x0a, 0x5b, 0x36, 0x69,
+	0x24, 0xce, 0xe5, 0xfc, 0x3f, 0xd1, 0x1e, 0x7e, 0xeb, 0xc3, 0xbe, 0xf6, 0x8b, 0x7f, 0x42, 0xd5,
+	0xca, 0x14, 0x84, 0x8f, 0x20, 0x79, 0x25, 0xc3, 0xc6, 0xfd, 0xce, 0x8e, 0x66, 0xeb, 0x24, 0x8e,
+	0x17, 0x25, 0x37, 0xaa, 0x87, 0x8f, 0x21, 0xf1, 0x43, 0xf9, 0x23, 0xa3, 0x0c, 0x37, 0x51, 0xcb,
+	0x50, 0x3d, 0x7c, 0x08, 0xc9, 0x6b, 0x9a, 0x13, 0x53, 0x87, 0xdd, 0xed, 0x7a, 0x0f, 0xc2, 0x37,
+	0xc4, 0xff, 0x20, 0x09, 0x52, 0x3d, 0x7c, 0x02, 0x91, 0x0c, 0xeb, 0xa6, 0xaf, 0x5f, 0x8d, 0x3e,
+	0x1b, 0x6d, 0x52, 0x5d, 0x5d, 0xf5, 0x70, 0x02, 0x91, 0xcb, 0x16, 0x47, 0xdd, 0xa0, 0xbb, 0xea,
+	0xa7, 0x89, 0xc0, 0xa3, 0x5f, 0x03, 0x00, 0xf9, 0x5a, 0x15, 0x68, 0x11, 0x04, 0x00, 0x00,
 }
@@ -13,9 +13,18 @@ It has these top-level messages:
 	Empty
 	NewMessage
 	Message
+	ReadMessages
+	Messages
+	Subscription
+	MessageID
+	NewMessages
+	BatchResults
+	BatchResult
 	NewTopic
 	Topic
 	TopicStatus
+	ListTopics
+	TopicList
 */
 package proto
 
@@ -56,6 +65,8 @@ type NewMessage struct {
 	// Message to send to the topic.
 	// @inject_tag: valid:"required"
 	Message *Message `protobuf:"bytes,2,opt,name=message" json:"message,omitempty" valid:"required"`
+	// Options the topic was created with.
+	Options map[string]string `protobuf:"bytes,3,rep,name=options" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *NewMessage) Reset()                    { *m = NewMessage{} }
@@ -70,10 +81,23 @@ func (m *NewMessage) GetMessage() *Message {
 	return nil
 }
 
+func (m *NewMessage) GetOptions() map[string]string {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
 type Message struct {
 	Group string `protobuf:"bytes,1,opt,name=group" json:"group,omitempty"`
 	// @inject_tag: valid:"required"
 	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty" valid:"required"`
+	// Unique ID of the message. Assigned by Lobby if empty.
+	Id string `protobuf:"bytes,3,opt,name=id" json:"id,omitempty"`
+	// Unix time in nanoseconds at which the message entered Lobby.
+	CreatedAt int64 `protobuf:"varint,4,opt,name=created_at,json=createdAt" json:"created_at,omitempty"`
+	// Arbitrary metadata associated with the message.
+	Headers map[string]string `protobuf:"bytes,5,rep,name=headers" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *Message) Reset()                    { *m = Message{} }
@@ -81,10 +105,157 @@ func (m *Message) String() string            { return proto1.CompactTextString(m
 func (*Message) ProtoMessage()               {}
 func (*Message) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
+func (m *Message) GetHeaders() map[string]string {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+// ReadMessages is used to read messages stored in a topic.
+type ReadMessages struct {
+	// Topic name.
+	// @inject_tag: valid:"required"
+	Topic string `protobuf:"bytes,1,opt,name=topic" json:"topic,omitempty" valid:"required"`
+	// Only read the messages of this group. Optional.
+	Group string `protobuf:"bytes,2,opt,name=group" json:"group,omitempty"`
+	// Cursor returned by a previous read. If empty, reads from the first message.
+	Cursor string `protobuf:"bytes,3,opt,name=cursor" json:"cursor,omitempty"`
+	// Maximum number of messages to return.
+	Limit int32 `protobuf:"varint,4,opt,name=limit" json:"limit,omitempty"`
+	// Options the topic was created with.
+	Options map[string]string `protobuf:"bytes,5,rep,name=options" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *ReadMessages) Reset()                    { *m = ReadMessages{} }
+func (m *ReadMessages) String() string            { return proto1.CompactTextString(m) }
+func (*ReadMessages) ProtoMessage()               {}
+func (*ReadMessages) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *ReadMessages) GetOptions() map[string]string {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
+// Messages is a page of messages read from a topic.
+type Messages struct {
+	Messages []*Message `protobuf:"bytes,1,rep,name=messages" json:"messages,omitempty"`
+	// Cursor used to read the following messages. Empty if there are no more messages to read.
+	Next string `protobuf:"bytes,2,opt,name=next" json:"next,omitempty"`
+}
+
+func (m *Messages) Reset()                    { *m = Messages{} }
+func (m *Messages) String() string            { return proto1.CompactTextString(m) }
+func (*Messages) ProtoMessage()               {}
+func (*Messages) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *Messages) GetMessages() []*Message {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
+// Subscription is used to receive the messages sent to a topic.
+type Subscription struct {
+	// Topic name.
+	// @inject_tag: valid:"required"
+	Topic string `protobuf:"bytes,1,opt,name=topic" json:"topic,omitempty" valid:"required"`
+	// Only receive the messages of this group. Optional.
+	Group string `protobuf:"bytes,2,opt,name=group" json:"group,omitempty"`
+}
+
+func (m *Subscription) Reset()                    { *m = Subscription{} }
+func (m *Subscription) String() string            { return proto1.CompactTextString(m) }
+func (*Subscription) ProtoMessage()               {}
+func (*Subscription) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+// MessageID is returned when a message is sent.
+type MessageID struct {
+	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+}
+
+func (m *MessageID) Reset()                    { *m = MessageID{} }
+func (m *MessageID) String() string            { return proto1.CompactTextString(m) }
+func (*MessageID) ProtoMessage()               {}
+func (*MessageID) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+// NewMessages is used to put several items in a topic.
+type NewMessages struct {
+	// Topic name.
+	// @inject_tag: valid:"required"
+	Topic string `protobuf:"bytes,1,opt,name=topic" json:"topic,omitempty" valid:"required"`
+	// Messages to send to the topic. Invalid messages are reported in the results
+	// without preventing the others from being sent.
+	Messages []*Message `protobuf:"bytes,2,rep,name=messages" json:"messages,omitempty"`
+	// Options the topic was created with.
+	Options map[string]string `protobuf:"bytes,3,rep,name=options" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *NewMessages) Reset()                    { *m = NewMessages{} }
+func (m *NewMessages) String() string            { return proto1.CompactTextString(m) }
+func (*NewMessages) ProtoMessage()               {}
+func (*NewMessages) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *NewMessages) GetMessages() []*Message {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
+func (m *NewMessages) GetOptions() map[string]string {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
+// BatchResults reports the result of each message of a batch, in order.
+type BatchResults struct {
+	Results []*BatchResult `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
+}
+
+func (m *BatchResults) Reset()                    { *m = BatchResults{} }
+func (m *BatchResults) String() string            { return proto1.CompactTextString(m) }
+func (*BatchResults) ProtoMessage()               {}
+func (*BatchResults) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *BatchResults) GetResults() []*BatchResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+// BatchResult is the result of sending one message of a batch.
+type BatchResult struct {
+	// ID of the message.
+	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	// Reason why the message couldn't be sent. Empty if the message was sent.
+	Error string `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+	// gRPC status code of the error, the same as if the message was sent alone.
+	Code uint32 `protobuf:"varint,3,opt,name=code" json:"code,omitempty"`
+}
+
+func (m *BatchResult) Reset()                    { *m = BatchResult{} }
+func (m *BatchResult) String() string            { return proto1.CompactTextString(m) }
+func (*BatchResult) ProtoMessage()               {}
+func (*BatchResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
 func init() {
 	proto1.RegisterType((*Empty)(nil), "proto.Empty")
 	proto1.RegisterType((*NewMessage)(nil), "proto.NewMessage")
 	proto1.RegisterType((*Message)(nil), "proto.Message")
+	proto1.RegisterType((*ReadMessages)(nil), "proto.ReadMessages")
+	proto1.RegisterType((*Messages)(nil), "proto.Messages")
+	proto1.RegisterType((*Subscription)(nil), "proto.Subscription")
+	proto1.RegisterType((*MessageID)(nil), "proto.MessageID")
+	proto1.RegisterType((*NewMessages)(nil), "proto.NewMessages")
+	proto1.RegisterType((*BatchResults)(nil), "proto.BatchResults")
+	proto1.RegisterType((*BatchResult)(nil), "proto.BatchResult")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -99,7 +270,13 @@ const _ = grpc.SupportPackageIsVersion3
 
 type TopicServiceClient interface {
 	// Send message to the topic.
-	Send(ctx context.Context, in *NewMessage, opts ...grpc.CallOption) (*Empty, error)
+	Send(ctx context.Context, in *NewMessage, opts ...grpc.CallOption) (*MessageID, error)
+	// Send several messages to the topic.
+	SendBatch(ctx context.Context, in *NewMessages, opts ...grpc.CallOption) (*BatchResults, error)
+	// Read messages stored in the topic.
+	Read(ctx context.Context, in *ReadMessages, opts ...grpc.CallOption) (*Messages, error)
+	// Subscribe to the messages sent to the topic.
+	Subscribe(ctx context.Context, in *Subscription, opts ...grpc.CallOption) (TopicService_SubscribeClient, error)
 }
 
 type topicServiceClient struct {
@@ -110,8 +287,8 @@ func NewTopicServiceClient(cc *grpc.ClientConn) TopicServiceClient {
 	return &topicServiceClient{cc}
 }
 
-func (c *topicServiceClient) Send(ctx context.Context, in *NewMessage, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
+func (c *topicServiceClient) Send(ctx context.Context, in *NewMessage, opts ...grpc.CallOption) (*MessageID, error) {
+	out := new(MessageID)
 	err := grpc.Invoke(ctx, "/proto.TopicService/Send", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
@@ -119,11 +296,67 @@ func (c *topicServiceClient) Send(ctx context.Context, in *NewMessage, opts ...g
 	return out, nil
 }
 
+func (c *topicServiceClient) SendBatch(ctx context.Context, in *NewMessages, opts ...grpc.CallOption) (*BatchResults, error) {
+	out := new(BatchResults)
+	err := grpc.Invoke(ctx, "/proto.TopicService/SendBatch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *topicServiceClient) Read(ctx context.Context, in *ReadMessages, opts ...grpc.CallOption) (*Messages, error) {
+	out := new(Messages)
+	err := grpc.Invoke(ctx, "/proto.TopicService/Read", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *topicServiceClient) Subscribe(ctx context.Context, in *Subscription, opts ...grpc.CallOption) (TopicService_SubscribeClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_TopicService_serviceDesc.Streams[0], c.cc, "/proto.TopicService/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &topicServiceSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TopicService_SubscribeClient interface {
+	Recv() (*Message, error)
+	grpc.ClientStream
+}
+
+type topicServiceSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *topicServiceSubscribeClient) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for TopicService service
 
 type TopicServiceServer interface {
 	// Send message to the topic.
-	Send(context.Context, *NewMessage) (*Empty, error)
+	Send(context.Context, *NewMessage) (*MessageID, error)
+	// Send several messages to the topic.
+	SendBatch(context.Context, *NewMessages) (*BatchResults, error)
+	// Read messages stored in the topic.
+	Read(context.Context, *ReadMessages) (*Messages, error)
+	// Subscribe to the messages sent to the topic.
+	Subscribe(*Subscription, TopicService_SubscribeServer) error
 }
 
 func RegisterTopicServiceServer(s *grpc.Server, srv TopicServiceServer) {
@@ -148,6 +381,63 @@ func _TopicService_Send_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TopicService_SendBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewMessages)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TopicServiceServer).SendBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.TopicService/SendBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TopicServiceServer).SendBatch(ctx, req.(*NewMessages))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TopicService_Read_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadMessages)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TopicServiceServer).Read(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.TopicService/Read",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TopicServiceServer).Read(ctx, req.(*ReadMessages))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TopicService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Subscription)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TopicServiceServer).Subscribe(m, &topicServiceSubscribeServer{stream})
+}
+
+type TopicService_SubscribeServer interface {
+	Send(*Message) error
+	grpc.ServerStream
+}
+
+type topicServiceSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *topicServiceSubscribeServer) Send(m *Message) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _TopicService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.TopicService",
 	HandlerType: (*TopicServiceServer)(nil),
@@ -156,24 +446,61 @@ var _TopicService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Send",
 			Handler:    _TopicService_Send_Handler,
 		},
+		{
+			MethodName: "SendBatch",
+			Handler:    _TopicService_SendBatch_Handler,
+		},
+		{
+			MethodName: "Read",
+			Handler:    _TopicService_Read_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _TopicService_Subscribe_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: fileDescriptor0,
 }
 
 func init() { proto1.RegisterFile("topic.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 169 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x2e, 0xc9, 0x2f, 0xc8,
-	0x4c, 0xd6, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x05, 0x53, 0x4a, 0xec, 0x5c, 0xac, 0xae,
-	0xb9, 0x05, 0x25, 0x95, 0x4a, 0x3e, 0x5c, 0x5c, 0x7e, 0xa9, 0xe5, 0xbe, 0xa9, 0xc5, 0xc5, 0x89,
-	0xe9, 0xa9, 0x42, 0x22, 0x5c, 0xac, 0x60, 0xc5, 0x12, 0x8c, 0x0a, 0x8c, 0x1a, 0x9c, 0x41, 0x10,
-	0x8e, 0x90, 0x06, 0x17, 0x7b, 0x2e, 0x44, 0x81, 0x04, 0x93, 0x02, 0xa3, 0x06, 0xb7, 0x11, 0x1f,
-	0xc4, 0x30, 0x3d, 0xa8, 0xb6, 0x20, 0x98, 0xb4, 0x92, 0x29, 0x17, 0x3b, 0x92, 0x51, 0xe9, 0x45,
-	0xf9, 0xa5, 0x05, 0x30, 0xa3, 0xc0, 0x1c, 0x90, 0x68, 0x59, 0x62, 0x4e, 0x29, 0xc4, 0x20, 0x9e,
-	0x20, 0x08, 0xc7, 0xc8, 0x92, 0x8b, 0x27, 0x04, 0x64, 0x53, 0x70, 0x6a, 0x51, 0x59, 0x66, 0x72,
-	0xaa, 0x90, 0x26, 0x17, 0x4b, 0x70, 0x6a, 0x5e, 0x8a, 0x90, 0x20, 0xd4, 0x1e, 0x84, 0x0b, 0xa5,
-	0x78, 0xa0, 0x42, 0x10, 0xd7, 0x33, 0x24, 0xb1, 0x81, 0xb9, 0xc6, 0x80, 0x00, 0x00, 0x00, 0xff,
-	0xff, 0x93, 0x32, 0x15, 0x65, 0xe5, 0x00, 0x00, 0x00,
+	// 534 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x53, 0x4d, 0x6f, 0xd3, 0x40,
+	0x10, 0xcd, 0xda, 0x49, 0x5d, 0x4f, 0x4c, 0x29, 0x53, 0x84, 0xac, 0x54, 0x80, 0xb5, 0xa7, 0x08,
+	0x41, 0x84, 0xc2, 0x87, 0x4a, 0xc4, 0x05, 0xd4, 0x0a, 0x8a, 0x04, 0x48, 0x1b, 0xee, 0xc8, 0xb1,
+	0x57, 0xad, 0x45, 0x12, 0x47, 0xbb, 0x9b, 0x42, 0xfe, 0x1e, 0x07, 0xfe, 0x02, 0x07, 0x2e, 0xfc,
+	0x14, 0xb4, 0x1f, 0x4e, 0x9c, 0xa4, 0x15, 0xea, 0xa1, 0x27, 0xef, 0xec, 0xbc, 0xf1, 0xcc, 0x7b,
+	0xf3, 0x16, 0xda, 0xaa, 0x9c, 0x15, 0x59, 0x6f, 0x26, 0x4a, 0x55, 0x62, 0xcb, 0x7c, 0x68, 0x00,
+	0xad, 0x93, 0xc9, 0x4c, 0x2d, 0xe8, 0x4f, 0x02, 0xf0, 0x89, 0x7f, 0xff, 0xc8, 0xa5, 0x4c, 0xcf,
+	0x38, 0xde, 0x85, 0x96, 0x41, 0xc7, 0x24, 0x21, 0xdd, 0x90, 0xd9, 0x00, 0xbb, 0x10, 0x4c, 0x2c,
+	0x20, 0xf6, 0x12, 0xd2, 0x6d, 0xf7, 0xf7, 0xec, 0xdf, 0x7a, 0xae, 0x8c, 0x55, 0x69, 0x3c, 0x82,
+	0xa0, 0x9c, 0xa9, 0xa2, 0x9c, 0xca, 0xd8, 0x4f, 0xfc, 0x6e, 0xbb, 0xff, 0xc0, 0x21, 0x57, 0x3d,
+	0x7a, 0x9f, 0x2d, 0xe0, 0x64, 0xaa, 0xc4, 0x82, 0x55, 0xf0, 0xce, 0x00, 0xa2, 0x7a, 0x02, 0xf7,
+	0xc1, 0xff, 0xc6, 0x17, 0x6e, 0x0e, 0x7d, 0xd4, 0xb3, 0x5d, 0xa4, 0xe3, 0xb9, 0x9d, 0x21, 0x64,
+	0x36, 0x18, 0x78, 0x47, 0x84, 0xfe, 0x26, 0x10, 0xd4, 0x18, 0x9c, 0x89, 0x72, 0x3e, 0xab, 0x18,
+	0x98, 0x60, 0xbd, 0x36, 0x72, 0xb5, 0xb8, 0x07, 0x5e, 0x91, 0xc7, 0xbe, 0x01, 0x7a, 0x45, 0x8e,
+	0xf7, 0x01, 0x32, 0xc1, 0x53, 0xc5, 0xf3, 0xaf, 0xa9, 0x8a, 0x9b, 0x09, 0xe9, 0xfa, 0x2c, 0x74,
+	0x37, 0x6f, 0x14, 0xbe, 0x80, 0xe0, 0x9c, 0xa7, 0x39, 0x17, 0x32, 0x6e, 0x19, 0x72, 0x87, 0xeb,
+	0x32, 0xf4, 0xde, 0xdb, 0xac, 0x63, 0xe6, 0xb0, 0x9a, 0x59, 0x3d, 0x71, 0x2d, 0x66, 0x7f, 0x09,
+	0x44, 0x8c, 0xa7, 0xb9, 0xeb, 0x20, 0xaf, 0x58, 0xd0, 0x92, 0xb4, 0x57, 0x27, 0x7d, 0x0f, 0x76,
+	0xb2, 0xb9, 0x90, 0xa5, 0x70, 0x14, 0x5d, 0xa4, 0xd1, 0xe3, 0x62, 0x52, 0x58, 0x86, 0x2d, 0x66,
+	0x03, 0x1c, 0xac, 0x56, 0x67, 0xd9, 0x25, 0x8e, 0x5d, 0xbd, 0xff, 0x0d, 0x2c, 0xef, 0x03, 0xec,
+	0x2e, 0xd9, 0x3d, 0x82, 0x5d, 0xe7, 0x24, 0x19, 0x93, 0xc4, 0xbf, 0xc4, 0x69, 0xcb, 0x3c, 0x22,
+	0x34, 0xa7, 0xfc, 0x87, 0x72, 0x3f, 0x34, 0x67, 0x3a, 0x80, 0x68, 0x38, 0x1f, 0xc9, 0x4c, 0x14,
+	0x66, 0x9a, 0xeb, 0xa8, 0x45, 0x0f, 0x21, 0x74, 0x4d, 0x4e, 0x8f, 0x9d, 0x33, 0x48, 0xe5, 0x0c,
+	0xfa, 0x8b, 0x40, 0x7b, 0x65, 0xe1, 0xab, 0xd6, 0x50, 0x1f, 0xdf, 0xfb, 0xcf, 0xf8, 0xaf, 0x36,
+	0x5f, 0xca, 0xc3, 0xad, 0x97, 0x72, 0x13, 0x6a, 0xbf, 0x86, 0xe8, 0x6d, 0xaa, 0xb2, 0x73, 0xc6,
+	0xe5, 0x7c, 0xac, 0x24, 0x3e, 0x86, 0x40, 0xd8, 0xa3, 0x13, 0x1c, 0xdd, 0x18, 0x35, 0x14, 0xab,
+	0x20, 0xf4, 0x1d, 0xb4, 0x6b, 0xf7, 0x9b, 0x2a, 0xe9, 0xb6, 0x5c, 0x88, 0x52, 0x54, 0x6d, 0x4d,
+	0xa0, 0x17, 0x95, 0x95, 0x39, 0x37, 0x26, 0xbc, 0xc5, 0xcc, 0xb9, 0xff, 0x87, 0x40, 0xf4, 0x45,
+	0x6b, 0x36, 0xe4, 0xe2, 0xa2, 0xc8, 0x38, 0x3e, 0x81, 0xe6, 0x90, 0x4f, 0x73, 0xbc, 0xb3, 0xa5,
+	0x42, 0x67, 0x7f, 0x5d, 0xc3, 0xd3, 0x63, 0xda, 0xc0, 0x97, 0x10, 0x6a, 0xb8, 0x19, 0x06, 0x71,
+	0x5b, 0xb9, 0xce, 0xc1, 0x36, 0x0d, 0x49, 0x1b, 0xd8, 0x83, 0xa6, 0xb6, 0x33, 0x1e, 0x5c, 0xe2,
+	0xed, 0xce, 0xed, 0xf5, 0x46, 0x1a, 0xff, 0x1c, 0x42, 0x67, 0xa8, 0x11, 0x5f, 0x16, 0xd5, 0x2d,
+	0xd6, 0xd9, 0xd8, 0x30, 0x6d, 0x3c, 0x25, 0xa3, 0x1d, 0x73, 0xf5, 0xec, 0xdf, 0x00, 0x98, 0xb8,
+	0x29, 0x46, 0x7a, 0x05, 0x00, 0x00,
 }
@@ -0,0 +1,132 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/auth"
+	"github.com/asdine/lobby/ratelimit"
+	"github.com/asdine/lobby/rpc/proto"
+	"github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// retryAfterKey is the header metadata holding the number of seconds after which a rate limited
+// request can be retried.
+const retryAfterKey = "retry-after"
+
+// identify returns a context carrying the identity of the client if it sent valid credentials.
+// The identity is used to enforce the rate limits of the client and reused to authorize the request.
+func identify(ctx context.Context, a auth.Authenticator) context.Context {
+	if a == nil {
+		return ctx
+	}
+
+	actx := ctx
+	if cert := peerCertificate(ctx); cert != nil {
+		actx = auth.NewCertificateContext(ctx, cert)
+	}
+
+	id, err := auth.Authenticate(actx, a, token(ctx))
+	if err != nil {
+		// the service reports the error if the method requires authentication.
+		return ctx
+	}
+
+	return auth.NewIdentityContext(ctx, id)
+}
+
+// clientKey identifies the client of a request for rate limits, by its verified identity or by its address.
+func clientKey(ctx context.Context) string {
+	var identity string
+	if id, ok := auth.IdentityFromContext(ctx); ok {
+		identity = id.Name
+	}
+
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr = p.Addr.String()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			addr = host
+		}
+	}
+
+	return ratelimit.ClientKey(identity, addr)
+}
+
+// allow checks the rate limits of the client and of the topic, if not empty, for a request sending n messages.
+// If a limit is exceeded, it returns the header to send to the client and the error.
+func allow(ctx context.Context, l *ratelimit.Limiter, topic string, n int) (metadata.MD, error) {
+	ok, wait := l.AllowN(clientKey(ctx), topic, n)
+	if ok {
+		return nil, nil
+	}
+
+	return metadata.Pairs(retryAfterKey, strconv.Itoa(ratelimit.RetryAfter(wait))),
+		status.Error(codes.ResourceExhausted, lobby.ErrRateLimited.Error())
+}
+
+// limited returns true if the method is subject to rate limits. Health checks never are.
+func limited(l *ratelimit.Limiter, method string) bool {
+	return l != nil && !strings.HasPrefix(method, "/grpc.health.")
+}
+
+// rateLimitUnaryInterceptor rejects the requests exceeding the limits of their client
+// and, for requests sending messages, of their topic. Clients are identified using a, if not nil.
+func rateLimitUnaryInterceptor(l *ratelimit.Limiter, a auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !limited(l, info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx = identify(ctx, a)
+
+		var topic string
+		n := 1
+		switch r := req.(type) {
+		case *proto.NewMessage:
+			topic = r.Topic
+		case *proto.NewMessages:
+			// every message of the batch counts towards the limit of the topic.
+			topic, n = r.Topic, len(r.Messages)
+		}
+
+		md, err := allow(ctx, l, topic, n)
+		if err != nil {
+			_ = grpc.SetHeader(ctx, md)
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// rateLimitStreamInterceptor rejects the streams exceeding the limits of their client.
+// Clients are identified using a, if not nil.
+func rateLimitStreamInterceptor(l *ratelimit.Limiter, a auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !limited(l, info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		ctx := identify(ss.Context(), a)
+
+		md, err := allow(ctx, l, "", 1)
+		if err != nil {
+			_ = ss.SetHeader(md)
+			return err
+		}
+
+		return handler(srv, &grpc_middleware.WrappedServerStream{
+			ServerStream:   ss,
+			WrappedContext: ctx,
+		})
+	}
+}
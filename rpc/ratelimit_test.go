@@ -0,0 +1,93 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/mock"
+	"github.com/asdine/lobby/ratelimit"
+	"github.com/asdine/lobby/rpc"
+	"github.com/asdine/lobby/rpc/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRateLimit(t *testing.T) {
+	var r mock.Registry
+
+	r.TopicFn = func(name string) (lobby.Topic, error) {
+		return new(mock.Topic), nil
+	}
+
+	r.InfoFn = func(name string) (*lobby.TopicInfo, error) {
+		return &lobby.TopicInfo{Name: name, Backend: "bolt"}, nil
+	}
+
+	limiter := ratelimit.New(ratelimit.Limit{Rate: 0.001, Burst: 2}, ratelimit.Limit{Rate: 0.001, Burst: 1}, nil)
+	conn, cleanup := newServerWithConfig(t, &r, nil, rpc.ServerConfig{Limiter: limiter})
+	defer cleanup()
+
+	topics := proto.NewTopicServiceClient(conn)
+	registry := proto.NewRegistryServiceClient(conn)
+	message := proto.NewMessage{
+		Topic:   "quotes",
+		Message: &proto.Message{Value: []byte("value")},
+	}
+
+	_, err := topics.Send(context.Background(), &message)
+	require.NoError(t, err)
+
+	// the limit of the topic is exceeded.
+	var header metadata.MD
+	_, err = topics.Send(context.Background(), &message, grpc.Header(&header))
+	require.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+	require.Equal(t, []string{"1000"}, header["retry-after"])
+
+	// requests that don't send messages are only limited by the limit of the client.
+	_, err = registry.Get(context.Background(), &proto.Topic{Name: "quotes"})
+	require.NoError(t, err)
+
+	// the limit of the client is exceeded.
+	_, err = registry.Get(context.Background(), &proto.Topic{Name: "quotes"})
+	require.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+
+	client, err := rpc.NewRegistry(conn)
+	require.NoError(t, err)
+
+	_, err = client.Info("quotes")
+	require.Equal(t, lobby.ErrRateLimited, err)
+}
+
+func TestRateLimitBatch(t *testing.T) {
+	var r mock.Registry
+
+	r.TopicFn = func(name string) (lobby.Topic, error) {
+		return new(mock.Topic), nil
+	}
+
+	limiter := ratelimit.New(ratelimit.Limit{}, ratelimit.Limit{Rate: 0.001, Burst: 3}, nil)
+	conn, cleanup := newServerWithConfig(t, &r, nil, rpc.ServerConfig{Limiter: limiter})
+	defer cleanup()
+
+	topics := proto.NewTopicServiceClient(conn)
+	batch := func(n int) *proto.NewMessages {
+		b := proto.NewMessages{Topic: "quotes"}
+		for i := 0; i < n; i++ {
+			b.Messages = append(b.Messages, &proto.Message{Value: []byte("value")})
+		}
+		return &b
+	}
+
+	// each message of a batch counts towards the limit of the topic.
+	_, err := topics.SendBatch(context.Background(), batch(2))
+	require.NoError(t, err)
+
+	_, err = topics.SendBatch(context.Background(), batch(2))
+	require.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+
+	_, err = topics.SendBatch(context.Background(), batch(1))
+	require.NoError(t, err)
+}
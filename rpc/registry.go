@@ -2,36 +2,47 @@ package rpc
 
 import (
 	"context"
+	"time"
 
 	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/auth"
 	"github.com/asdine/lobby/log"
 	"github.com/asdine/lobby/rpc/proto"
 	"github.com/asdine/lobby/validation"
 	"google.golang.org/grpc"
 )
 
-func newRegistryService(r lobby.Registry, logger *log.Logger) *registryService {
+func newRegistryService(r lobby.Registry, a auth.Authenticator, logger *log.Logger) *registryService {
 	return &registryService{
 		registry: r,
+		auth:     a,
 		logger:   logger,
 	}
 }
 
 type registryService struct {
 	registry lobby.Registry
+	auth     auth.Authenticator
 	logger   *log.Logger
 }
 
 // Create a topic in the registry.
 func (s *registryService) Create(ctx context.Context, newTopic *proto.NewTopic) (*proto.Empty, error) {
+	log.AddFields(ctx, log.Fields{"topic": newTopic.Name, "backend": newTopic.Backend})
+
 	err := validation.Validate(newTopic)
 	if err != nil {
-		return nil, newError(err, s.logger)
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	err = authorize(ctx, s.auth, newTopic.Name, auth.Create)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
 	}
 
-	err = s.registry.Create(newTopic.Backend, newTopic.Name)
+	err = s.registry.Create(newTopic.Backend, newTopic.Name, newTopic.Options)
 	if err != nil {
-		return nil, newError(err, s.logger)
+		return nil, newError(err, s.logger.WithContext(ctx))
 	}
 
 	return new(proto.Empty), nil
@@ -39,17 +50,24 @@ func (s *registryService) Create(ctx context.Context, newTopic *proto.NewTopic)
 
 // Exists check a topic in the registry.
 func (s *registryService) Status(ctx context.Context, topic *proto.Topic) (*proto.TopicStatus, error) {
+	log.AddFields(ctx, log.Fields{"topic": topic.Name})
+
 	err := validation.Validate(topic)
 	if err != nil {
-		return nil, newError(err, s.logger)
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	err = authorize(ctx, s.auth, topic.Name, auth.Read)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
 	}
 
 	var exists bool
 
-	_, err = s.registry.Topic(topic.Name)
+	_, err = s.registry.Info(topic.Name)
 	if err != nil {
 		if err != lobby.ErrTopicNotFound {
-			return nil, newError(err, s.logger)
+			return nil, newError(err, s.logger.WithContext(ctx))
 		}
 	} else {
 		exists = true
@@ -60,13 +78,166 @@ func (s *registryService) Status(ctx context.Context, topic *proto.Topic) (*prot
 	}, nil
 }
 
+// Delete a topic from the registry.
+func (s *registryService) Delete(ctx context.Context, topic *proto.Topic) (*proto.Empty, error) {
+	log.AddFields(ctx, log.Fields{"topic": topic.Name})
+
+	err := validation.Validate(topic)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	err = authorize(ctx, s.auth, topic.Name, auth.Create)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	err = s.registry.Delete(topic.Name)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	return new(proto.Empty), nil
+}
+
+// Move binds a topic to another backend. If requested, the messages of the topic are copied
+// to the new backend first, which requires the registry to be a lobby.Migrator.
+func (s *registryService) Move(ctx context.Context, req *proto.MoveTopic) (*proto.Empty, error) {
+	log.AddFields(ctx, log.Fields{"topic": req.Name, "backend": req.Backend})
+
+	err := validation.Validate(req)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	err = authorize(ctx, s.auth, req.Name, auth.Create)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	if req.Copy {
+		m, ok := s.registry.(lobby.Migrator)
+		if !ok {
+			return nil, newError(lobby.ErrNotSupported, s.logger.WithContext(ctx))
+		}
+
+		err = m.Migrate(ctx, req.Name, req.Backend, req.Options)
+	} else {
+		err = s.registry.Rebind(req.Name, req.Backend, req.Options)
+	}
+
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	return new(proto.Empty), nil
+}
+
+// Get returns informations about a topic.
+func (s *registryService) Get(ctx context.Context, topic *proto.Topic) (*proto.Topic, error) {
+	log.AddFields(ctx, log.Fields{"topic": topic.Name})
+
+	err := validation.Validate(topic)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	err = authorize(ctx, s.auth, topic.Name, auth.Read)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	info, err := s.registry.Info(topic.Name)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	return newTopic(info), nil
+}
+
+// List topics ordered by name.
+func (s *registryService) List(ctx context.Context, page *proto.ListTopics) (*proto.TopicList, error) {
+	var err error
+
+	if page.Offset < 0 {
+		err = validation.AddError(err, "offset", errInvalidOffset)
+	}
+
+	if page.Limit < 0 || page.Limit > maxPageLimit {
+		err = validation.AddError(err, "limit", errInvalidLimit)
+	}
+
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	limit := int(page.Limit)
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+
+	var id *auth.Identity
+	if s.auth != nil {
+		id, err = auth.Authenticate(ctx, s.auth, token(ctx))
+		if err != nil {
+			return nil, newError(err, s.logger.WithContext(ctx))
+		}
+	}
+
+	// topics the client can't read are omitted, offset and limit apply to the others.
+	list, err := auth.List(s.registry, id, int(page.Offset), limit)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	topics := proto.TopicList{
+		Topics: make([]*proto.Topic, 0, len(list)),
+	}
+
+	for i := range list {
+		topics.Topics = append(topics.Topics, newTopic(&list[i]))
+	}
+
+	return &topics, nil
+}
+
+func newTopic(info *lobby.TopicInfo) *proto.Topic {
+	t := proto.Topic{
+		Name:    info.Name,
+		Backend: info.Backend,
+		Options: info.Options,
+	}
+
+	if !info.CreatedAt.IsZero() {
+		t.CreatedAt = info.CreatedAt.UnixNano()
+	}
+
+	return &t
+}
+
+func topicInfo(t *proto.Topic) lobby.TopicInfo {
+	info := lobby.TopicInfo{
+		Name:    t.Name,
+		Backend: t.Backend,
+		Options: t.Options,
+	}
+
+	if t.CreatedAt != 0 {
+		info.CreatedAt = time.Unix(0, t.CreatedAt)
+	}
+
+	return info
+}
+
 var _ lobby.Registry = new(Registry)
+var _ lobby.Migrator = new(Registry)
 
 // NewRegistry returns a gRPC Registry. It is used to communicate with external Registries.
+// Operations on its topics are only bound to the context they receive.
 func NewRegistry(conn *grpc.ClientConn) (*Registry, error) {
 	client := proto.NewRegistryServiceClient(conn)
 
-	backend, err := NewBackend(conn)
+	backend, err := NewBackend(conn, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -92,11 +263,84 @@ func (s *Registry) RegisterBackend(_ string, _ lobby.Backend) {
 }
 
 // Create a topic and register it to the Registry.
-func (s *Registry) Create(backendName, topicName string) error {
-	_, err := s.client.Create(context.Background(), &proto.NewTopic{Name: topicName, Backend: backendName})
+func (s *Registry) Create(backendName, topicName string, options map[string]string) error {
+	_, err := s.client.Create(context.Background(), &proto.NewTopic{
+		Name:    topicName,
+		Backend: backendName,
+		Options: options,
+	})
+	return errFromGRPC(err)
+}
+
+// Delete a topic from the Registry.
+func (s *Registry) Delete(topicName string) error {
+	_, err := s.client.Delete(context.Background(), &proto.Topic{Name: topicName})
+	return errFromGRPC(err)
+}
+
+// Rebind a topic to another backend and replace its options.
+func (s *Registry) Rebind(topicName, backendName string, options map[string]string) error {
+	_, err := s.client.Move(context.Background(), &proto.MoveTopic{
+		Name:    topicName,
+		Backend: backendName,
+		Options: options,
+	})
 	return errFromGRPC(err)
 }
 
+// Migrate a topic to another backend along with its messages.
+func (s *Registry) Migrate(ctx context.Context, topicName, backendName string, options map[string]string) error {
+	_, err := s.client.Move(ctx, &proto.MoveTopic{
+		Name:    topicName,
+		Backend: backendName,
+		Options: options,
+		Copy:    true,
+	})
+	return errFromGRPC(err)
+}
+
+// Info returns informations about the selected topic.
+func (s *Registry) Info(topicName string) (*lobby.TopicInfo, error) {
+	topic, err := s.client.Get(context.Background(), &proto.Topic{Name: topicName})
+	if err != nil {
+		return nil, errFromGRPC(err)
+	}
+
+	info := topicInfo(topic)
+	return &info, nil
+}
+
+// List topics ordered by name. Topics are fetched by pages
+// until the limit is reached or there are no more topics to return.
+func (s *Registry) List(offset, limit int) ([]lobby.TopicInfo, error) {
+	list := []lobby.TopicInfo{}
+
+	for limit <= 0 || len(list) < limit {
+		size := maxPageLimit
+		if limit > 0 && limit-len(list) < size {
+			size = limit - len(list)
+		}
+
+		topics, err := s.client.List(context.Background(), &proto.ListTopics{
+			Offset: int32(offset + len(list)),
+			Limit:  int32(size),
+		})
+		if err != nil {
+			return nil, errFromGRPC(err)
+		}
+
+		for _, t := range topics.Topics {
+			list = append(list, topicInfo(t))
+		}
+
+		if len(topics.Topics) < size {
+			break
+		}
+	}
+
+	return list, nil
+}
+
 // Topic returns the topic associated with the given id.
 func (s *Registry) Topic(name string) (lobby.Topic, error) {
 	status, err := s.client.Status(context.Background(), &proto.Topic{Name: name})
@@ -108,7 +352,8 @@ func (s *Registry) Topic(name string) (lobby.Topic, error) {
 		return nil, lobby.ErrTopicNotFound
 	}
 
-	return s.Backend.Topic(name)
+	// the server opens the topic with its own options.
+	return s.Backend.Topic(name, nil)
 }
 
 // Close the connexion to the Registry.
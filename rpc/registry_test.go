@@ -3,6 +3,7 @@ package rpc_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net"
 	"os"
@@ -27,7 +28,7 @@ func TestRegistryServerCreate(t *testing.T) {
 	t.Run("OK", func(t *testing.T) {
 		var r mock.Registry
 
-		r.CreateFn = func(backendName, topicName string) error {
+		r.CreateFn = func(backendName, topicName string, options map[string]string) error {
 			assert.Equal(t, "backend", backendName)
 			assert.Equal(t, "topic", topicName)
 
@@ -57,7 +58,7 @@ func TestRegistryServerCreate(t *testing.T) {
 	t.Run("TopicAlreadyExists", func(t *testing.T) {
 		var r mock.Registry
 
-		r.CreateFn = func(backendName, topicName string) error {
+		r.CreateFn = func(backendName, topicName string, options map[string]string) error {
 			assert.Equal(t, "backend", backendName)
 			assert.Equal(t, "topic", topicName)
 
@@ -77,7 +78,7 @@ func TestRegistryServerCreate(t *testing.T) {
 	t.Run("BackendNotFound", func(t *testing.T) {
 		var r mock.Registry
 
-		r.CreateFn = func(backendName, topicName string) error {
+		r.CreateFn = func(backendName, topicName string, options map[string]string) error {
 			assert.Equal(t, "backend", backendName)
 			assert.Equal(t, "topic", topicName)
 
@@ -97,7 +98,7 @@ func TestRegistryServerCreate(t *testing.T) {
 	t.Run("InternalError", func(t *testing.T) {
 		var r mock.Registry
 
-		r.CreateFn = func(backendName, topicName string) error {
+		r.CreateFn = func(backendName, topicName string, options map[string]string) error {
 			assert.Equal(t, "backend", backendName)
 			assert.Equal(t, "topic", topicName)
 
@@ -119,10 +120,10 @@ func TestRegistryServerStatus(t *testing.T) {
 	t.Run("OK", func(t *testing.T) {
 		var r mock.Registry
 
-		r.TopicFn = func(name string) (lobby.Topic, error) {
+		r.InfoFn = func(name string) (*lobby.TopicInfo, error) {
 			assert.Equal(t, "topic", name)
 
-			return new(mock.Topic), nil
+			return &lobby.TopicInfo{Name: name}, nil
 		}
 
 		conn, cleanup := newServer(t, &r)
@@ -133,6 +134,7 @@ func TestRegistryServerStatus(t *testing.T) {
 		status, err := client.Status(context.Background(), &proto.Topic{Name: "topic"})
 		require.NoError(t, err)
 		require.True(t, status.Exists)
+		require.Zero(t, r.TopicInvoked)
 	})
 
 	t.Run("EmptyFields", func(t *testing.T) {
@@ -149,7 +151,7 @@ func TestRegistryServerStatus(t *testing.T) {
 	t.Run("NotFound", func(t *testing.T) {
 		var r mock.Registry
 
-		r.TopicFn = func(name string) (lobby.Topic, error) {
+		r.InfoFn = func(name string) (*lobby.TopicInfo, error) {
 			assert.Equal(t, "topic", name)
 
 			return nil, lobby.ErrTopicNotFound
@@ -168,7 +170,7 @@ func TestRegistryServerStatus(t *testing.T) {
 	t.Run("InternalError", func(t *testing.T) {
 		var r mock.Registry
 
-		r.TopicFn = func(name string) (lobby.Topic, error) {
+		r.InfoFn = func(name string) (*lobby.TopicInfo, error) {
 			assert.Equal(t, "topic", name)
 
 			return nil, errors.New("something unexpected happened !")
@@ -185,6 +187,228 @@ func TestRegistryServerStatus(t *testing.T) {
 	})
 }
 
+func TestRegistryServerDelete(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		var r mock.Registry
+
+		r.DeleteFn = func(topicName string) error {
+			assert.Equal(t, "topic", topicName)
+			return nil
+		}
+
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+
+		client := proto.NewRegistryServiceClient(conn)
+
+		_, err := client.Delete(context.Background(), &proto.Topic{Name: "topic"})
+		require.NoError(t, err)
+		require.Equal(t, 1, r.DeleteInvoked)
+	})
+
+	t.Run("EmptyFields", func(t *testing.T) {
+		var r mock.Registry
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+		client := proto.NewRegistryServiceClient(conn)
+
+		_, err := client.Delete(context.Background(), new(proto.Topic))
+		require.Error(t, err)
+		require.Equal(t, codes.InvalidArgument, grpc.Code(err))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		var r mock.Registry
+
+		r.DeleteFn = func(topicName string) error {
+			assert.Equal(t, "topic", topicName)
+			return lobby.ErrTopicNotFound
+		}
+
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+
+		client := proto.NewRegistryServiceClient(conn)
+
+		_, err := client.Delete(context.Background(), &proto.Topic{Name: "topic"})
+		require.Error(t, err)
+		require.Equal(t, codes.NotFound, grpc.Code(err))
+	})
+
+	t.Run("InternalError", func(t *testing.T) {
+		var r mock.Registry
+
+		r.DeleteFn = func(topicName string) error {
+			assert.Equal(t, "topic", topicName)
+			return errors.New("something unexpected happened !")
+		}
+
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+
+		client := proto.NewRegistryServiceClient(conn)
+
+		_, err := client.Delete(context.Background(), &proto.Topic{Name: "topic"})
+		require.Error(t, err)
+		require.Equal(t, codes.Unknown, grpc.Code(err))
+	})
+}
+
+// migrator is a registry able to migrate topics.
+type migrator struct {
+	mock.Registry
+
+	migrateFn func(ctx context.Context, topicName, backendName string, options map[string]string) error
+}
+
+func (m *migrator) Migrate(ctx context.Context, topicName, backendName string, options map[string]string) error {
+	return m.migrateFn(ctx, topicName, backendName, options)
+}
+
+func TestRegistryServerMove(t *testing.T) {
+	t.Run("Rebind", func(t *testing.T) {
+		var r mock.Registry
+
+		r.RebindFn = func(topicName, backendName string, options map[string]string) error {
+			assert.Equal(t, "topic", topicName)
+			assert.Equal(t, "redis", backendName)
+			assert.Equal(t, map[string]string{"key": "quotes"}, options)
+			return nil
+		}
+
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+
+		client := proto.NewRegistryServiceClient(conn)
+
+		_, err := client.Move(context.Background(), &proto.MoveTopic{
+			Name:    "topic",
+			Backend: "redis",
+			Options: map[string]string{"key": "quotes"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, r.RebindInvoked)
+	})
+
+	t.Run("EmptyFields", func(t *testing.T) {
+		var r mock.Registry
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+		client := proto.NewRegistryServiceClient(conn)
+
+		_, err := client.Move(context.Background(), &proto.MoveTopic{Name: "topic"})
+		require.Error(t, err)
+		require.Equal(t, codes.InvalidArgument, grpc.Code(err))
+	})
+
+	t.Run("CopyNotSupported", func(t *testing.T) {
+		var r mock.Registry
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+		client := proto.NewRegistryServiceClient(conn)
+
+		_, err := client.Move(context.Background(), &proto.MoveTopic{Name: "topic", Backend: "redis", Copy: true})
+		require.Error(t, err)
+		require.Equal(t, codes.Unimplemented, grpc.Code(err))
+		require.Zero(t, r.RebindInvoked)
+	})
+}
+
+func TestRegistryServerGet(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		var r mock.Registry
+
+		createdAt := time.Now()
+		r.InfoFn = func(topicName string) (*lobby.TopicInfo, error) {
+			assert.Equal(t, "topic", topicName)
+			return &lobby.TopicInfo{Name: "topic", Backend: "backend", CreatedAt: createdAt}, nil
+		}
+
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+
+		client := proto.NewRegistryServiceClient(conn)
+
+		topic, err := client.Get(context.Background(), &proto.Topic{Name: "topic"})
+		require.NoError(t, err)
+		require.Equal(t, "topic", topic.Name)
+		require.Equal(t, "backend", topic.Backend)
+		require.Equal(t, createdAt.UnixNano(), topic.CreatedAt)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		var r mock.Registry
+
+		r.InfoFn = func(topicName string) (*lobby.TopicInfo, error) {
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+
+		client := proto.NewRegistryServiceClient(conn)
+
+		_, err := client.Get(context.Background(), &proto.Topic{Name: "topic"})
+		require.Error(t, err)
+		require.Equal(t, codes.NotFound, grpc.Code(err))
+	})
+}
+
+func TestRegistryServerList(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		var r mock.Registry
+
+		r.ListFn = func(offset, limit int) ([]lobby.TopicInfo, error) {
+			assert.Equal(t, 5, offset)
+			assert.Equal(t, 2, limit)
+			return []lobby.TopicInfo{{Name: "a", Backend: "backend"}, {Name: "b", Backend: "backend"}}, nil
+		}
+
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+
+		client := proto.NewRegistryServiceClient(conn)
+
+		list, err := client.List(context.Background(), &proto.ListTopics{Offset: 5, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, list.Topics, 2)
+		require.Equal(t, "a", list.Topics[0].Name)
+		require.Equal(t, "b", list.Topics[1].Name)
+	})
+
+	t.Run("DefaultLimit", func(t *testing.T) {
+		var r mock.Registry
+
+		r.ListFn = func(offset, limit int) ([]lobby.TopicInfo, error) {
+			assert.Equal(t, 0, offset)
+			assert.Equal(t, 20, limit)
+			return nil, nil
+		}
+
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+
+		client := proto.NewRegistryServiceClient(conn)
+
+		list, err := client.List(context.Background(), new(proto.ListTopics))
+		require.NoError(t, err)
+		require.Len(t, list.Topics, 0)
+	})
+
+	t.Run("InvalidPagination", func(t *testing.T) {
+		var r mock.Registry
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+
+		client := proto.NewRegistryServiceClient(conn)
+
+		_, err := client.List(context.Background(), &proto.ListTopics{Offset: -1, Limit: 1000})
+		require.Error(t, err)
+		require.Equal(t, codes.InvalidArgument, grpc.Code(err))
+		require.Zero(t, r.ListInvoked)
+	})
+}
+
 func newRegistry(t *testing.T, r lobby.Registry) (*rpc.Registry, func()) {
 	dir, err := ioutil.TempDir("", "lobby")
 	require.NoError(t, err)
@@ -193,7 +417,7 @@ func newRegistry(t *testing.T, r lobby.Registry) (*rpc.Registry, func()) {
 	l, err := net.Listen("unix", socketPath)
 	require.NoError(t, err)
 
-	srv := rpc.NewServer(log.New(log.Output(ioutil.Discard)), rpc.WithTopicService(r), rpc.WithRegistryService(r))
+	srv := rpc.NewServer(log.New(log.Output(ioutil.Discard)), rpc.WithRegistryTopicService(r, nil), rpc.WithRegistryService(r, nil))
 
 	go func() {
 		srv.Serve(l)
@@ -223,9 +447,10 @@ func TestRegistryCreate(t *testing.T) {
 	t.Run("OK", func(t *testing.T) {
 		var r mock.Registry
 
-		r.CreateFn = func(backendName, topicName string) error {
+		r.CreateFn = func(backendName, topicName string, options map[string]string) error {
 			assert.Equal(t, "backend", backendName)
 			assert.Equal(t, "topic", topicName)
+			assert.Equal(t, map[string]string{"key": "value"}, options)
 
 			return nil
 		}
@@ -233,7 +458,7 @@ func TestRegistryCreate(t *testing.T) {
 		reg, cleanup := newRegistry(t, &r)
 		defer cleanup()
 
-		err := reg.Create("backend", "topic")
+		err := reg.Create("backend", "topic", map[string]string{"key": "value"})
 		require.NoError(t, err)
 	})
 
@@ -257,23 +482,171 @@ func TestRegistryCreate(t *testing.T) {
 }
 
 func testRegistryCreateWith(t *testing.T, reg lobby.Registry, mockReg *mock.Registry, returnedErr, expectedErr error) {
-	mockReg.CreateFn = func(backendName, topicName string) error {
+	mockReg.CreateFn = func(backendName, topicName string, options map[string]string) error {
 		return returnedErr
 	}
 
-	err := reg.Create("backend", "topic")
+	err := reg.Create("backend", "topic", nil)
 	require.Error(t, err)
 	require.Equal(t, expectedErr, err)
 }
 
+func TestRegistryDelete(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		var r mock.Registry
+
+		r.DeleteFn = func(topicName string) error {
+			assert.Equal(t, "topic", topicName)
+			return nil
+		}
+
+		reg, cleanup := newRegistry(t, &r)
+		defer cleanup()
+
+		err := reg.Delete("topic")
+		require.NoError(t, err)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		var r mock.Registry
+
+		reg, cleanup := newRegistry(t, &r)
+		defer cleanup()
+
+		testCases := map[error]error{
+			lobby.ErrTopicNotFound:   lobby.ErrTopicNotFound,
+			errors.New("unexpected"): status.Error(codes.Unknown, rpc.ErrInternal.Error()),
+		}
+
+		for returnedErr, expectedErr := range testCases {
+			r.DeleteFn = func(topicName string) error {
+				return returnedErr
+			}
+
+			err := reg.Delete("topic")
+			require.Error(t, err)
+			require.Equal(t, expectedErr, err)
+		}
+	})
+}
+
+func TestRegistryMove(t *testing.T) {
+	t.Run("Rebind", func(t *testing.T) {
+		var r mock.Registry
+
+		r.RebindFn = func(topicName, backendName string, options map[string]string) error {
+			assert.Equal(t, "topic", topicName)
+			assert.Equal(t, "redis", backendName)
+			return lobby.ErrBackendNotFound
+		}
+
+		reg, cleanup := newRegistry(t, &r)
+		defer cleanup()
+
+		err := reg.Rebind("topic", "redis", nil)
+		require.Equal(t, lobby.ErrBackendNotFound, err)
+		require.Equal(t, 1, r.RebindInvoked)
+	})
+
+	t.Run("Migrate", func(t *testing.T) {
+		var invoked int
+		r := migrator{
+			migrateFn: func(ctx context.Context, topicName, backendName string, options map[string]string) error {
+				invoked++
+				assert.Equal(t, "topic", topicName)
+				assert.Equal(t, "redis", backendName)
+				assert.Equal(t, map[string]string{"key": "quotes"}, options)
+				return nil
+			},
+		}
+
+		reg, cleanup := newRegistry(t, &r)
+		defer cleanup()
+
+		err := reg.Migrate(context.Background(), "topic", "redis", map[string]string{"key": "quotes"})
+		require.NoError(t, err)
+		require.Equal(t, 1, invoked)
+		require.Zero(t, r.RebindInvoked)
+	})
+}
+
+func TestRegistryList(t *testing.T) {
+	var r mock.Registry
+
+	topics := make([]lobby.TopicInfo, 250)
+	for i := range topics {
+		topics[i] = lobby.TopicInfo{Name: fmt.Sprintf("topic%03d", i), Backend: "backend"}
+	}
+
+	r.ListFn = func(offset, limit int) ([]lobby.TopicInfo, error) {
+		if offset > len(topics) {
+			return nil, nil
+		}
+
+		list := topics[offset:]
+		if limit < len(list) {
+			list = list[:limit]
+		}
+		return list, nil
+	}
+
+	reg, cleanup := newRegistry(t, &r)
+	defer cleanup()
+
+	list, err := reg.List(0, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 250)
+	require.Equal(t, topics, list)
+
+	list, err = reg.List(10, 120)
+	require.NoError(t, err)
+	require.Len(t, list, 120)
+	require.Equal(t, "topic010", list[0].Name)
+
+	list, err = reg.List(300, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 0)
+}
+
+func TestRegistryInfo(t *testing.T) {
+	var r mock.Registry
+
+	createdAt := time.Now()
+	r.InfoFn = func(topicName string) (*lobby.TopicInfo, error) {
+		if topicName != "topic" {
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		return &lobby.TopicInfo{
+			Name:      "topic",
+			Backend:   "backend",
+			Options:   map[string]string{"key": "value"},
+			CreatedAt: createdAt,
+		}, nil
+	}
+
+	reg, cleanup := newRegistry(t, &r)
+	defer cleanup()
+
+	info, err := reg.Info("topic")
+	require.NoError(t, err)
+	require.Equal(t, "topic", info.Name)
+	require.Equal(t, "backend", info.Backend)
+	require.Equal(t, map[string]string{"key": "value"}, info.Options)
+	require.Equal(t, createdAt.UnixNano(), info.CreatedAt.UnixNano())
+
+	_, err = reg.Info("unknown")
+	require.Equal(t, lobby.ErrTopicNotFound, err)
+}
+
 func TestRegistryTopic(t *testing.T) {
 	t.Run("OK", func(t *testing.T) {
 		var r mock.Registry
 
-		r.TopicFn = func(name string) (lobby.Topic, error) {
+		r.InfoFn = func(name string) (*lobby.TopicInfo, error) {
 			assert.Equal(t, "topic", name)
 
-			return nil, nil
+			return &lobby.TopicInfo{Name: name}, nil
 		}
 
 		reg, cleanup := newRegistry(t, &r)
@@ -304,7 +677,7 @@ func TestRegistryTopic(t *testing.T) {
 }
 
 func testRegistryTopicWith(t *testing.T, reg lobby.Registry, mockReg *mock.Registry, returnedErr, expectedErr error) {
-	mockReg.TopicFn = func(name string) (lobby.Topic, error) {
+	mockReg.InfoFn = func(name string) (*lobby.TopicInfo, error) {
 		return nil, returnedErr
 	}
 
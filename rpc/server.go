@@ -1,47 +1,139 @@
 package rpc
 
 import (
+	"context"
+	"crypto/tls"
 	"net"
 
 	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/auth"
 	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/ratelimit"
 	"github.com/asdine/lobby/rpc/proto"
 	"github.com/grpc-ecosystem/go-grpc-middleware"
 	"github.com/grpc-ecosystem/go-grpc-middleware/recovery"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // NewServer returns a configured gRPC server.
 func NewServer(logger *log.Logger, services ...func(*grpc.Server, *log.Logger)) lobby.Server {
-	g := grpc.NewServer(
+	return NewServerWithConfig(logger, ServerConfig{}, services...)
+}
+
+// NewTLSServer returns a configured gRPC server only accepting TLS connections.
+// The verified certificates of the clients are stored in the context of their requests,
+// see auth.CertificateFromContext.
+func NewTLSServer(logger *log.Logger, cfg *tls.Config, services ...func(*grpc.Server, *log.Logger)) lobby.Server {
+	return NewServerWithConfig(logger, ServerConfig{TLS: cfg}, services...)
+}
+
+// ServerConfig holds the optional settings of a gRPC server.
+type ServerConfig struct {
+	// If set, the server only accepts TLS connections, see NewTLSServer.
+	TLS *tls.Config
+	// If set, requests exceeding the limits are rejected with the ResourceExhausted code.
+	Limiter *ratelimit.Limiter
+	// If set, clients are limited by the identity they are authenticated with.
+	// Clients without valid credentials are limited by their address.
+	Auth auth.Authenticator
+}
+
+// NewServerWithConfig returns a gRPC server configured by cfg.
+func NewServerWithConfig(logger *log.Logger, cfg ServerConfig, services ...func(*grpc.Server, *log.Logger)) lobby.Server {
+	s := server{
+		quit: make(chan struct{}),
+	}
+
+	var opts []grpc.ServerOption
+	if cfg.TLS != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(cfg.TLS)))
+	}
+
+	opts = append(opts,
 		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(
+			metricsUnaryInterceptor,
+			traceUnaryInterceptor,
+			logUnaryInterceptor,
+			rateLimitUnaryInterceptor(cfg.Limiter, cfg.Auth),
 			grpc_recovery.UnaryServerInterceptor(),
+			certificateUnaryInterceptor,
+		)),
+		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(
+			metricsStreamInterceptor,
+			traceStreamInterceptor,
+			logStreamInterceptor,
+			rateLimitStreamInterceptor(cfg.Limiter, cfg.Auth),
+			grpc_recovery.StreamServerInterceptor(),
+			s.streamInterceptor,
+			certificateStreamInterceptor,
 		)),
 	)
 
-	for _, s := range services {
-		s(g, logger)
+	s.srv = grpc.NewServer(opts...)
+
+	for _, service := range services {
+		service(s.srv, logger)
 	}
 
-	return &server{srv: g}
+	return &s
 }
 
-// WithTopicService enables the TopicService.
+// WithTopicService enables the TopicService on top of a backend.
+// It is used by backend plugins: topics are opened with the options sent by Lobby
+// and messages keep the ID and creation date given by Lobby.
 func WithTopicService(b lobby.Backend) func(*grpc.Server, *log.Logger) {
 	return func(g *grpc.Server, logger *log.Logger) {
-		proto.RegisterTopicServiceServer(g, newTopicService(b, logger))
+		subscriber, _ := b.(lobby.Subscriber)
+		proto.RegisterTopicServiceServer(g, newTopicService(b.Topic, subscriber, nil, true, logger))
+	}
+}
+
+// WithRegistryTopicService enables the TopicService on top of a registry.
+// Topics are opened with the options they were created with, the options
+// sent by clients are ignored. Messages are always given an ID and a creation date
+// by the server. Requests are authenticated using a, if not nil.
+func WithRegistryTopicService(r lobby.Registry, a auth.Authenticator) func(*grpc.Server, *log.Logger) {
+	return func(g *grpc.Server, logger *log.Logger) {
+		subscriber, _ := r.(lobby.Subscriber)
+		topic := func(name string, _ map[string]string) (lobby.Topic, error) {
+			return r.Topic(name)
+		}
+
+		proto.RegisterTopicServiceServer(g, newTopicService(topic, subscriber, a, false, logger))
 	}
 }
 
-// WithRegistryService enables the RegistryService.
-func WithRegistryService(r lobby.Registry) func(*grpc.Server, *log.Logger) {
+// WithRegistryService enables the RegistryService. Requests are authenticated using a, if not nil.
+func WithRegistryService(r lobby.Registry, a auth.Authenticator) func(*grpc.Server, *log.Logger) {
 	return func(g *grpc.Server, logger *log.Logger) {
-		proto.RegisterRegistryServiceServer(g, newRegistryService(r, logger))
+		proto.RegisterRegistryServiceServer(g, newRegistryService(r, a, logger))
 	}
 }
 
 type server struct {
-	srv *grpc.Server
+	srv  *grpc.Server
+	quit chan struct{}
+}
+
+// streamInterceptor cancels the context of running streams when the server is stopped,
+// otherwise long lived streams like subscriptions would prevent the server from stopping.
+func (s *server) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, cancel := context.WithCancel(ss.Context())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-s.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return handler(srv, &grpc_middleware.WrappedServerStream{
+		ServerStream:   ss,
+		WrappedContext: ctx,
+	})
 }
 
 func (s *server) Name() string {
@@ -53,6 +145,7 @@ func (s *server) Serve(l net.Listener) error {
 }
 
 func (s *server) Stop() error {
+	close(s.quit)
 	s.srv.GracefulStop()
 	return nil
 }
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/auth"
 	"github.com/asdine/lobby/log"
 	"github.com/asdine/lobby/rpc"
 	"github.com/stretchr/testify/require"
@@ -16,6 +17,14 @@ import (
 )
 
 func newServer(t *testing.T, r lobby.Registry) (*grpc.ClientConn, func()) {
+	return newServerWithAuth(t, r, nil)
+}
+
+func newServerWithAuth(t *testing.T, r lobby.Registry, a auth.Authenticator) (*grpc.ClientConn, func()) {
+	return newServerWithConfig(t, r, a, rpc.ServerConfig{})
+}
+
+func newServerWithConfig(t *testing.T, r lobby.Registry, a auth.Authenticator, cfg rpc.ServerConfig) (*grpc.ClientConn, func()) {
 	dir, err := ioutil.TempDir("", "lobby")
 	require.NoError(t, err)
 
@@ -23,7 +32,7 @@ func newServer(t *testing.T, r lobby.Registry) (*grpc.ClientConn, func()) {
 	l, err := net.Listen("unix", socketPath)
 	require.NoError(t, err)
 
-	srv := rpc.NewServer(log.New(log.Output(ioutil.Discard)), rpc.WithTopicService(r), rpc.WithRegistryService(r))
+	srv := rpc.NewServerWithConfig(log.New(log.Output(ioutil.Discard)), cfg, rpc.WithRegistryTopicService(r, a), rpc.WithRegistryService(r, a))
 
 	go func() {
 		srv.Serve(l)
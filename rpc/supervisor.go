@@ -0,0 +1,239 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
+	"github.com/pkg/errors"
+)
+
+// Maximum duration of a plugin restart.
+const pluginStartTimeout = 5 * time.Second
+
+// RestartPolicy controls how crashed plugins are restarted.
+type RestartPolicy struct {
+	// Maximum number of restarts within Window before giving up.
+	// If zero, crashed plugins are not restarted.
+	MaxRestarts int
+	// Period over which restarts are counted.
+	Window time.Duration
+	// Delay before restarting a plugin that crashed for the first time within Window.
+	// It is doubled after each restart, up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRestartPolicy allows 5 restarts every 10 minutes.
+var DefaultRestartPolicy = RestartPolicy{
+	MaxRestarts: 5,
+	Window:      10 * time.Minute,
+	MinBackoff:  100 * time.Millisecond,
+	MaxBackoff:  30 * time.Second,
+}
+
+// backoff returns the delay before restarting a plugin that was already restarted n times within the window.
+func (p *RestartPolicy) backoff(n int) time.Duration {
+	d := p.MinBackoff
+	for i := 0; i < n && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	return d
+}
+
+// SuperviseBackendPlugin loads a backend plugin and restarts it when it crashes, according to the given policy.
+// The returned backend always uses the connection to the running plugin, it can be registered once.
+// Waiting on the returned plugin blocks until it is closed or until it crashed more times than the policy allows.
+func SuperviseBackendPlugin(ctx context.Context, name, cmdPath, dataDir, configFile string, timeout time.Duration, policy RestartPolicy, logger *log.Logger) (lobby.Backend, lobby.Plugin, error) {
+	return superviseBackend(ctx, name, func(ctx context.Context) (*Backend, lobby.Plugin, error) {
+		return loadBackendPlugin(ctx, name, cmdPath, dataDir, configFile, timeout)
+	}, policy, logger)
+}
+
+func superviseBackend(ctx context.Context, name string, load func(context.Context) (*Backend, lobby.Plugin, error), policy RestartPolicy, logger *log.Logger) (lobby.Backend, lobby.Plugin, error) {
+	bck, plg, err := load(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s := backendSupervisor{
+		name:    name,
+		load:    load,
+		policy:  policy,
+		logger:  logger,
+		backend: bck,
+		plugin:  plg,
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go s.run()
+
+	return &supervisedBackend{&s}, &s, nil
+}
+
+// backendSupervisor restarts a backend plugin when it crashes.
+type backendSupervisor struct {
+	name   string
+	load   func(context.Context) (*Backend, lobby.Plugin, error)
+	policy RestartPolicy
+	logger *log.Logger
+
+	m       sync.RWMutex
+	backend *Backend
+	plugin  lobby.Plugin
+	closed  bool
+
+	quit chan struct{}
+	done chan struct{}
+	// reason why the supervisor stopped, set before done is closed.
+	err error
+}
+
+// Name of the plugin.
+func (s *backendSupervisor) Name() string {
+	return s.name
+}
+
+// Close the running plugin and stops restarting it.
+func (s *backendSupervisor) Close() error {
+	s.m.Lock()
+	if s.closed {
+		s.m.Unlock()
+		return nil
+	}
+
+	s.closed = true
+	close(s.quit)
+	plugin := s.plugin
+	s.m.Unlock()
+
+	return plugin.Close()
+}
+
+// Wait until the supervisor stops. It returns an error if the plugin crashed and couldn't be restarted,
+// or if it crashed while closing.
+func (s *backendSupervisor) Wait() error {
+	<-s.done
+	return s.err
+}
+
+func (s *backendSupervisor) run() {
+	defer close(s.done)
+
+	// time of each restart within the window.
+	var restarts []time.Time
+
+	for {
+		s.m.RLock()
+		plugin := s.plugin
+		s.m.RUnlock()
+
+		err := plugin.Wait()
+
+		select {
+		case <-s.quit:
+			s.err = err
+			return
+		default:
+		}
+
+		if err == nil {
+			return
+		}
+
+		s.logger.Error(err)
+
+		for {
+			now := time.Now()
+			for len(restarts) > 0 && now.Sub(restarts[0]) > s.policy.Window {
+				restarts = restarts[1:]
+			}
+
+			if len(restarts) >= s.policy.MaxRestarts {
+				s.err = errors.Wrapf(err, "gave up restarting plugin %s after %d restarts", s.name, len(restarts))
+				return
+			}
+
+			delay := s.policy.backoff(len(restarts))
+			restarts = append(restarts, now)
+
+			select {
+			case <-s.quit:
+				return
+			case <-time.After(delay):
+			}
+
+			err = s.restart()
+			if err == nil {
+				break
+			}
+
+			if err == errSupervisorClosed {
+				return
+			}
+
+			err = errors.Wrapf(err, "failed to restart plugin %s", s.name)
+			s.logger.Error(err)
+		}
+
+		pluginRestarts.Inc(s.name)
+		s.logger.Printf("Restarted %s plugin\n", s.name)
+	}
+}
+
+var errSupervisorClosed = errors.New("supervisor closed")
+
+// restart loads a new instance of the plugin and swaps it with the crashed one.
+func (s *backendSupervisor) restart() error {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginStartTimeout)
+	defer cancel()
+
+	bck, plg, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.closed {
+		// the supervisor was closed during the restart.
+		plg.Close()
+		return errSupervisorClosed
+	}
+
+	// the connection to the crashed plugin is not closed by the plugin itself.
+	if s.backend.conn != nil {
+		s.backend.conn.Close()
+	}
+
+	s.backend = bck
+	s.plugin = plg
+	return nil
+}
+
+// supervisedBackend is a backend that always uses the running instance of a supervised plugin.
+type supervisedBackend struct {
+	s *backendSupervisor
+}
+
+// Topic returns the topic associated with the given name.
+func (b *supervisedBackend) Topic(name string, options map[string]string) (lobby.Topic, error) {
+	b.s.m.RLock()
+	defer b.s.m.RUnlock()
+
+	return b.s.backend.Topic(name, options)
+}
+
+// Close does nothing, the connection is closed with the plugin.
+func (b *supervisedBackend) Close() error {
+	return nil
+}
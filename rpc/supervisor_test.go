@@ -0,0 +1,183 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePlugin crashes when its crash channel is closed.
+type fakePlugin struct {
+	crash     chan struct{}
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newFakePlugin() *fakePlugin {
+	return &fakePlugin{
+		crash:  make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+}
+
+func (p *fakePlugin) Name() string {
+	return "fake"
+}
+
+func (p *fakePlugin) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.closed)
+	})
+	return nil
+}
+
+func (p *fakePlugin) Wait() error {
+	select {
+	case <-p.crash:
+		return errors.New("plugin fake exited unexpectedly")
+	case <-p.closed:
+		return nil
+	}
+}
+
+// fakeLoader returns a load function that records the plugins it starts.
+// Loads fail once the number of plugins reaches max.
+func fakeLoader(max int) (func(context.Context) (*Backend, lobby.Plugin, error), func() []*fakePlugin) {
+	var m sync.Mutex
+	var plugins []*fakePlugin
+
+	load := func(ctx context.Context) (*Backend, lobby.Plugin, error) {
+		m.Lock()
+		defer m.Unlock()
+
+		if len(plugins) >= max {
+			return nil, nil, errors.New("failed to start")
+		}
+
+		p := newFakePlugin()
+		plugins = append(plugins, p)
+		bck, _ := NewBackend(nil, 0)
+		return bck, p, nil
+	}
+
+	return load, func() []*fakePlugin {
+		m.Lock()
+		defer m.Unlock()
+		return append([]*fakePlugin(nil), plugins...)
+	}
+}
+
+func waitForPlugins(t *testing.T, plugins func() []*fakePlugin, n int) []*fakePlugin {
+	deadline := time.Now().Add(time.Second)
+	for len(plugins()) < n {
+		require.True(t, time.Now().Before(deadline), "plugin wasn't restarted")
+		time.Sleep(time.Millisecond)
+	}
+
+	return plugins()
+}
+
+func TestSuperviseBackend(t *testing.T) {
+	logger := log.New(log.Output(ioutil.Discard))
+	policy := RestartPolicy{
+		MaxRestarts: 2,
+		Window:      time.Minute,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	}
+
+	t.Run("Restart", func(t *testing.T) {
+		load, plugins := fakeLoader(10)
+		restarts := pluginRestarts.Value("fake")
+
+		bck, plg, err := superviseBackend(context.Background(), "fake", load, policy, logger)
+		require.NoError(t, err)
+		require.Equal(t, "fake", plg.Name())
+
+		first := plugins()[0]
+		close(first.crash)
+
+		list := waitForPlugins(t, plugins, 2)
+		_, err = bck.Topic("topic", nil)
+		require.NoError(t, err)
+
+		sup := plg.(*backendSupervisor)
+		sup.m.RLock()
+		require.Equal(t, list[1], sup.plugin)
+		sup.m.RUnlock()
+		require.Equal(t, restarts+1, pluginRestarts.Value("fake"))
+
+		err = plg.Close()
+		require.NoError(t, err)
+		require.NoError(t, plg.Wait())
+
+		select {
+		case <-list[1].closed:
+		default:
+			t.Error("the running plugin wasn't closed")
+		}
+	})
+
+	t.Run("GiveUp", func(t *testing.T) {
+		load, plugins := fakeLoader(10)
+
+		_, plg, err := superviseBackend(context.Background(), "fake", load, policy, logger)
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			list := waitForPlugins(t, plugins, i+1)
+			close(list[i].crash)
+		}
+
+		err = plg.Wait()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "gave up restarting plugin fake after 2 restarts")
+		require.Len(t, plugins(), 3)
+	})
+
+	t.Run("FailedRestarts", func(t *testing.T) {
+		load, plugins := fakeLoader(1)
+
+		_, plg, err := superviseBackend(context.Background(), "fake", load, policy, logger)
+		require.NoError(t, err)
+
+		close(plugins()[0].crash)
+
+		err = plg.Wait()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to start")
+	})
+
+	t.Run("NoRestart", func(t *testing.T) {
+		load, plugins := fakeLoader(10)
+
+		_, plg, err := superviseBackend(context.Background(), "fake", load, RestartPolicy{}, logger)
+		require.NoError(t, err)
+
+		close(plugins()[0].crash)
+
+		err = plg.Wait()
+		require.Error(t, err)
+		require.Len(t, plugins(), 1)
+	})
+}
+
+func TestRestartPolicyBackoff(t *testing.T) {
+	p := RestartPolicy{
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: time.Second,
+	}
+
+	require.Equal(t, 100*time.Millisecond, p.backoff(0))
+	require.Equal(t, 200*time.Millisecond, p.backoff(1))
+	require.Equal(t, 800*time.Millisecond, p.backoff(3))
+	require.Equal(t, time.Second, p.backoff(4))
+	require.Equal(t, time.Second, p.backoff(100))
+}
@@ -2,44 +2,271 @@ package rpc
 
 import (
 	"context"
+	"time"
 
 	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/auth"
 	"github.com/asdine/lobby/log"
 	"github.com/asdine/lobby/rpc/proto"
 	"github.com/asdine/lobby/validation"
+	"google.golang.org/grpc/codes"
 )
 
-func newTopicService(b lobby.Backend, logger *log.Logger) *topicService {
+// newTopicService returns a topicService fetching topics with the given function.
+// Subscriptions are only supported if subscriber isn't nil. Requests are authenticated
+// only if a isn't nil. Messages keep the ID and creation date sent by the client only
+// if keepIDs is true, otherwise they are given new ones.
+func newTopicService(topic func(name string, options map[string]string) (lobby.Topic, error), subscriber lobby.Subscriber, a auth.Authenticator, keepIDs bool, logger *log.Logger) *topicService {
 	return &topicService{
-		backend: b,
-		logger:  logger,
+		topic:      topic,
+		subscriber: subscriber,
+		auth:       a,
+		keepIDs:    keepIDs,
+		logger:     logger,
 	}
 }
 
 type topicService struct {
-	backend lobby.Backend
-	logger  *log.Logger
+	topic      func(name string, options map[string]string) (lobby.Topic, error)
+	subscriber lobby.Subscriber
+	auth       auth.Authenticator
+	keepIDs    bool
+	logger     *log.Logger
+}
+
+// message converts a message sent by a client, giving it an ID and a creation date
+// unless it already has an ID and the service keeps them.
+func (s *topicService) message(pm *proto.Message) *lobby.Message {
+	m := lobbyMessage(pm)
+	if !s.keepIDs || m.ID == "" {
+		return lobby.NewMessage(m.Group, m.Value, m.Headers)
+	}
+
+	return &m
 }
 
 // Send an message to a topic.
-func (s *topicService) Send(ctx context.Context, message *proto.NewMessage) (*proto.Empty, error) {
+func (s *topicService) Send(ctx context.Context, message *proto.NewMessage) (*proto.MessageID, error) {
+	log.AddFields(ctx, log.Fields{"topic": message.Topic})
+
 	err := validation.Validate(message)
 	if err != nil {
-		return nil, newError(err, s.logger)
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	err = authorize(ctx, s.auth, message.Topic, auth.Send)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	t, err := s.topic(message.Topic, message.Options)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+	defer t.Close()
+
+	m := s.message(message.Message)
+
+	err = t.Send(ctx, m)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	return &proto.MessageID{Id: m.ID}, nil
+}
+
+// SendBatch sends several messages to a topic.
+// Invalid messages and messages that couldn't be sent are reported in the results.
+func (s *topicService) SendBatch(ctx context.Context, req *proto.NewMessages) (*proto.BatchResults, error) {
+	log.AddFields(ctx, log.Fields{"topic": req.Topic})
+
+	err := validation.Validate(req)
+	if len(req.Messages) == 0 || len(req.Messages) > maxBatchSize {
+		err = validation.AddError(err, "messages", errInvalidBatch)
+	}
+
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	err = authorize(ctx, s.auth, req.Topic, auth.Send)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	t, err := s.topic(req.Topic, req.Options)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+	defer t.Close()
+
+	resp := proto.BatchResults{
+		Results: make([]*proto.BatchResult, len(req.Messages)),
+	}
+
+	messages := make([]*lobby.Message, 0, len(req.Messages))
+	// position in the batch of each valid message.
+	indexes := make([]int, 0, len(req.Messages))
+
+	for i, pm := range req.Messages {
+		resp.Results[i] = new(proto.BatchResult)
+
+		err = validation.Validate(pm)
+		if err != nil {
+			resp.Results[i].Error = err.Error()
+			resp.Results[i].Code = uint32(codes.InvalidArgument)
+			continue
+		}
+
+		m := s.message(pm)
+		resp.Results[i].Id = m.ID
+		messages = append(messages, m)
+		indexes = append(indexes, i)
+	}
+
+	if len(messages) == 0 {
+		return &resp, nil
+	}
+
+	errs, err := lobby.SendBatch(ctx, t, messages)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	for i := range errs {
+		if errs[i] != nil {
+			msg, code := batchError(errs[i], s.logger.WithContext(ctx))
+			resp.Results[indexes[i]].Error = msg
+			resp.Results[indexes[i]].Code = uint32(code)
+		}
+	}
+
+	return &resp, nil
+}
+
+// Read messages stored in a topic.
+func (s *topicService) Read(ctx context.Context, req *proto.ReadMessages) (*proto.Messages, error) {
+	log.AddFields(ctx, log.Fields{"topic": req.Topic})
+
+	err := validation.Validate(req)
+	if req.Limit < 0 || req.Limit > maxPageLimit {
+		err = validation.AddError(err, "limit", errInvalidLimit)
+	}
+
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
 	}
 
-	t, err := s.backend.Topic(message.Topic)
+	err = authorize(ctx, s.auth, req.Topic, auth.Read)
 	if err != nil {
-		return nil, newError(err, s.logger)
+		return nil, newError(err, s.logger.WithContext(ctx))
 	}
 
-	err = t.Send(&lobby.Message{
-		Group: message.Message.Group,
-		Value: message.Message.Value,
-	})
+	t, err := s.topic(req.Topic, req.Options)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+	defer t.Close()
+
+	r, ok := t.(lobby.TopicReader)
+	if !ok {
+		return nil, newError(lobby.ErrNotSupported, s.logger.WithContext(ctx))
+	}
+
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+
+	list, next, err := r.Read(ctx, req.Group, req.Cursor, limit)
+	if err != nil {
+		return nil, newError(err, s.logger.WithContext(ctx))
+	}
+
+	page := proto.Messages{
+		Messages: make([]*proto.Message, len(list)),
+		Next:     next,
+	}
+
+	for i := range list {
+		page.Messages[i] = newMessage(&list[i])
+	}
+
+	return &page, nil
+}
+
+// Subscribe to the messages sent to a topic.
+func (s *topicService) Subscribe(req *proto.Subscription, stream proto.TopicService_SubscribeServer) error {
+	ctx := stream.Context()
+	log.AddFields(ctx, log.Fields{"topic": req.Topic})
+
+	err := validation.Validate(req)
 	if err != nil {
-		return nil, newError(err, s.logger)
+		return newError(err, s.logger.WithContext(ctx))
+	}
+
+	err = authorize(ctx, s.auth, req.Topic, auth.Read)
+	if err != nil {
+		return newError(err, s.logger.WithContext(ctx))
+	}
+
+	if s.subscriber == nil {
+		return newError(lobby.ErrNotSupported, s.logger.WithContext(ctx))
+	}
+
+	sub, err := s.subscriber.Subscribe(req.Topic, req.Group)
+	if err != nil {
+		return newError(err, s.logger.WithContext(ctx))
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case m, ok := <-sub.Messages():
+			if !ok {
+				err = sub.Err()
+				if err != nil {
+					return newError(err, s.logger.WithContext(ctx))
+				}
+				return nil
+			}
+
+			err = stream.Send(newMessage(m))
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func newMessage(m *lobby.Message) *proto.Message {
+	pm := proto.Message{
+		Id:      m.ID,
+		Group:   m.Group,
+		Value:   m.Value,
+		Headers: m.Headers,
+	}
+
+	if !m.CreatedAt.IsZero() {
+		pm.CreatedAt = m.CreatedAt.UnixNano()
+	}
+
+	return &pm
+}
+
+func lobbyMessage(pm *proto.Message) lobby.Message {
+	m := lobby.Message{
+		ID:      pm.Id,
+		Group:   pm.Group,
+		Value:   pm.Value,
+		Headers: pm.Headers,
+	}
+
+	if pm.CreatedAt != 0 {
+		m.CreatedAt = time.Unix(0, pm.CreatedAt).UTC()
 	}
 
-	return new(proto.Empty), nil
+	return m
 }
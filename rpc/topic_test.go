@@ -3,11 +3,16 @@ package rpc_test
 import (
 	"context"
 	"errors"
+	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/log"
 	"github.com/asdine/lobby/mock"
+	"github.com/asdine/lobby/pubsub"
 	"github.com/asdine/lobby/rpc/proto"
+	"github.com/asdine/lobby/schema"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
@@ -17,14 +22,50 @@ import (
 func TestTopicServerSend(t *testing.T) {
 	t.Run("OK", func(t *testing.T) {
 		var r mock.Registry
+		var topic mock.Topic
 
 		r.TopicFn = func(name string) (lobby.Topic, error) {
 			assert.Equal(t, "topic", name)
 
-			return &mock.Topic{
-				SendFn: func(message *lobby.Message) error {
+			topic = mock.Topic{
+				SendFn: func(ctx context.Context, message *lobby.Message) error {
 					assert.Equal(t, "group", message.Group)
 					assert.Equal(t, "value", string(message.Value))
+					assert.NotEmpty(t, message.ID)
+					assert.False(t, message.CreatedAt.IsZero())
+					assert.Equal(t, "test", message.Headers["source"])
+					return nil
+				},
+			}
+			return &topic, nil
+		}
+
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+
+		client := proto.NewTopicServiceClient(conn)
+
+		id, err := client.Send(context.Background(), &proto.NewMessage{
+			Message: &proto.Message{
+				Group:   "group",
+				Value:   []byte("value"),
+				Headers: map[string]string{"source": "test"},
+			},
+			Topic: "topic",
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, id.Id)
+		require.Equal(t, 1, topic.CloseInvoked)
+	})
+
+	t.Run("WithID", func(t *testing.T) {
+		var r mock.Registry
+		var sent *lobby.Message
+
+		r.TopicFn = func(name string) (lobby.Topic, error) {
+			return &mock.Topic{
+				SendFn: func(ctx context.Context, message *lobby.Message) error {
+					sent = message
 					return nil
 				},
 			}, nil
@@ -35,14 +76,20 @@ func TestTopicServerSend(t *testing.T) {
 
 		client := proto.NewTopicServiceClient(conn)
 
-		_, err := client.Send(context.Background(), &proto.NewMessage{
+		id, err := client.Send(context.Background(), &proto.NewMessage{
 			Message: &proto.Message{
-				Group: "group",
-				Value: []byte("value"),
+				Id:        "abc",
+				CreatedAt: 10,
+				Group:     "group",
+				Value:     []byte("value"),
 			},
 			Topic: "topic",
 		})
 		require.NoError(t, err)
+		// IDs and creation dates are always assigned by the server.
+		require.NotEqual(t, "abc", sent.ID)
+		require.Equal(t, sent.ID, id.Id)
+		require.WithinDuration(t, time.Now(), sent.CreatedAt, time.Second)
 	})
 
 	t.Run("EmptyFields", func(t *testing.T) {
@@ -78,13 +125,37 @@ func TestTopicServerSend(t *testing.T) {
 		require.Equal(t, codes.NotFound, grpc.Code(err))
 	})
 
+	t.Run("Schema", func(t *testing.T) {
+		s, err := schema.Compile([]byte(`{"type": "object", "required": ["id"]}`))
+		require.NoError(t, err)
+
+		var tp mock.Topic
+		var r mock.Registry
+		r.TopicFn = func(name string) (lobby.Topic, error) {
+			return schema.Middleware(s)(&tp), nil
+		}
+
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+		client := proto.NewTopicServiceClient(conn)
+
+		_, err = client.Send(context.Background(), &proto.NewMessage{
+			Message: &proto.Message{Value: []byte(`{"name": "a"}`)},
+			Topic:   "topic",
+		})
+		require.Error(t, err)
+		require.Equal(t, codes.InvalidArgument, grpc.Code(err))
+		require.Equal(t, "value.id: is required", grpc.ErrorDesc(err))
+		require.Zero(t, tp.SendInvoked)
+	})
+
 	t.Run("InternalError", func(t *testing.T) {
 		var r mock.Registry
 		r.TopicFn = func(name string) (lobby.Topic, error) {
 			assert.Equal(t, "topic", name)
 
 			return &mock.Topic{
-				SendFn: func(message *lobby.Message) error {
+				SendFn: func(ctx context.Context, message *lobby.Message) error {
 					assert.Equal(t, "group", message.Group)
 					assert.Equal(t, "value", string(message.Value))
 					return errors.New("something unexpected happened !")
@@ -107,3 +178,249 @@ func TestTopicServerSend(t *testing.T) {
 		require.Equal(t, codes.Unknown, grpc.Code(err))
 	})
 }
+
+func TestTopicServerSendBatch(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		var r mock.Registry
+
+		r.TopicFn = func(name string) (lobby.Topic, error) {
+			assert.Equal(t, "topic", name)
+
+			return &mock.Topic{
+				SendBatchFn: func(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+					require.Len(t, messages, 3)
+					assert.NotEqual(t, "abc", messages[0].ID)
+					assert.NotEmpty(t, messages[1].ID)
+					assert.False(t, messages[1].CreatedAt.IsZero())
+					return []error{nil, errors.New("something unexpected"), lobby.ErrNotSupported}, nil
+				},
+			}, nil
+		}
+
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+
+		client := proto.NewTopicServiceClient(conn)
+
+		resp, err := client.SendBatch(context.Background(), &proto.NewMessages{
+			Topic: "topic",
+			Messages: []*proto.Message{
+				{Id: "abc", Value: []byte("value1")},
+				{Value: []byte("value2")},
+				{Group: "empty"},
+				{Value: []byte("value3")},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 4)
+		require.NotEmpty(t, resp.Results[0].Id)
+		require.NotEqual(t, "abc", resp.Results[0].Id)
+		require.Empty(t, resp.Results[0].Error)
+		require.NotEmpty(t, resp.Results[1].Id)
+		require.Equal(t, "internal_error", resp.Results[1].Error)
+		require.Equal(t, uint32(codes.Unknown), resp.Results[1].Code)
+		require.Empty(t, resp.Results[2].Id)
+		require.NotEmpty(t, resp.Results[2].Error)
+		require.Equal(t, uint32(codes.InvalidArgument), resp.Results[2].Code)
+		require.Equal(t, lobby.ErrNotSupported.Error(), resp.Results[3].Error)
+		require.Equal(t, uint32(codes.Unimplemented), resp.Results[3].Code)
+	})
+
+	t.Run("EmptyBatch", func(t *testing.T) {
+		var r mock.Registry
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+		client := proto.NewTopicServiceClient(conn)
+
+		_, err := client.SendBatch(context.Background(), &proto.NewMessages{Topic: "topic"})
+		require.Error(t, err)
+		require.Equal(t, codes.InvalidArgument, grpc.Code(err))
+	})
+
+	t.Run("TopicNotFound", func(t *testing.T) {
+		var r mock.Registry
+		r.TopicFn = func(name string) (lobby.Topic, error) {
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+		client := proto.NewTopicServiceClient(conn)
+
+		_, err := client.SendBatch(context.Background(), &proto.NewMessages{
+			Topic:    "unknown",
+			Messages: []*proto.Message{{Value: []byte("value")}},
+		})
+		require.Error(t, err)
+		require.Equal(t, codes.NotFound, grpc.Code(err))
+	})
+}
+
+func TestTopicServerRead(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		var r mock.Registry
+
+		r.TopicFn = func(name string) (lobby.Topic, error) {
+			assert.Equal(t, "topic", name)
+
+			return &mock.Topic{
+				ReadFn: func(ctx context.Context, group, cursor string, limit int) ([]lobby.Message, string, error) {
+					assert.Equal(t, "group", group)
+					assert.Equal(t, "cursor", cursor)
+					assert.Equal(t, 20, limit)
+					return []lobby.Message{{Group: "group", Value: []byte("value")}}, "next", nil
+				},
+			}, nil
+		}
+
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+
+		client := proto.NewTopicServiceClient(conn)
+
+		page, err := client.Read(context.Background(), &proto.ReadMessages{
+			Topic:  "topic",
+			Group:  "group",
+			Cursor: "cursor",
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Messages, 1)
+		require.Equal(t, "value", string(page.Messages[0].Value))
+		require.Equal(t, "next", page.Next)
+	})
+
+	t.Run("InvalidLimit", func(t *testing.T) {
+		var r mock.Registry
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+		client := proto.NewTopicServiceClient(conn)
+
+		_, err := client.Read(context.Background(), &proto.ReadMessages{Topic: "topic", Limit: 1000})
+		require.Error(t, err)
+		require.Equal(t, codes.InvalidArgument, grpc.Code(err))
+	})
+
+	t.Run("NotSupported", func(t *testing.T) {
+		var r mock.Registry
+		r.TopicFn = func(name string) (lobby.Topic, error) {
+			return lobby.TopicFunc(func(context.Context, *lobby.Message) error {
+				return nil
+			}), nil
+		}
+
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+		client := proto.NewTopicServiceClient(conn)
+
+		_, err := client.Read(context.Background(), &proto.ReadMessages{Topic: "topic"})
+		require.Error(t, err)
+		require.Equal(t, codes.Unimplemented, grpc.Code(err))
+	})
+
+	t.Run("InvalidCursor", func(t *testing.T) {
+		var r mock.Registry
+		r.TopicFn = func(name string) (lobby.Topic, error) {
+			return &mock.Topic{
+				ReadFn: func(ctx context.Context, group, cursor string, limit int) ([]lobby.Message, string, error) {
+					return nil, "", lobby.ErrInvalidCursor
+				},
+			}, nil
+		}
+
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+		client := proto.NewTopicServiceClient(conn)
+
+		_, err := client.Read(context.Background(), &proto.ReadMessages{Topic: "topic", Cursor: "cursor"})
+		require.Error(t, err)
+		require.Equal(t, codes.InvalidArgument, grpc.Code(err))
+	})
+}
+
+func TestTopicServerSubscribe(t *testing.T) {
+	newRegistry := func() *pubsub.Registry {
+		var m mock.Registry
+
+		m.InfoFn = func(name string) (*lobby.TopicInfo, error) {
+			if name != "topic" {
+				return nil, lobby.ErrTopicNotFound
+			}
+
+			return &lobby.TopicInfo{Name: name}, nil
+		}
+
+		m.TopicFn = func(name string) (lobby.Topic, error) {
+			return lobby.TopicFunc(func(context.Context, *lobby.Message) error {
+				return nil
+			}), nil
+		}
+
+		return pubsub.NewRegistry(&m, 10, log.New(log.Output(ioutil.Discard)))
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		r := newRegistry()
+		conn, cleanup := newServer(t, r)
+		defer cleanup()
+		client := proto.NewTopicServiceClient(conn)
+
+		stream, err := client.Subscribe(context.Background(), &proto.Subscription{Topic: "topic", Group: "group"})
+		require.NoError(t, err)
+
+		// the stream might not be subscribed yet, send until the first message is received.
+		done := make(chan struct{})
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				case <-time.After(10 * time.Millisecond):
+					client.Send(context.Background(), &proto.NewMessage{
+						Topic:   "topic",
+						Message: &proto.Message{Group: "group", Value: []byte("value")},
+					})
+				}
+			}
+		}()
+
+		m, err := stream.Recv()
+		close(done)
+		require.NoError(t, err)
+		require.Equal(t, "group", m.Group)
+		require.Equal(t, "value", string(m.Value))
+	})
+
+	t.Run("EmptyFields", func(t *testing.T) {
+		conn, cleanup := newServer(t, newRegistry())
+		defer cleanup()
+		client := proto.NewTopicServiceClient(conn)
+
+		stream, err := client.Subscribe(context.Background(), new(proto.Subscription))
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.Equal(t, codes.InvalidArgument, grpc.Code(err))
+	})
+
+	t.Run("TopicNotFound", func(t *testing.T) {
+		conn, cleanup := newServer(t, newRegistry())
+		defer cleanup()
+		client := proto.NewTopicServiceClient(conn)
+
+		stream, err := client.Subscribe(context.Background(), &proto.Subscription{Topic: "unknown"})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.Equal(t, codes.NotFound, grpc.Code(err))
+	})
+
+	t.Run("NotSupported", func(t *testing.T) {
+		var r mock.Registry
+		conn, cleanup := newServer(t, &r)
+		defer cleanup()
+		client := proto.NewTopicServiceClient(conn)
+
+		stream, err := client.Subscribe(context.Background(), &proto.Subscription{Topic: "topic"})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.Equal(t, codes.Unimplemented, grpc.Code(err))
+	})
+}
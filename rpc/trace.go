@@ -0,0 +1,72 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/asdine/lobby/trace"
+	"github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// traceparentKey is the metadata holding the W3C trace context of a request.
+const traceparentKey = "traceparent"
+
+// withTraceparent adds the span context stored in ctx, if any, to the outgoing metadata.
+func withTraceparent(ctx context.Context) context.Context {
+	sc, ok := trace.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	return withMetadata(ctx, traceparentKey, sc.Traceparent())
+}
+
+// traceparent returns the context holding the trace context sent by the client, if any.
+func traceparent(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md[traceparentKey]) == 0 {
+		return ctx
+	}
+
+	return trace.ContextWithTraceparent(ctx, md[traceparentKey][0])
+}
+
+func traceUnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, span := trace.Start(ctx, method, trace.Client)
+	defer span.Finish()
+
+	err := invoker(withTraceparent(ctx), method, req, reply, cc, opts...)
+	span.SetAttribute("rpc.grpc.status_code", grpc.Code(err).String())
+	span.SetError(err)
+	return err
+}
+
+func traceStreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(withTraceparent(ctx), desc, cc, method, opts...)
+}
+
+// traceUnaryInterceptor measures the requests with a span, child of the one of the client if any.
+func traceUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, span := trace.Start(traceparent(ctx), info.FullMethod, trace.Server)
+	defer span.Finish()
+
+	resp, err := handler(ctx, req)
+	span.SetAttribute("rpc.grpc.status_code", grpc.Code(err).String())
+	span.SetError(err)
+	return resp, err
+}
+
+// traceStreamInterceptor measures the streams with a span, child of the one of the client if any.
+func traceStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, span := trace.Start(traceparent(ss.Context()), info.FullMethod, trace.Server)
+	defer span.Finish()
+
+	err := handler(srv, &grpc_middleware.WrappedServerStream{
+		ServerStream:   ss,
+		WrappedContext: ctx,
+	})
+	span.SetAttribute("rpc.grpc.status_code", grpc.Code(err).String())
+	span.SetError(err)
+	return err
+}
@@ -0,0 +1,70 @@
+package rpc_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/mock"
+	"github.com/asdine/lobby/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracing(t *testing.T) {
+	var mu sync.Mutex
+	var spans []*trace.Span
+	var e mock.Exporter
+	e.ExportFn = func(s *trace.Span) {
+		mu.Lock()
+		spans = append(spans, s)
+		mu.Unlock()
+	}
+
+	trace.SetExporter(&e)
+	defer trace.SetExporter(nil)
+
+	var b mock.Backend
+	var remote trace.SpanContext
+	b.TopicFn = func(name string, options map[string]string) (lobby.Topic, error) {
+		return &mock.Topic{
+			SendFn: func(ctx context.Context, message *lobby.Message) error {
+				remote, _ = trace.FromContext(ctx)
+				return nil
+			},
+		}, nil
+	}
+
+	backend, cleanup := newBackend(t, &b)
+	defer cleanup()
+
+	topic, err := backend.Topic("topic", nil)
+	require.NoError(t, err)
+
+	ctx, root := trace.Start(context.Background(), "root", trace.Internal)
+	err = topic.Send(ctx, &lobby.Message{Value: []byte("Value")})
+	require.NoError(t, err)
+	root.Finish()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// server span, client span and root span.
+	require.Len(t, spans, 3)
+	server, client := spans[0], spans[1]
+	if server.Kind != trace.Server {
+		server, client = client, server
+	}
+
+	require.Equal(t, "/proto.TopicService/Send", client.Name)
+	require.Equal(t, trace.Client, client.Kind)
+	require.Equal(t, root.Context.SpanID, client.Parent)
+	require.Equal(t, "/proto.TopicService/Send", server.Name)
+	require.Equal(t, trace.Server, server.Kind)
+	require.Equal(t, client.Context.SpanID, server.Parent)
+	require.Equal(t, "OK", server.Attributes["rpc.grpc.status_code"])
+
+	// the backend receives the context of the server span.
+	require.Equal(t, root.Context.TraceID, remote.TraceID)
+	require.Equal(t, server.Context.SpanID, remote.SpanID)
+}
@@ -0,0 +1,132 @@
+package schema
+
+import (
+	"context"
+	"sync"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/validation"
+)
+
+// Option is the topic option holding the JSON Schema the messages of the topic must match.
+// It is never given to the backends.
+const Option = "schema"
+
+// Middleware rejects the messages whose value doesn't match the schema with a validation error.
+func Middleware(s *Schema) lobby.Middleware {
+	return lobby.MessageFunc(func(ctx context.Context, m *lobby.Message) error {
+		return s.Validate(m.Value)
+	})
+}
+
+var _ lobby.Registry = new(Registry)
+
+// NewRegistry returns a Registry validating the messages of the topics created with a schema.
+// The schema of a topic is stored in its options, so any registry can be used.
+func NewRegistry(r lobby.Registry) *Registry {
+	return &Registry{
+		Registry: r,
+		schemas:  make(map[string]*Schema),
+	}
+}
+
+// Registry is a registry whose topics can validate their messages against a JSON Schema.
+type Registry struct {
+	lobby.Registry
+
+	mu sync.RWMutex
+	// compiled schemas, indexed by their source.
+	schemas map[string]*Schema
+}
+
+// RegisterBackend registers the backend in the underlying registry. Topics opened by the
+// backend validate their messages against the schema found in their options.
+func (r *Registry) RegisterBackend(name string, backend lobby.Backend) {
+	r.Registry.RegisterBackend(name, &validatingBackend{Backend: backend, registry: r})
+}
+
+// Create a topic in the underlying registry, after making sure its schema is valid.
+func (r *Registry) Create(backendName, topicName string, options map[string]string) error {
+	err := r.check(options)
+	if err != nil {
+		return err
+	}
+
+	return r.Registry.Create(backendName, topicName, options)
+}
+
+// Rebind a topic to another backend in the underlying registry, after making sure its schema is valid.
+func (r *Registry) Rebind(topicName, backendName string, options map[string]string) error {
+	err := r.check(options)
+	if err != nil {
+		return err
+	}
+
+	return r.Registry.Rebind(topicName, backendName, options)
+}
+
+// check the schema found in the options, if any.
+func (r *Registry) check(options map[string]string) error {
+	if src, ok := options[Option]; ok {
+		if _, err := r.compile(src); err != nil {
+			return validation.AddError(nil, Option, err)
+		}
+	}
+
+	return nil
+}
+
+// compile the schema, or return it from the cache.
+func (r *Registry) compile(src string) (*Schema, error) {
+	r.mu.RLock()
+	s, ok := r.schemas[src]
+	r.mu.RUnlock()
+	if ok {
+		return s, nil
+	}
+
+	s, err := Compile([]byte(src))
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.schemas[src] = s
+	r.mu.Unlock()
+
+	return s, nil
+}
+
+// validatingBackend opens the topics of a backend, checking their messages against their schema.
+type validatingBackend struct {
+	lobby.Backend
+
+	registry *Registry
+}
+
+// Topic returns the topic of the backend, validating its messages if it has a schema.
+func (b *validatingBackend) Topic(name string, options map[string]string) (lobby.Topic, error) {
+	src, ok := options[Option]
+	if !ok {
+		return b.Backend.Topic(name, options)
+	}
+
+	s, err := b.registry.compile(src)
+	if err != nil {
+		return nil, err
+	}
+
+	rest := make(map[string]string, len(options))
+	for k, v := range options {
+		if k != Option {
+			rest[k] = v
+		}
+	}
+
+	t, err := b.Backend.Topic(name, rest)
+	if err != nil {
+		return nil, err
+	}
+
+	return Middleware(s)(t), nil
+}
@@ -0,0 +1,74 @@
+package schema_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/mock"
+	"github.com/asdine/lobby/schema"
+	"github.com/asdine/lobby/validation"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	var m mock.Registry
+	topics := make(map[string]lobby.TopicInfo)
+
+	m.CreateFn = func(backend, name string, options map[string]string) error {
+		topics[name] = lobby.TopicInfo{Name: name, Backend: backend, Options: options}
+		return nil
+	}
+
+	m.TopicFn = func(name string) (lobby.Topic, error) {
+		info, ok := topics[name]
+		if !ok {
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		return m.Backends[info.Backend].Topic(name, info.Options)
+	}
+
+	var options map[string]string
+	var tp mock.Topic
+	r := schema.NewRegistry(&m)
+	r.RegisterBackend("bolt", &mock.Backend{
+		TopicFn: func(name string, opts map[string]string) (lobby.Topic, error) {
+			options = opts
+			return &tp, nil
+		},
+	})
+
+	t.Run("InvalidSchema", func(t *testing.T) {
+		err := r.Create("bolt", "a", map[string]string{schema.Option: `{"type": 1}`})
+		require.True(t, validation.IsError(err))
+		require.Error(t, validation.LastError(err, schema.Option))
+		require.Zero(t, m.CreateInvoked)
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		err := r.Create("bolt", "b", map[string]string{schema.Option: `{"type": "object"}`, "max-age": "1h"})
+		require.NoError(t, err)
+
+		topic, err := r.Topic("b")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"max-age": "1h"}, options)
+
+		err = topic.Send(context.Background(), lobby.NewMessage("", []byte(`"a"`), nil))
+		require.Equal(t, "must be of type object", validation.LastError(err, schema.Root).Error())
+		require.Zero(t, tp.SendInvoked)
+
+		err = topic.Send(context.Background(), lobby.NewMessage("", []byte(`{"a": 1}`), nil))
+		require.NoError(t, err)
+		require.Equal(t, 1, tp.SendInvoked)
+	})
+
+	t.Run("NoSchema", func(t *testing.T) {
+		err := r.Create("bolt", "c", nil)
+		require.NoError(t, err)
+
+		topic, err := r.Topic("c")
+		require.NoError(t, err)
+		require.Equal(t, &tp, topic)
+	})
+}
@@ -0,0 +1,684 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/asdine/lobby/validation"
+	"github.com/pkg/errors"
+)
+
+// Root is the name of the field under which the errors of the whole value are reported.
+// The errors of nested values are reported under their path, like "value.user.name" or "value.tags.0".
+const Root = "value"
+
+// Schema is a compiled JSON Schema.
+// It supports the validation keywords of draft 7, except format, dependencies, if/then/else
+// and the references to other documents.
+type Schema struct {
+	root *node
+}
+
+// Compile parses a JSON Schema.
+func Compile(data []byte) (*Schema, error) {
+	doc, err := decode(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid schema")
+	}
+
+	c := compiler{doc: doc, refs: make(map[string]*node)}
+	root, err := c.compile(doc, "#")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schema{root: root}, nil
+}
+
+// Validate the JSON document. It returns a validation error listing the failures of each field.
+func (s *Schema) Validate(data []byte) error {
+	value, err := decode(data)
+	if err != nil {
+		return validation.AddError(nil, Root, errors.New("must be valid JSON"))
+	}
+
+	var v validator
+	s.root.validate(&v, Root, value)
+	return v.err
+}
+
+// decode a JSON document, keeping numbers as json.Number.
+func decode(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if dec.More() {
+		return nil, errors.New("unexpected data after the JSON document")
+	}
+
+	return v, nil
+}
+
+// node is a compiled schema or subschema.
+type node struct {
+	// always is set for the boolean schemas true and false.
+	always *bool
+	// ref points to the node of a $ref. Other keywords are ignored, as in draft 7.
+	ref *node
+
+	types    []string
+	enum     []interface{}
+	constant interface{}
+	hasConst bool
+
+	properties        map[string]*node
+	patternProperties map[*regexp.Regexp]*node
+	additional        *node
+	required          []string
+	minProperties     *int
+	maxProperties     *int
+
+	items       *node
+	tupleItems  []*node
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+
+	allOf []*node
+	anyOf []*node
+	oneOf []*node
+	not   *node
+}
+
+type compiler struct {
+	doc  interface{}
+	refs map[string]*node
+}
+
+func (c *compiler) compile(raw interface{}, path string) (*node, error) {
+	var n node
+	if err := c.fill(&n, raw, path); err != nil {
+		return nil, err
+	}
+
+	return &n, nil
+}
+
+func (c *compiler) fill(n *node, raw interface{}, path string) error {
+	if b, ok := raw.(bool); ok {
+		n.always = &b
+		return nil
+	}
+
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return errors.Errorf("invalid schema at %s: must be an object or a boolean", path)
+	}
+
+	var err error
+	invalid := func(keyword, reason string) error {
+		return errors.Errorf("invalid schema at %s: %s %s", path, keyword, reason)
+	}
+
+	if ref, ok := obj["$ref"]; ok {
+		s, ok := ref.(string)
+		if !ok {
+			return invalid("$ref", "must be a string")
+		}
+
+		n.ref, err = c.resolve(s)
+		return err
+	}
+
+	if t, ok := obj["type"]; ok {
+		switch t := t.(type) {
+		case string:
+			n.types = []string{t}
+		case []interface{}:
+			for _, v := range t {
+				s, ok := v.(string)
+				if !ok {
+					return invalid("type", "must be a string or an array of strings")
+				}
+				n.types = append(n.types, s)
+			}
+		default:
+			return invalid("type", "must be a string or an array of strings")
+		}
+
+		for _, t := range n.types {
+			switch t {
+			case "null", "boolean", "object", "array", "number", "integer", "string":
+			default:
+				return invalid("type", fmt.Sprintf("has unknown type %q", t))
+			}
+		}
+	}
+
+	if e, ok := obj["enum"]; ok {
+		list, ok := e.([]interface{})
+		if !ok {
+			return invalid("enum", "must be an array")
+		}
+		for _, v := range list {
+			n.enum = append(n.enum, normalize(v))
+		}
+	}
+
+	if v, ok := obj["const"]; ok {
+		n.constant = normalize(v)
+		n.hasConst = true
+	}
+
+	if p, ok := obj["properties"]; ok {
+		props, ok := p.(map[string]interface{})
+		if !ok {
+			return invalid("properties", "must be an object")
+		}
+
+		n.properties = make(map[string]*node, len(props))
+		for name, raw := range props {
+			if n.properties[name], err = c.compile(raw, path+"/properties/"+name); err != nil {
+				return err
+			}
+		}
+	}
+
+	if p, ok := obj["patternProperties"]; ok {
+		props, ok := p.(map[string]interface{})
+		if !ok {
+			return invalid("patternProperties", "must be an object")
+		}
+
+		n.patternProperties = make(map[*regexp.Regexp]*node, len(props))
+		for pattern, raw := range props {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return invalid("patternProperties", "must contain valid regular expressions")
+			}
+
+			if n.patternProperties[re], err = c.compile(raw, path+"/patternProperties/"+pattern); err != nil {
+				return err
+			}
+		}
+	}
+
+	if raw, ok := obj["additionalProperties"]; ok {
+		if n.additional, err = c.compile(raw, path+"/additionalProperties"); err != nil {
+			return err
+		}
+	}
+
+	if r, ok := obj["required"]; ok {
+		list, ok := r.([]interface{})
+		if !ok {
+			return invalid("required", "must be an array of strings")
+		}
+		for _, v := range list {
+			s, ok := v.(string)
+			if !ok {
+				return invalid("required", "must be an array of strings")
+			}
+			n.required = append(n.required, s)
+		}
+	}
+
+	if raw, ok := obj["items"]; ok {
+		if list, ok := raw.([]interface{}); ok {
+			for i, raw := range list {
+				item, err := c.compile(raw, path+"/items/"+strconv.Itoa(i))
+				if err != nil {
+					return err
+				}
+				n.tupleItems = append(n.tupleItems, item)
+			}
+		} else if n.items, err = c.compile(raw, path+"/items"); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := obj["uniqueItems"]; ok {
+		b, ok := v.(bool)
+		if !ok {
+			return invalid("uniqueItems", "must be a boolean")
+		}
+		n.uniqueItems = b
+	}
+
+	if p, ok := obj["pattern"]; ok {
+		s, ok := p.(string)
+		if !ok {
+			return invalid("pattern", "must be a string")
+		}
+
+		if n.pattern, err = regexp.Compile(s); err != nil {
+			return invalid("pattern", "must be a valid regular expression")
+		}
+	}
+
+	for keyword, dst := range map[string]**int{
+		"minProperties": &n.minProperties,
+		"maxProperties": &n.maxProperties,
+		"minItems":      &n.minItems,
+		"maxItems":      &n.maxItems,
+		"minLength":     &n.minLength,
+		"maxLength":     &n.maxLength,
+	} {
+		v, ok := obj[keyword]
+		if !ok {
+			continue
+		}
+
+		i, ok := toInt(v)
+		if !ok {
+			return invalid(keyword, "must be a non-negative integer")
+		}
+		*dst = &i
+	}
+
+	for keyword, dst := range map[string]**float64{
+		"minimum":          &n.minimum,
+		"maximum":          &n.maximum,
+		"exclusiveMinimum": &n.exclusiveMinimum,
+		"exclusiveMaximum": &n.exclusiveMaximum,
+		"multipleOf":       &n.multipleOf,
+	} {
+		v, ok := obj[keyword]
+		if !ok {
+			continue
+		}
+
+		f, ok := toFloat(v)
+		if !ok || (keyword == "multipleOf" && f <= 0) {
+			return invalid(keyword, "must be a number")
+		}
+		*dst = &f
+	}
+
+	for keyword, dst := range map[string]*[]*node{
+		"allOf": &n.allOf,
+		"anyOf": &n.anyOf,
+		"oneOf": &n.oneOf,
+	} {
+		v, ok := obj[keyword]
+		if !ok {
+			continue
+		}
+
+		list, ok := v.([]interface{})
+		if !ok || len(list) == 0 {
+			return invalid(keyword, "must be a non-empty array")
+		}
+
+		for i, raw := range list {
+			sub, err := c.compile(raw, path+"/"+keyword+"/"+strconv.Itoa(i))
+			if err != nil {
+				return err
+			}
+			*dst = append(*dst, sub)
+		}
+	}
+
+	if raw, ok := obj["not"]; ok {
+		if n.not, err = c.compile(raw, path+"/not"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolve a reference to a part of the schema document, like "#" or "#/definitions/user".
+// Each reference is compiled once, which allows recursive schemas.
+func (c *compiler) resolve(ref string) (*node, error) {
+	if n, ok := c.refs[ref]; ok {
+		return n, nil
+	}
+
+	if ref != "#" && !strings.HasPrefix(ref, "#/") {
+		return nil, errors.Errorf("invalid schema: unsupported reference %q", ref)
+	}
+
+	raw := c.doc
+	if ref != "#" {
+		for _, token := range strings.Split(ref[2:], "/") {
+			token = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+
+			obj, ok := raw.(map[string]interface{})
+			if !ok {
+				return nil, errors.Errorf("invalid schema: unresolvable reference %q", ref)
+			}
+
+			if raw, ok = obj[token]; !ok {
+				return nil, errors.Errorf("invalid schema: unresolvable reference %q", ref)
+			}
+		}
+	}
+
+	var n node
+	c.refs[ref] = &n
+	return &n, c.fill(&n, raw, ref)
+}
+
+// validator collects the failures of a document.
+type validator struct {
+	err error
+}
+
+func (v *validator) fail(path, format string, args ...interface{}) {
+	v.err = validation.AddError(v.err, path, fmt.Errorf(format, args...))
+}
+
+// matches returns true if the value is valid against the node.
+func (n *node) matches(path string, value interface{}) bool {
+	var v validator
+	n.validate(&v, path, value)
+	return v.err == nil
+}
+
+func (n *node) validate(v *validator, path string, value interface{}) {
+	if n.always != nil {
+		if !*n.always {
+			v.fail(path, "is not allowed")
+		}
+		return
+	}
+
+	if n.ref != nil {
+		n.ref.validate(v, path, value)
+		return
+	}
+
+	if len(n.types) > 0 && !hasType(value, n.types) {
+		v.fail(path, "must be of type %s", strings.Join(n.types, " or "))
+		// the other keywords would only report the same error.
+		return
+	}
+
+	if n.enum != nil {
+		found := false
+		for _, e := range n.enum {
+			if reflect.DeepEqual(e, normalize(value)) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			v.fail(path, "must be one of the allowed values")
+		}
+	}
+
+	if n.hasConst && !reflect.DeepEqual(n.constant, normalize(value)) {
+		v.fail(path, "must be equal to the constant value")
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		n.validateObject(v, path, value)
+	case []interface{}:
+		n.validateArray(v, path, value)
+	case string:
+		n.validateString(v, path, value)
+	case json.Number:
+		f, _ := value.Float64()
+		n.validateNumber(v, path, f)
+	}
+
+	for _, sub := range n.allOf {
+		sub.validate(v, path, value)
+	}
+
+	if n.anyOf != nil {
+		found := false
+		for _, sub := range n.anyOf {
+			if sub.matches(path, value) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			v.fail(path, "must match at least one of the schemas")
+		}
+	}
+
+	if n.oneOf != nil {
+		count := 0
+		for _, sub := range n.oneOf {
+			if sub.matches(path, value) {
+				count++
+			}
+		}
+
+		if count != 1 {
+			v.fail(path, "must match exactly one of the schemas")
+		}
+	}
+
+	if n.not != nil && n.not.matches(path, value) {
+		v.fail(path, "must not match the schema")
+	}
+}
+
+func (n *node) validateObject(v *validator, path string, obj map[string]interface{}) {
+	for _, name := range n.required {
+		if _, ok := obj[name]; !ok {
+			v.fail(join(path, name), "is required")
+		}
+	}
+
+	if n.minProperties != nil && len(obj) < *n.minProperties {
+		v.fail(path, "must have at least %d properties", *n.minProperties)
+	}
+
+	if n.maxProperties != nil && len(obj) > *n.maxProperties {
+		v.fail(path, "must have at most %d properties", *n.maxProperties)
+	}
+
+	// properties are validated in order so that errors are reported deterministically.
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := obj[name]
+		matched := false
+
+		if sub, ok := n.properties[name]; ok {
+			sub.validate(v, join(path, name), value)
+			matched = true
+		}
+
+		for re, sub := range n.patternProperties {
+			if re.MatchString(name) {
+				sub.validate(v, join(path, name), value)
+				matched = true
+			}
+		}
+
+		if !matched && n.additional != nil {
+			n.additional.validate(v, join(path, name), value)
+		}
+	}
+}
+
+func (n *node) validateArray(v *validator, path string, list []interface{}) {
+	if n.minItems != nil && len(list) < *n.minItems {
+		v.fail(path, "must contain at least %d items", *n.minItems)
+	}
+
+	if n.maxItems != nil && len(list) > *n.maxItems {
+		v.fail(path, "must contain at most %d items", *n.maxItems)
+	}
+
+	for i, item := range list {
+		switch {
+		case i < len(n.tupleItems):
+			n.tupleItems[i].validate(v, join(path, strconv.Itoa(i)), item)
+		case n.items != nil:
+			n.items.validate(v, join(path, strconv.Itoa(i)), item)
+		}
+	}
+
+	if n.uniqueItems {
+		for i := range list {
+			for j := i + 1; j < len(list); j++ {
+				if reflect.DeepEqual(normalize(list[i]), normalize(list[j])) {
+					v.fail(path, "must contain unique items")
+					return
+				}
+			}
+		}
+	}
+}
+
+func (n *node) validateString(v *validator, path string, s string) {
+	length := utf8.RuneCountInString(s)
+
+	if n.minLength != nil && length < *n.minLength {
+		v.fail(path, "must be at least %d characters long", *n.minLength)
+	}
+
+	if n.maxLength != nil && length > *n.maxLength {
+		v.fail(path, "must be at most %d characters long", *n.maxLength)
+	}
+
+	if n.pattern != nil && !n.pattern.MatchString(s) {
+		v.fail(path, "must match the pattern %s", n.pattern)
+	}
+}
+
+func (n *node) validateNumber(v *validator, path string, f float64) {
+	if n.minimum != nil && f < *n.minimum {
+		v.fail(path, "must be greater than or equal to %v", *n.minimum)
+	}
+
+	if n.maximum != nil && f > *n.maximum {
+		v.fail(path, "must be less than or equal to %v", *n.maximum)
+	}
+
+	if n.exclusiveMinimum != nil && f <= *n.exclusiveMinimum {
+		v.fail(path, "must be greater than %v", *n.exclusiveMinimum)
+	}
+
+	if n.exclusiveMaximum != nil && f >= *n.exclusiveMaximum {
+		v.fail(path, "must be less than %v", *n.exclusiveMaximum)
+	}
+
+	if n.multipleOf != nil {
+		q := f / *n.multipleOf
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(path, "must be a multiple of %v", *n.multipleOf)
+		}
+	}
+}
+
+func join(path, name string) string {
+	return path + "." + name
+}
+
+func hasType(value interface{}, types []string) bool {
+	for _, t := range types {
+		switch value := value.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		case json.Number:
+			if t == "number" {
+				return true
+			}
+
+			if f, err := value.Float64(); err == nil && t == "integer" && f == math.Trunc(f) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// normalize converts the numbers of a decoded value to float64, so that values can be compared
+// regardless of how their numbers are written, like 1 and 1.0.
+func normalize(value interface{}) interface{} {
+	switch value := value.(type) {
+	case json.Number:
+		f, _ := value.Float64()
+		return f
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i := range value {
+			list[i] = normalize(value[i])
+		}
+		return list
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			obj[k] = normalize(v)
+		}
+		return obj
+	default:
+		return value
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+func toInt(v interface{}) (int, bool) {
+	f, ok := toFloat(v)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return 0, false
+	}
+
+	return int(f), true
+}
@@ -0,0 +1,143 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/asdine/lobby/schema"
+	"github.com/asdine/lobby/validation"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		schema string
+		ok     bool
+	}{
+		{`true`, true},
+		{`{}`, true},
+		{`{"type": ["string", "null"]}`, true},
+		{`{"definitions": {"id": {"type": "integer"}}, "properties": {"id": {"$ref": "#/definitions/id"}}}`, true},
+		{`{"properties": {"next": {"$ref": "#"}}}`, true},
+		{`{"type": "date"}`, false},
+		{`{"pattern": "a("}`, false},
+		{`{"minLength": -1}`, false},
+		{`{"multipleOf": 0}`, false},
+		{`{"anyOf": []}`, false},
+		{`{"$ref": "#/definitions/missing"}`, false},
+		{`{"$ref": "http://example.com/schema.json"}`, false},
+		{`[]`, false},
+		{`{`, false},
+	}
+
+	for _, test := range tests {
+		_, err := schema.Compile([]byte(test.schema))
+		if test.ok {
+			require.NoError(t, err, test.schema)
+		} else {
+			require.Error(t, err, test.schema)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s, err := schema.Compile([]byte(`{
+		"type": "object",
+		"required": ["id", "user"],
+		"additionalProperties": false,
+		"definitions": {
+			"name": {"type": "string", "minLength": 1, "maxLength": 5}
+		},
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"user": {
+				"type": "object",
+				"required": ["name"],
+				"properties": {
+					"name": {"$ref": "#/definitions/name"},
+					"email": {"type": "string", "pattern": "^[^@]+@[^@]+$"}
+				}
+			},
+			"status": {"enum": ["open", "closed"]},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 3, "uniqueItems": true},
+			"price": {"type": "number", "exclusiveMinimum": 0, "multipleOf": 0.01},
+			"ref": {"oneOf": [{"type": "integer"}, {"type": "string", "pattern": "^[0-9]+$"}]}
+		}
+	}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		value  string
+		errors map[string]string
+	}{
+		{`{"id": 1, "user": {"name": "bob"}}`, nil},
+		{`{"id": 1.0, "user": {"name": "bob", "email": "bob@example.com"}, "status": "open", "tags": ["a", "b"], "price": 9.99, "ref": 12}`, nil},
+		{`[]`, map[string]string{"value": "must be of type object"}},
+		{`{"id": 1`, map[string]string{"value": "must be valid JSON"}},
+		{`{}`, map[string]string{"value.id": "is required", "value.user": "is required"}},
+		{`{"id": 0.5, "user": {"name": ""}}`, map[string]string{
+			"value.id":        "must be of type integer",
+			"value.user.name": "must be at least 1 characters long",
+		}},
+		{`{"id": 0, "user": {"name": "robert", "email": "bob"}}`, map[string]string{
+			"value.id":         "must be greater than or equal to 1",
+			"value.user.name":  "must be at most 5 characters long",
+			"value.user.email": "must match the pattern ^[^@]+@[^@]+$",
+		}},
+		{`{"id": 1, "user": {"name": "bob"}, "status": "draft", "other": true}`, map[string]string{
+			"value.status": "must be one of the allowed values",
+			"value.other":  "is not allowed",
+		}},
+		{`{"id": 1, "user": {"name": "bob"}, "tags": ["a", "b", 1, "c"]}`, map[string]string{
+			"value.tags":   "must contain at most 3 items",
+			"value.tags.2": "must be of type string",
+		}},
+		{`{"id": 1, "user": {"name": "bob"}, "price": 0, "ref": "a"}`, map[string]string{
+			"value.price": "must be greater than 0",
+			"value.ref":   "must match exactly one of the schemas",
+		}},
+		{`{"id": 1, "user": {"name": "bob"}, "price": 1.005}`, map[string]string{
+			"value.price": "must be a multiple of 0.01",
+		}},
+	}
+
+	for _, test := range tests {
+		err := s.Validate([]byte(test.value))
+		if test.errors == nil {
+			require.NoError(t, err, test.value)
+			continue
+		}
+
+		require.True(t, validation.IsError(err), test.value)
+		for field, msg := range test.errors {
+			fieldErr := validation.LastError(err, field)
+			require.Error(t, fieldErr, test.value)
+			require.Equal(t, msg, fieldErr.Error(), test.value)
+		}
+	}
+}
+
+func TestValidateRecursive(t *testing.T) {
+	s, err := schema.Compile([]byte(`{
+		"type": "object",
+		"properties": {
+			"value": {"type": "integer"},
+			"next": {"$ref": "#"}
+		}
+	}`))
+	require.NoError(t, err)
+
+	require.NoError(t, s.Validate([]byte(`{"value": 1, "next": {"value": 2, "next": {}}}`)))
+
+	err = s.Validate([]byte(`{"value": 1, "next": {"value": 2, "next": {"value": "3"}}}`))
+	require.Equal(t, "must be of type integer", validation.LastError(err, "value.next.next.value").Error())
+}
+
+func TestValidateUniqueItems(t *testing.T) {
+	s, err := schema.Compile([]byte(`{"uniqueItems": true}`))
+	require.NoError(t, err)
+
+	require.NoError(t, s.Validate([]byte(`[1, "1", {"a": 1}, {"a": 2}]`)))
+
+	err = s.Validate([]byte(`[{"a": 1}, {"a": 1.0}]`))
+	require.Equal(t, "must contain unique items", validation.LastError(err, schema.Root).Error())
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/cli"
+	nsq "github.com/nsqio/go-nsq"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultNSQAddr     = "127.0.0.1:4150"
+	defaultMaxInFlight = 1
+)
+
+// Config of the plugin.
+type Config struct {
+	NSQAddr      string
+	LookupdAddrs []string `toml:"lookupd-addrs"`
+	MaxInFlight  int      `toml:"max-in-flight"`
+	Consumers    []ConsumerConfig
+}
+
+// ConsumerConfig maps a NSQ topic and channel to a Lobby topic.
+type ConsumerConfig struct {
+	Topic   string
+	Channel string
+	// Name of the Lobby topic the messages are sent to.
+	Target string
+	// Group of the messages sent to the Lobby topic. Optional.
+	Group string
+}
+
+func main() {
+	var cfg Config
+
+	cli.RunServer("nsq", func(r lobby.Registry) (lobby.Server, error) {
+		if cfg.NSQAddr == "" && len(cfg.LookupdAddrs) == 0 {
+			cfg.NSQAddr = defaultNSQAddr
+		}
+
+		if cfg.MaxInFlight <= 0 {
+			cfg.MaxInFlight = defaultMaxInFlight
+		}
+
+		for _, c := range cfg.Consumers {
+			if c.Topic == "" || c.Channel == "" || c.Target == "" {
+				return nil, errors.New("consumers require a topic, a channel and a target")
+			}
+		}
+
+		return NewServer(r, &cfg), nil
+	}, &cfg)
+}
+
+var _ lobby.Server = new(Server)
+
+// NewServer returns a NSQ consumer server.
+func NewServer(r lobby.Registry, cfg *Config) *Server {
+	return &Server{
+		registry: r,
+		cfg:      cfg,
+		quit:     make(chan struct{}),
+	}
+}
+
+// Server consumes NSQ topics and forwards the messages to Lobby topics.
+type Server struct {
+	registry lobby.Registry
+	cfg      *Config
+
+	m         sync.Mutex
+	consumers []*nsq.Consumer
+	quit      chan struct{}
+}
+
+// Name of the server.
+func (s *Server) Name() string {
+	return "nsq"
+}
+
+// Serve connects the consumers and blocks until the server is stopped.
+// The listener is unused.
+func (s *Server) Serve(l net.Listener) error {
+	config := nsq.NewConfig()
+	config.MaxInFlight = s.cfg.MaxInFlight
+
+	for _, c := range s.cfg.Consumers {
+		consumer, err := nsq.NewConsumer(c.Topic, c.Channel, config)
+		if err != nil {
+			s.Stop()
+			return errors.Wrapf(err, "failed to create consumer for topic %s", c.Topic)
+		}
+
+		consumer.SetLogger(log.New(os.Stderr, "", 0), nsq.LogLevelInfo)
+		consumer.AddConcurrentHandlers(newHandler(s.registry, c.Target, c.Group), s.cfg.MaxInFlight)
+
+		s.m.Lock()
+		select {
+		case <-s.quit:
+			// the server was stopped while connecting.
+			s.m.Unlock()
+			consumer.Stop()
+			return nil
+		default:
+		}
+		s.consumers = append(s.consumers, consumer)
+		s.m.Unlock()
+
+		if len(s.cfg.LookupdAddrs) > 0 {
+			err = consumer.ConnectToNSQLookupds(s.cfg.LookupdAddrs)
+		} else {
+			err = consumer.ConnectToNSQD(s.cfg.NSQAddr)
+		}
+		if err != nil {
+			s.Stop()
+			return errors.Wrapf(err, "failed to connect consumer of topic %s", c.Topic)
+		}
+	}
+
+	<-s.quit
+	return nil
+}
+
+// Stop the consumers and waits for the messages being handled to be processed.
+func (s *Server) Stop() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	select {
+	case <-s.quit:
+		return nil
+	default:
+	}
+
+	for _, c := range s.consumers {
+		c.Stop()
+	}
+
+	for _, c := range s.consumers {
+		<-c.StopChan
+	}
+
+	close(s.quit)
+	return nil
+}
+
+func newHandler(r lobby.Registry, topic, group string) nsq.Handler {
+	return nsq.HandlerFunc(func(m *nsq.Message) error {
+		t, err := r.Topic(topic)
+		if err != nil {
+			return errors.Wrapf(err, "failed to fetch topic %s", topic)
+		}
+		defer t.Close()
+
+		// returning an error requeues the message.
+		err = t.Send(context.Background(), lobby.NewMessage(group, m.Body, nil))
+		return errors.Wrapf(err, "failed to send message to topic %s", topic)
+	})
+}
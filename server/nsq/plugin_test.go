@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/mock"
+	nsq "github.com/nsqio/go-nsq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	var id nsq.MessageID
+	copy(id[:], "0123456789abcdef")
+
+	t.Run("OK", func(t *testing.T) {
+		var r mock.Registry
+		topic := mock.Topic{
+			SendFn: func(ctx context.Context, m *lobby.Message) error {
+				require.Equal(t, "group", m.Group)
+				require.Equal(t, "value", string(m.Value))
+				return nil
+			},
+		}
+
+		r.TopicFn = func(name string) (lobby.Topic, error) {
+			require.Equal(t, "target", name)
+			return &topic, nil
+		}
+
+		err := newHandler(&r, "target", "group").HandleMessage(nsq.NewMessage(id, []byte("value")))
+		require.NoError(t, err)
+		require.Equal(t, 1, topic.SendInvoked)
+		require.Equal(t, 1, topic.CloseInvoked)
+	})
+
+	t.Run("TopicNotFound", func(t *testing.T) {
+		var r mock.Registry
+		r.TopicFn = func(name string) (lobby.Topic, error) {
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		err := newHandler(&r, "target", "").HandleMessage(nsq.NewMessage(id, []byte("value")))
+		require.Error(t, err)
+	})
+
+	t.Run("SendError", func(t *testing.T) {
+		var r mock.Registry
+		r.TopicFn = func(name string) (lobby.Topic, error) {
+			return &mock.Topic{
+				SendFn: func(ctx context.Context, m *lobby.Message) error {
+					return errors.New("unexpected error")
+				},
+			}, nil
+		}
+
+		err := newHandler(&r, "target", "").HandleMessage(nsq.NewMessage(id, []byte("value")))
+		require.Error(t, err)
+	})
+}
+
+func TestServerStop(t *testing.T) {
+	var r mock.Registry
+	s := NewServer(&r, &Config{NSQAddr: defaultNSQAddr, MaxInFlight: 1})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err := s.Serve(nil)
+		require.NoError(t, err)
+	}()
+
+	err := s.Stop()
+	require.NoError(t, err)
+	<-done
+
+	err = s.Stop()
+	require.NoError(t, err)
+}
+
+// nsqd is a fake nsqd delivering a single message to the first consumer
+// and recording the commands it receives.
+type nsqd struct {
+	net.Listener
+	commands chan string
+}
+
+func newNSQD(t *testing.T, id nsq.MessageID, body []byte) *nsqd {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	n := nsqd{
+		Listener: l,
+		commands: make(chan string, 16),
+	}
+
+	go n.serve(id, body)
+	return &n
+}
+
+func (n *nsqd) serve(id nsq.MessageID, body []byte) {
+	conn, err := n.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	// protocol magic
+	if _, err = io.ReadFull(r, make([]byte, 4)); err != nil {
+		return
+	}
+
+	var sent bool
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "IDENTIFY":
+			var size int32
+			if err = binary.Read(r, binary.BigEndian, &size); err != nil {
+				return
+			}
+			if _, err = io.CopyN(ioutil.Discard, r, int64(size)); err != nil {
+				return
+			}
+			writeFrame(conn, nsq.FrameTypeResponse, []byte("OK"))
+		case "SUB":
+			writeFrame(conn, nsq.FrameTypeResponse, []byte("OK"))
+		case "RDY":
+			if !sent && fields[1] != "0" {
+				var buf bytes.Buffer
+				binary.Write(&buf, binary.BigEndian, time.Now().UnixNano())
+				binary.Write(&buf, binary.BigEndian, uint16(1))
+				buf.Write(id[:])
+				buf.Write(body)
+				writeFrame(conn, nsq.FrameTypeMessage, buf.Bytes())
+				sent = true
+			}
+		case "CLS":
+			writeFrame(conn, nsq.FrameTypeResponse, []byte("CLOSE_WAIT"))
+		}
+
+		n.commands <- strings.TrimSpace(line)
+	}
+}
+
+// wait for a command starting with the given prefix.
+func (n *nsqd) wait(t *testing.T, prefix string) string {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case cmd := <-n.commands:
+			if strings.HasPrefix(cmd, prefix) {
+				return cmd
+			}
+		case <-timeout:
+			require.FailNow(t, "command not received", prefix)
+		}
+	}
+}
+
+func writeFrame(w io.Writer, frameType int32, data []byte) {
+	binary.Write(w, binary.BigEndian, int32(len(data)+4))
+	binary.Write(w, binary.BigEndian, frameType)
+	w.Write(data)
+}
+
+func TestServerDrain(t *testing.T) {
+	var id nsq.MessageID
+	copy(id[:], "0123456789abcdef")
+
+	t.Run("InFlight", func(t *testing.T) {
+		n := newNSQD(t, id, []byte("value"))
+		defer n.Close()
+
+		received := make(chan struct{})
+		release := make(chan struct{})
+		var r mock.Registry
+		r.TopicFn = func(name string) (lobby.Topic, error) {
+			return &mock.Topic{
+				SendFn: func(ctx context.Context, m *lobby.Message) error {
+					close(received)
+					<-release
+					return nil
+				},
+			}, nil
+		}
+
+		s := NewServer(&r, &Config{
+			NSQAddr:     n.Addr().String(),
+			MaxInFlight: 1,
+			Consumers:   []ConsumerConfig{{Topic: "a", Channel: "b", Target: "target"}},
+		})
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			err := s.Serve(nil)
+			require.NoError(t, err)
+		}()
+
+		<-received
+
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			err := s.Stop()
+			require.NoError(t, err)
+		}()
+
+		n.wait(t, "CLS")
+		select {
+		case <-stopped:
+			require.FailNow(t, "server stopped before the message was handled")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+		require.Equal(t, "FIN "+string(id[:]), n.wait(t, "FIN"))
+		<-stopped
+		<-done
+	})
+
+	t.Run("Requeue", func(t *testing.T) {
+		n := newNSQD(t, id, []byte("value"))
+		defer n.Close()
+
+		var r mock.Registry
+		r.TopicFn = func(name string) (lobby.Topic, error) {
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		s := NewServer(&r, &Config{
+			NSQAddr:     n.Addr().String(),
+			MaxInFlight: 1,
+			Consumers:   []ConsumerConfig{{Topic: "a", Channel: "b", Target: "target"}},
+		})
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			err := s.Serve(nil)
+			require.NoError(t, err)
+		}()
+
+		require.True(t, strings.HasPrefix(n.wait(t, "REQ"), "REQ "+string(id[:])))
+
+		err := s.Stop()
+		require.NoError(t, err)
+		<-done
+	})
+}
@@ -0,0 +1,39 @@
+package telemetry
+
+import (
+	"github.com/asdine/lobby/metrics"
+)
+
+var (
+	topicLookups = metrics.NewCounter(
+		"lobby_registry_topic_lookups_total",
+		"Number of topic lookups in the registry, by result.",
+		"result",
+	)
+	topicLookupDuration = metrics.NewHistogram(
+		"lobby_registry_topic_lookup_duration_seconds",
+		"Duration of the topic lookups in the registry.",
+		nil,
+	)
+	topicMessages = metrics.NewCounter(
+		"lobby_topic_messages_total",
+		"Number of messages sent to a topic, by topic.",
+		"topic",
+	)
+	topicMessageBytes = metrics.NewCounter(
+		"lobby_topic_message_bytes_total",
+		"Size of the values of the messages sent to a topic, by topic.",
+		"topic",
+	)
+	topicSendErrors = metrics.NewCounter(
+		"lobby_topic_send_errors_total",
+		"Number of messages that couldn't be sent to a topic, by topic.",
+		"topic",
+	)
+	topicSendDuration = metrics.NewHistogram(
+		"lobby_topic_send_duration_seconds",
+		"Duration of the calls sending messages to a topic, by topic.",
+		nil,
+		"topic",
+	)
+)
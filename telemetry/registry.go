@@ -0,0 +1,135 @@
+package telemetry
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/trace"
+)
+
+var _ lobby.Registry = new(Registry)
+var _ lobby.Migrator = new(Registry)
+var _ lobby.BatchSender = new(topic)
+
+// NewRegistry returns a registry recording metrics and traces of the topic lookups
+// and of the messages sent to the topics of r.
+func NewRegistry(r lobby.Registry) *Registry {
+	return &Registry{
+		Registry: r,
+	}
+}
+
+// Registry is a registry instrumenting its topics.
+type Registry struct {
+	lobby.Registry
+}
+
+// Topic returns the selected topic from the underlying registry.
+func (r *Registry) Topic(name string) (lobby.Topic, error) {
+	start := time.Now()
+	t, err := r.Registry.Topic(name)
+	topicLookupDuration.Observe(time.Since(start).Seconds())
+
+	switch err {
+	case nil:
+		topicLookups.Inc("found")
+	case lobby.ErrTopicNotFound:
+		topicLookups.Inc("not_found")
+	default:
+		topicLookups.Inc("error")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	it := topic{Topic: t, name: name}
+	if tr, ok := t.(lobby.TopicReader); ok {
+		return &topicReader{topic: &it, reader: tr}, nil
+	}
+
+	return &it, nil
+}
+
+// Migrate a topic to another backend along with its messages,
+// if the underlying registry is a lobby.Migrator.
+func (r *Registry) Migrate(ctx context.Context, topicName, backendName string, options map[string]string) error {
+	m, ok := r.Registry.(lobby.Migrator)
+	if !ok {
+		return lobby.ErrNotSupported
+	}
+
+	return m.Migrate(ctx, topicName, backendName, options)
+}
+
+type topic struct {
+	lobby.Topic
+
+	name string
+}
+
+// Send a message to the underlying topic.
+func (t *topic) Send(ctx context.Context, m *lobby.Message) error {
+	ctx, span := t.startSpan(ctx, "topic.Send")
+	defer span.Finish()
+
+	start := time.Now()
+	err := t.Topic.Send(ctx, m)
+	t.recordSend(start, []*lobby.Message{m}, []error{err})
+	span.SetError(err)
+	return err
+}
+
+// SendBatch sends the messages to the underlying topic.
+func (t *topic) SendBatch(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+	ctx, span := t.startSpan(ctx, "topic.SendBatch")
+	defer span.Finish()
+	span.SetAttribute("messaging.batch.message_count", strconv.Itoa(len(messages)))
+
+	start := time.Now()
+	errs, err := lobby.SendBatch(ctx, t.Topic, messages)
+	span.SetError(err)
+	if err != nil {
+		topicSendDuration.Observe(time.Since(start).Seconds(), t.name)
+		topicSendErrors.Add(float64(len(messages)), t.name)
+		return nil, err
+	}
+
+	t.recordSend(start, messages, errs)
+	return errs, nil
+}
+
+// startSpan starts a span measuring an operation on the topic.
+func (t *topic) startSpan(ctx context.Context, name string) (context.Context, *trace.Span) {
+	ctx, span := trace.Start(ctx, name, trace.Internal)
+	span.SetAttribute("messaging.destination", t.name)
+	return ctx, span
+}
+
+// recordSend records the messages sent since start. errs contains the error of each message, if any.
+func (t *topic) recordSend(start time.Time, messages []*lobby.Message, errs []error) {
+	topicSendDuration.Observe(time.Since(start).Seconds(), t.name)
+
+	for i, m := range messages {
+		if errs[i] != nil {
+			topicSendErrors.Inc(t.name)
+			continue
+		}
+
+		topicMessages.Inc(t.name)
+		topicMessageBytes.Add(float64(len(m.Value)), t.name)
+	}
+}
+
+type topicReader struct {
+	*topic
+
+	reader lobby.TopicReader
+}
+
+// Read messages stored in the underlying topic.
+func (t *topicReader) Read(ctx context.Context, group, cursor string, limit int) ([]lobby.Message, string, error) {
+	return t.reader.Read(ctx, group, cursor, limit)
+}
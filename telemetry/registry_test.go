@@ -0,0 +1,94 @@
+package telemetry_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/metrics"
+	"github.com/asdine/lobby/mock"
+	"github.com/asdine/lobby/telemetry"
+	"github.com/asdine/lobby/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryMetrics(t *testing.T) {
+	var m mock.Registry
+	m.TopicFn = func(name string) (lobby.Topic, error) {
+		if name != "metrics" {
+			return nil, lobby.ErrTopicNotFound
+		}
+
+		return lobby.TopicFunc(func(ctx context.Context, message *lobby.Message) error {
+			if string(message.Value) == "fail" {
+				return errors.New("failed")
+			}
+
+			return nil
+		}), nil
+	}
+	r := telemetry.NewRegistry(&m)
+
+	_, err := r.Topic("unknown")
+	require.Equal(t, lobby.ErrTopicNotFound, err)
+
+	topic, err := r.Topic("metrics")
+	require.NoError(t, err)
+
+	err = topic.Send(context.Background(), &lobby.Message{Value: []byte("hello")})
+	require.NoError(t, err)
+
+	err = topic.Send(context.Background(), &lobby.Message{Value: []byte("fail")})
+	require.Error(t, err)
+
+	errs, err := topic.(lobby.BatchSender).SendBatch(context.Background(), []*lobby.Message{
+		{Value: []byte("a")},
+		{Value: []byte("fail")},
+	})
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+
+	var buf bytes.Buffer
+	_, err = metrics.DefaultRegistry.WriteTo(&buf)
+	require.NoError(t, err)
+
+	require.Contains(t, buf.String(), `lobby_registry_topic_lookups_total{result="not_found"}`)
+	require.Contains(t, buf.String(), `lobby_topic_messages_total{topic="metrics"} 2`)
+	require.Contains(t, buf.String(), `lobby_topic_message_bytes_total{topic="metrics"} 6`)
+	require.Contains(t, buf.String(), `lobby_topic_send_errors_total{topic="metrics"} 2`)
+	require.Contains(t, buf.String(), `lobby_topic_send_duration_seconds_count{topic="metrics"} 3`)
+}
+
+func TestRegistryTracing(t *testing.T) {
+	var spans []*trace.Span
+	var e mock.Exporter
+	e.ExportFn = func(s *trace.Span) {
+		spans = append(spans, s)
+	}
+
+	trace.SetExporter(&e)
+	defer trace.SetExporter(nil)
+
+	var m mock.Registry
+	m.TopicFn = func(name string) (lobby.Topic, error) {
+		return lobby.TopicFunc(func(ctx context.Context, message *lobby.Message) error {
+			return errors.New("failed")
+		}), nil
+	}
+	r := telemetry.NewRegistry(&m)
+
+	topic, err := r.Topic("traced")
+	require.NoError(t, err)
+
+	ctx, root := trace.Start(context.Background(), "root", trace.Server)
+	err = topic.Send(ctx, &lobby.Message{Value: []byte("hello")})
+	require.Error(t, err)
+
+	require.Len(t, spans, 1)
+	require.Equal(t, "topic.Send", spans[0].Name)
+	require.Equal(t, root.Context.SpanID, spans[0].Parent)
+	require.Equal(t, "traced", spans[0].Attributes["messaging.destination"])
+	require.Equal(t, "failed", spans[0].Err)
+}
@@ -1,57 +1,218 @@
 package lobby
 
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	mrand "math/rand"
+	"sync"
+	"time"
+)
+
 // Errors.
 const (
 	ErrBackendNotFound    = Error("backend not found")
 	ErrTopicNotFound      = Error("topic not found")
 	ErrTopicAlreadyExists = Error("topic already exists")
+	ErrNotSupported       = Error("operation not supported")
+	ErrInvalidCursor      = Error("invalid cursor")
+	ErrSlowConsumer       = Error("slow consumer")
+	ErrUnauthenticated    = Error("unauthenticated")
+	ErrPermissionDenied   = Error("permission denied")
+	ErrRateLimited        = Error("rate limit exceeded")
+	ErrMessageTooLarge    = Error("message too large")
 )
 
 // A Message is a key value pair saved in a topic.
 type Message struct {
-	Group string
-	Value []byte
+	// Unique ID assigned when the message enters Lobby.
+	ID string
+	// Time at which the message entered Lobby.
+	CreatedAt time.Time
+	Group     string
+	Value     []byte
+	// Arbitrary metadata associated with the message.
+	Headers map[string]string
+}
+
+// NewMessage returns a message with a unique ID, created now.
+// Entrypoints must use it to create the messages they receive.
+func NewMessage(group string, value []byte, headers map[string]string) *Message {
+	return &Message{
+		ID:        newID(),
+		CreatedAt: time.Now().UTC(),
+		Group:     group,
+		Value:     value,
+		Headers:   headers,
+	}
+}
+
+func newID() string {
+	var id [16]byte
+
+	RandomID(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// fallback generates ids if the system can't provide randomness.
+var fallback = struct {
+	sync.Mutex
+	*mrand.Rand
+}{Rand: mrand.New(mrand.NewSource(time.Now().UnixNano()))}
+
+// RandomID fills id with random bytes, never all zero. It uses crypto/rand, or math/rand
+// if the system can't provide randomness, so that generating an id never fails.
+func RandomID(id []byte) {
+	for {
+		if _, err := rand.Read(id); err != nil {
+			fallback.Lock()
+			for i := range id {
+				id[i] = byte(fallback.Intn(256))
+			}
+			fallback.Unlock()
+		}
+
+		for _, b := range id {
+			if b != 0 {
+				return
+			}
+		}
+	}
 }
 
 // A Topic manages a collection of items.
 type Topic interface {
-	// Send a message in the topic.
-	Send(*Message) error
+	// Send a message in the topic. The context is used to abort the operation
+	// if it takes too long or if the client is gone.
+	Send(context.Context, *Message) error
 	// Close the topic. Can be used to close sessions if required.
 	Close() error
 }
 
+// A TopicReader is a Topic able to return the messages it stored.
+// Backends can return topics implementing this interface to allow reading data back.
+type TopicReader interface {
+	Topic
+
+	// Read at most limit messages stored after the given cursor, in the order they were sent.
+	// If group is not empty, only the messages of that group are returned.
+	// An empty cursor reads from the first message. The returned cursor must be used to read
+	// the following messages, it is empty if there are no more messages to read.
+	// If limit is lower or equal to zero, all the remaining messages are returned.
+	Read(ctx context.Context, group, cursor string, limit int) ([]Message, string, error)
+}
+
+// A BatchSender is a Topic able to send several messages at once.
+// Backends can return topics implementing this interface to send batches more efficiently.
+type BatchSender interface {
+	Topic
+
+	// SendBatch sends the messages in the topic. It returns one error per message, in the same order,
+	// which is nil if the message was sent. The returned error is set when the batch couldn't be sent at all.
+	SendBatch(ctx context.Context, messages []*Message) ([]error, error)
+}
+
+// SendBatch sends the messages using t.SendBatch if t is a BatchSender,
+// or by sending each message one after the other otherwise.
+func SendBatch(ctx context.Context, t Topic, messages []*Message) ([]error, error) {
+	if bs, ok := t.(BatchSender); ok {
+		return bs.SendBatch(ctx, messages)
+	}
+
+	errs := make([]error, len(messages))
+	for i := range messages {
+		errs[i] = t.Send(ctx, messages[i])
+	}
+
+	return errs, nil
+}
+
 // TopicFunc creates a topic from a send function.
-func TopicFunc(fn func(*Message) error) Topic {
+func TopicFunc(fn func(context.Context, *Message) error) Topic {
 	return &topicFunc{fn}
 }
 
 type topicFunc struct {
-	fn func(*Message) error
+	fn func(context.Context, *Message) error
 }
 
-func (t *topicFunc) Send(m *Message) error {
-	return t.fn(m)
+func (t *topicFunc) Send(ctx context.Context, m *Message) error {
+	return t.fn(ctx, m)
 }
 
 func (t *topicFunc) Close() error {
 	return nil
 }
 
+// A Subscription receives the messages sent to a topic.
+type Subscription interface {
+	// Messages returns the channel on which messages are delivered.
+	// The channel is closed when the subscription ends.
+	Messages() <-chan *Message
+	// Err returns the reason why the subscription ended, if any.
+	// It must only be called once the Messages channel is closed.
+	Err() error
+	// Close the subscription.
+	Close() error
+}
+
+// A Subscriber delivers the messages sent to topics in real time.
+type Subscriber interface {
+	// Subscribe to the messages sent to the given topic.
+	// If group is not empty, only the messages of that group are delivered.
+	Subscribe(topic, group string) (Subscription, error)
+}
+
 // A Backend is able to create topics that can be used to store data.
 type Backend interface {
-	// Get a topic by name.
-	Topic(name string) (Topic, error)
+	// Get a topic by name. The options are the ones given when the topic was created,
+	// backends can use them to configure the topic.
+	Topic(name string, options map[string]string) (Topic, error)
 	// Close the backend connection.
 	Close() error
 }
 
+// A HealthChecker reports whether a component, like a backend, is able to serve requests.
+type HealthChecker interface {
+	// Health returns an error if the component is unhealthy, e.g. if a backend can't reach its datastore.
+	Health(ctx context.Context) error
+}
+
+// TopicInfo describes a topic stored in a Registry.
+type TopicInfo struct {
+	Name      string
+	Backend   string
+	CreatedAt time.Time
+	// Options given to the backend of the topic.
+	Options map[string]string
+}
+
 // A Registry manages the topics, their configuration and their associated Backend.
 type Registry interface {
-	Backend
-
+	// Get a topic by name. The topic is created by its backend using the options stored in the Registry.
+	Topic(name string) (Topic, error)
 	// Register a backend under the given name.
 	RegisterBackend(name string, backend Backend)
-	// Create a topic and register it to the Registry.
-	Create(backendName, topicName string) error
+	// Create a topic and register it to the Registry. The options are given to the backend
+	// every time the topic is used.
+	Create(backendName, topicName string, options map[string]string) error
+	// Delete a topic from the Registry.
+	Delete(topicName string) error
+	// Rebind a topic to another backend and replace its options. The messages stored
+	// by the previous backend are left untouched.
+	Rebind(topicName, backendName string, options map[string]string) error
+	// Info returns informations about the selected topic.
+	Info(topicName string) (*TopicInfo, error)
+	// List topics ordered by name. Skips the first offset topics and returns at most limit topics.
+	// If limit is lower or equal to zero, all the remaining topics are returned.
+	List(offset, limit int) ([]TopicInfo, error)
+	// Close the registry and its backends.
+	Close() error
+}
+
+// A Migrator is a Registry able to move topics to another backend along with their messages.
+type Migrator interface {
+	// Migrate rebinds a topic to another backend, after copying the messages stored by its current
+	// backend to the new one. Messages sent during the migration are sent to both backends.
+	Migrate(ctx context.Context, topicName, backendName string, options map[string]string) error
 }
@@ -0,0 +1,72 @@
+package lobby_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/asdine/lobby"
+	"github.com/asdine/lobby/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMessage(t *testing.T) {
+	m1 := lobby.NewMessage("group", []byte("value"), map[string]string{"a": "b"})
+	require.NotEmpty(t, m1.ID)
+	require.False(t, m1.CreatedAt.IsZero())
+	require.Equal(t, "group", m1.Group)
+	require.Equal(t, "value", string(m1.Value))
+	require.Equal(t, "b", m1.Headers["a"])
+
+	m2 := lobby.NewMessage("group", []byte("value"), nil)
+	require.NotEqual(t, m1.ID, m2.ID)
+}
+
+func TestRandomID(t *testing.T) {
+	var id1, id2 [8]byte
+
+	lobby.RandomID(id1[:])
+	lobby.RandomID(id2[:])
+	require.NotEqual(t, [8]byte{}, id1)
+	require.NotEqual(t, id1, id2)
+}
+
+func TestSendBatch(t *testing.T) {
+	t.Run("Fallback", func(t *testing.T) {
+		var sent []string
+		tp := lobby.TopicFunc(func(ctx context.Context, m *lobby.Message) error {
+			if string(m.Value) == "bad" {
+				return errors.New("bad value")
+			}
+
+			sent = append(sent, string(m.Value))
+			return nil
+		})
+
+		errs, err := lobby.SendBatch(context.Background(), tp, []*lobby.Message{
+			{Value: []byte("a")},
+			{Value: []byte("bad")},
+			{Value: []byte("b")},
+		})
+		require.NoError(t, err)
+		require.Len(t, errs, 3)
+		require.NoError(t, errs[0])
+		require.Error(t, errs[1])
+		require.NoError(t, errs[2])
+		require.Equal(t, []string{"a", "b"}, sent)
+	})
+
+	t.Run("BatchSender", func(t *testing.T) {
+		var tp mock.Topic
+		tp.SendBatchFn = func(ctx context.Context, messages []*lobby.Message) ([]error, error) {
+			require.Len(t, messages, 2)
+			return make([]error, len(messages)), nil
+		}
+
+		errs, err := lobby.SendBatch(context.Background(), &tp, []*lobby.Message{{Value: []byte("a")}, {Value: []byte("b")}})
+		require.NoError(t, err)
+		require.Len(t, errs, 2)
+		require.Equal(t, 1, tp.SendBatchInvoked)
+		require.Zero(t, tp.SendInvoked)
+	})
+}
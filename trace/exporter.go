@@ -0,0 +1,326 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/asdine/lobby/log"
+	"github.com/pkg/errors"
+)
+
+// Default settings of the OTLP exporter.
+const (
+	DefaultBatchSize     = 512
+	DefaultFlushInterval = 5 * time.Second
+	// DefaultQueueSize is the number of spans waiting to be sent above which spans are dropped.
+	DefaultQueueSize = 4096
+)
+
+// NewFileExporter returns an exporter appending the spans to the file at path, one JSON object per line.
+// Lobby and its plugins can append to the same file.
+func NewFileExporter(path, service string) (*FileExporter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open trace file %s", path)
+	}
+
+	return &FileExporter{
+		f:       f,
+		service: service,
+	}, nil
+}
+
+// FileExporter writes spans to a file.
+type FileExporter struct {
+	mu      sync.Mutex
+	f       *os.File
+	service string
+}
+
+type fileSpan struct {
+	TraceID    string            `json:"trace_id"`
+	SpanID     string            `json:"span_id"`
+	ParentID   string            `json:"parent_id,omitempty"`
+	Service    string            `json:"service"`
+	Name       string            `json:"name"`
+	Kind       string            `json:"kind"`
+	Start      time.Time         `json:"start"`
+	End        time.Time         `json:"end"`
+	Duration   float64           `json:"duration"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Err        string            `json:"err,omitempty"`
+}
+
+// Export writes the span to the file. Each span is written using a single call
+// so that lines written by different processes don't interleave.
+func (f *FileExporter) Export(s *Span) {
+	fs := fileSpan{
+		TraceID:    s.Context.TraceID.String(),
+		SpanID:     s.Context.SpanID.String(),
+		Service:    f.service,
+		Name:       s.Name,
+		Kind:       s.Kind.String(),
+		Start:      s.Start,
+		End:        s.End,
+		Duration:   s.Duration().Seconds(),
+		Attributes: s.Attributes,
+		Err:        s.Err,
+	}
+
+	if s.Parent != (SpanID{}) {
+		fs.ParentID = s.Parent.String()
+	}
+
+	data, err := json.Marshal(&fs)
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, _ = f.f.Write(append(data, '\n'))
+}
+
+// Close the file.
+func (f *FileExporter) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.f.Close()
+}
+
+// NewOTLPExporter returns an exporter sending the spans to an OpenTelemetry collector,
+// using OTLP over HTTP with JSON encoding. The endpoint is the full URL of the traces
+// route, like http://localhost:4318/v1/traces.
+// Spans are sent by batches in the background.
+func NewOTLPExporter(endpoint, service string, logger *log.Logger) *OTLPExporter {
+	e := OTLPExporter{
+		endpoint:      endpoint,
+		service:       service,
+		logger:        logger,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		batchSize:     DefaultBatchSize,
+		flushInterval: DefaultFlushInterval,
+		spans:         make(chan *Span, DefaultQueueSize),
+		quit:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	go e.run()
+	return &e
+}
+
+// OTLPExporter sends spans to an OpenTelemetry collector.
+type OTLPExporter struct {
+	endpoint      string
+	service       string
+	logger        *log.Logger
+	client        *http.Client
+	batchSize     int
+	flushInterval time.Duration
+	spans         chan *Span
+	quit          chan struct{}
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+// Export queues the span. Spans are dropped if the queue is full.
+func (e *OTLPExporter) Export(s *Span) {
+	select {
+	case e.spans <- s:
+	default:
+		e.logger.Debug("Trace queue full, dropping span")
+	}
+}
+
+// Close sends the queued spans and stops the exporter.
+func (e *OTLPExporter) Close() error {
+	e.closeOnce.Do(func() {
+		close(e.quit)
+	})
+	<-e.done
+	return nil
+}
+
+func (e *OTLPExporter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, e.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := e.send(batch); err != nil {
+			e.logger.Printf("Failed to export %d spans: %s\n", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case s := <-e.spans:
+			batch = append(batch, s)
+			if len(batch) >= e.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.quit:
+			for {
+				select {
+				case s := <-e.spans:
+					batch = append(batch, s)
+					if len(batch) >= e.batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *OTLPExporter) send(spans []*Span) error {
+	data, err := json.Marshal(newOTLPRequest(e.service, spans))
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// The following types follow the JSON encoding of the OTLP trace protocol.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// status codes of OTLP spans.
+const (
+	otlpStatusOK    = 1
+	otlpStatusError = 2
+)
+
+func newOTLPRequest(service string, spans []*Span) *otlpRequest {
+	list := make([]otlpSpan, len(spans))
+	for i, s := range spans {
+		list[i] = otlpSpan{
+			TraceID:           s.Context.TraceID.String(),
+			SpanID:            s.Context.SpanID.String(),
+			Name:              s.Name,
+			Kind:              int(s.Kind),
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.Attributes),
+			Status:            otlpStatus{Code: otlpStatusOK},
+		}
+
+		if s.Parent != (SpanID{}) {
+			list[i].ParentSpanID = s.Parent.String()
+		}
+
+		if s.Err != "" {
+			list[i].Status = otlpStatus{Code: otlpStatusError, Message: s.Err}
+		}
+	}
+
+	return &otlpRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: otlpAttributes(map[string]string{"service.name": service}),
+				},
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{Name: "github.com/asdine/lobby"},
+						Spans: list,
+					},
+				},
+			},
+		},
+	}
+}
+
+func otlpAttributes(attrs map[string]string) []otlpAttribute {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	list := make([]otlpAttribute, len(keys))
+	for i, k := range keys {
+		list[i] = otlpAttribute{Key: k, Value: otlpValue{StringValue: attrs[k]}}
+	}
+
+	return list
+}
@@ -0,0 +1,141 @@
+package trace_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/asdine/lobby/log"
+	"github.com/asdine/lobby/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileExporter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lobby")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	e, err := trace.NewFileExporter(path.Join(dir, "spans.json"), "lobby")
+	require.NoError(t, err)
+
+	trace.SetExporter(e)
+	defer trace.SetExporter(nil)
+
+	ctx, parent := trace.Start(context.Background(), "parent", trace.Server)
+	_, child := trace.Start(ctx, "child", trace.Client)
+	child.SetAttribute("topic", "quotes")
+	child.Finish()
+	parent.Finish()
+
+	require.NoError(t, e.Close())
+
+	f, err := os.Open(path.Join(dir, "spans.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var lines []map[string]interface{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(s.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.NoError(t, s.Err())
+
+	require.Len(t, lines, 2)
+	require.Equal(t, "child", lines[0]["name"])
+	require.Equal(t, "client", lines[0]["kind"])
+	require.Equal(t, "lobby", lines[0]["service"])
+	require.Equal(t, parent.Context.TraceID.String(), lines[0]["trace_id"])
+	require.Equal(t, parent.Context.SpanID.String(), lines[0]["parent_id"])
+	require.Equal(t, map[string]interface{}{"topic": "quotes"}, lines[0]["attributes"])
+	require.Equal(t, "parent", lines[1]["name"])
+	require.NotContains(t, lines[1], "parent_id")
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var mu sync.Mutex
+	var requests []map[string]interface{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/traces", r.URL.Path)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var req map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	e := trace.NewOTLPExporter(srv.URL+"/v1/traces", "lobby", log.New(log.Output(ioutil.Discard)))
+
+	trace.SetExporter(e)
+	defer trace.SetExporter(nil)
+
+	_, span := trace.Start(context.Background(), "span", trace.Server)
+	span.SetAttribute("topic", "quotes")
+	span.SetError(os.ErrNotExist)
+	span.Finish()
+
+	// spans are flushed when the exporter is closed.
+	require.NoError(t, e.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, requests, 1)
+
+	data, err := json.Marshal(requests[0])
+	require.NoError(t, err)
+
+	var req struct {
+		ResourceSpans []struct {
+			Resource struct {
+				Attributes []struct {
+					Key   string
+					Value struct {
+						StringValue string
+					}
+				}
+			}
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceID           string
+					SpanID            string
+					Name              string
+					Kind              int
+					StartTimeUnixNano string
+					Status            struct {
+						Code    int
+						Message string
+					}
+				}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(data, &req))
+
+	require.Len(t, req.ResourceSpans, 1)
+	rs := req.ResourceSpans[0]
+	require.Equal(t, "service.name", rs.Resource.Attributes[0].Key)
+	require.Equal(t, "lobby", rs.Resource.Attributes[0].Value.StringValue)
+	require.Len(t, rs.ScopeSpans[0].Spans, 1)
+
+	s := rs.ScopeSpans[0].Spans[0]
+	require.Equal(t, span.Context.TraceID.String(), s.TraceID)
+	require.Equal(t, span.Context.SpanID.String(), s.SpanID)
+	require.Equal(t, "span", s.Name)
+	require.Equal(t, 2, s.Kind)
+	require.NotEmpty(t, s.StartTimeUnixNano)
+	require.Equal(t, 2, s.Status.Code)
+	require.Equal(t, os.ErrNotExist.Error(), s.Status.Message)
+}
@@ -0,0 +1,225 @@
+package trace
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asdine/lobby"
+	"github.com/pkg/errors"
+)
+
+// Kind of a span. The values match the ones of OpenTelemetry.
+type Kind int
+
+// List of span kinds.
+const (
+	Internal Kind = iota + 1
+	Server
+	Client
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Server:
+		return "server"
+	case Client:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SpanContext is the part of a span propagated across processes.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid returns true if both the trace and span ids are set.
+func (s SpanContext) IsValid() bool {
+	return s.TraceID != TraceID{} && s.SpanID != SpanID{}
+}
+
+// Traceparent returns the W3C traceparent header of the span context.
+func (s SpanContext) Traceparent() string {
+	flags := "00"
+	if s.Sampled {
+		flags = "01"
+	}
+
+	return fmt.Sprintf("00-%s-%s-%s", s.TraceID, s.SpanID, flags)
+}
+
+// ParseTraceparent parses a W3C traceparent header.
+func ParseTraceparent(header string) (SpanContext, error) {
+	var sc SpanContext
+
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return sc, errors.Errorf("invalid traceparent %q", header)
+	}
+
+	for _, f := range []struct {
+		dst []byte
+		src string
+	}{
+		{sc.TraceID[:], parts[1]},
+		{sc.SpanID[:], parts[2]},
+	} {
+		if len(f.src) != 2*len(f.dst) || strings.ToLower(f.src) != f.src {
+			return sc, errors.Errorf("invalid traceparent %q", header)
+		}
+
+		if _, err := hex.Decode(f.dst, []byte(f.src)); err != nil {
+			return sc, errors.Errorf("invalid traceparent %q", header)
+		}
+	}
+
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 || !sc.IsValid() {
+		return sc, errors.Errorf("invalid traceparent %q", header)
+	}
+
+	sc.Sampled = flags[0]&1 == 1
+	return sc, nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a context holding the span context. Spans started from this context
+// are children of the span it identifies.
+func NewContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, sc)
+}
+
+// FromContext returns the span context stored in ctx, if any.
+func FromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(contextKey{}).(SpanContext)
+	return sc, ok
+}
+
+// ContextWithTraceparent returns a context holding the span context of the traceparent header.
+// Invalid or empty headers are ignored.
+func ContextWithTraceparent(ctx context.Context, header string) context.Context {
+	if header == "" {
+		return ctx
+	}
+
+	sc, err := ParseTraceparent(header)
+	if err != nil {
+		return ctx
+	}
+
+	return NewContext(ctx, sc)
+}
+
+// Exporter sends finished spans to their destination.
+type Exporter interface {
+	// Export must not block the caller.
+	Export(*Span)
+	Close() error
+}
+
+var exporter struct {
+	sync.RWMutex
+	e Exporter
+}
+
+// SetExporter sets the exporter of the finished spans. Spans are dropped if e is nil.
+func SetExporter(e Exporter) {
+	exporter.Lock()
+	exporter.e = e
+	exporter.Unlock()
+}
+
+func currentExporter() Exporter {
+	exporter.RLock()
+	defer exporter.RUnlock()
+	return exporter.e
+}
+
+// Span measures an operation.
+type Span struct {
+	Name    string
+	Kind    Kind
+	Context SpanContext
+	// Parent is zero for the root span of a trace.
+	Parent     SpanID
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]string
+	// Err is the error the operation ended with, if any.
+	Err string
+
+	once sync.Once
+}
+
+// Start a span. It is a child of the span stored in ctx, if any, otherwise it starts a new trace.
+// The returned context holds the new span and must be used to start its children.
+func Start(ctx context.Context, name string, kind Kind) (context.Context, *Span) {
+	s := Span{
+		Name:       name,
+		Kind:       kind,
+		Start:      time.Now(),
+		Attributes: make(map[string]string),
+	}
+
+	if parent, ok := FromContext(ctx); ok && parent.IsValid() {
+		s.Context.TraceID = parent.TraceID
+		s.Context.Sampled = parent.Sampled
+		s.Parent = parent.SpanID
+	} else {
+		lobby.RandomID(s.Context.TraceID[:])
+		s.Context.Sampled = true
+	}
+	lobby.RandomID(s.Context.SpanID[:])
+
+	return NewContext(ctx, s.Context), &s
+}
+
+// SetAttribute associates a value to the span.
+func (s *Span) SetAttribute(key, value string) {
+	s.Attributes[key] = value
+}
+
+// SetError marks the span as failed, if err is not nil.
+func (s *Span) SetError(err error) {
+	if err != nil {
+		s.Err = err.Error()
+	}
+}
+
+// Finish ends the span and exports it if it is sampled. Calls after the first one do nothing.
+func (s *Span) Finish() {
+	s.once.Do(func() {
+		s.End = time.Now()
+
+		if e := currentExporter(); e != nil && s.Context.Sampled {
+			e.Export(s)
+		}
+	})
+}
+
+// Duration of the span.
+func (s *Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
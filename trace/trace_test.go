@@ -0,0 +1,120 @@
+package trace_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/asdine/lobby/mock"
+	"github.com/asdine/lobby/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	header := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	sc, err := trace.ParseTraceparent(header)
+	require.NoError(t, err)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	require.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	require.True(t, sc.Sampled)
+	require.Equal(t, header, sc.Traceparent())
+
+	sc, err = trace.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.NoError(t, err)
+	require.False(t, sc.Sampled)
+
+	// future versions can add fields.
+	_, err = trace.ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	require.NoError(t, err)
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902zz-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+	}
+
+	for _, h := range invalid {
+		_, err = trace.ParseTraceparent(h)
+		require.Error(t, err, h)
+	}
+}
+
+func TestStart(t *testing.T) {
+	var spans []*trace.Span
+	var e mock.Exporter
+	e.ExportFn = func(s *trace.Span) {
+		spans = append(spans, s)
+	}
+
+	trace.SetExporter(&e)
+	defer trace.SetExporter(nil)
+
+	t.Run("Root", func(t *testing.T) {
+		spans = nil
+
+		ctx, span := trace.Start(context.Background(), "root", trace.Server)
+		sc, ok := trace.FromContext(ctx)
+		require.True(t, ok)
+		require.True(t, sc.IsValid())
+		require.True(t, sc.Sampled)
+		require.Equal(t, span.Context, sc)
+		require.Equal(t, trace.SpanID{}, span.Parent)
+
+		_, child := trace.Start(ctx, "child", trace.Client)
+		child.SetAttribute("key", "value")
+		child.SetError(errors.New("failed"))
+		child.Finish()
+		span.Finish()
+		span.Finish()
+
+		require.Len(t, spans, 2)
+		require.Equal(t, "child", spans[0].Name)
+		require.Equal(t, sc.TraceID, spans[0].Context.TraceID)
+		require.Equal(t, sc.SpanID, spans[0].Parent)
+		require.NotEqual(t, sc.SpanID, spans[0].Context.SpanID)
+		require.Equal(t, "value", spans[0].Attributes["key"])
+		require.Equal(t, "failed", spans[0].Err)
+		require.Equal(t, "root", spans[1].Name)
+		require.False(t, spans[1].End.Before(spans[1].Start))
+	})
+
+	t.Run("Remote", func(t *testing.T) {
+		spans = nil
+
+		ctx := trace.ContextWithTraceparent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		_, span := trace.Start(ctx, "span", trace.Server)
+		span.Finish()
+
+		require.Len(t, spans, 1)
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].Context.TraceID.String())
+		require.Equal(t, "00f067aa0ba902b7", spans[0].Parent.String())
+	})
+
+	t.Run("NotSampled", func(t *testing.T) {
+		spans = nil
+
+		ctx := trace.ContextWithTraceparent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+		ctx, span := trace.Start(ctx, "span", trace.Server)
+		span.Finish()
+
+		require.Empty(t, spans)
+
+		// the sampling decision is propagated.
+		sc, _ := trace.FromContext(ctx)
+		require.False(t, sc.Sampled)
+	})
+
+	t.Run("InvalidTraceparent", func(t *testing.T) {
+		ctx := trace.ContextWithTraceparent(context.Background(), "invalid")
+		_, ok := trace.FromContext(ctx)
+		require.False(t, ok)
+	})
+}